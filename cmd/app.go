@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/duynhne/notification-service/config"
+	database "github.com/duynhne/notification-service/internal/core"
+	logicv1 "github.com/duynhne/notification-service/internal/logic/v1"
+	"github.com/duynhne/notification-service/internal/logic/v1/health"
+	"github.com/duynhne/notification-service/internal/logic/v1/retry"
+	"github.com/duynhne/notification-service/internal/logic/v1/sms"
+	"github.com/duynhne/notification-service/internal/logic/v1/webhook"
+	webv1 "github.com/duynhne/notification-service/internal/web/v1"
+)
+
+// app holds the services shared by the commands of the service binary. Each process
+// role uses the part it needs: serve the handlers, worker and scheduler their jobs.
+type app struct {
+	cfg    *config.Config
+	logger *zap.Logger
+
+	notifications *logicv1.NotificationService
+	deliveries    *logicv1.DeliveryService
+	callbacks     *logicv1.CallbackService
+	suppressions  *logicv1.SuppressionService
+	unsubscribes  *logicv1.UnsubscribeService
+	devices       *logicv1.DeviceService
+	push          *logicv1.PushService
+	webPush       *logicv1.WebPushService
+	webhooks      *logicv1.WebhookService
+	providers     *health.Registry
+	retention     *logicv1.RetentionService
+	partitions    *logicv1.PartitionService
+}
+
+// newApp wires the repositories, channel providers and services.
+func newApp(cfg *config.Config, logger *zap.Logger) (*app, error) {
+	repo := database.NewNotificationRepository()
+	deliveryRepo := database.NewNotificationDeliveryRepository()
+	retentionService, err := newRetentionService(cfg, repo)
+	if err != nil {
+		return nil, fmt.Errorf("configure retention: %w", err)
+	}
+
+	notificationIDs := logicv1.NewNotificationIDs(repo, cfg.Inbox.LegacyIDs)
+	callbackService := logicv1.NewCallbackService(
+		repo,
+		notificationIDs,
+		database.NewAPIClientRepository(),
+		database.NewCallbackEventRepository(),
+		// Each dispatch is a single attempt; retries are scheduled through the outbox.
+		// Callback URLs belong to calling services, which may run in the cluster.
+		webhook.NewClient(cfg.Callback.Timeout, retry.Policy{MaxAttempts: 1}, false),
+		callbackPolicy(cfg),
+		cfg.Callback.AllowHTTP,
+	)
+	suppressionService := logicv1.NewSuppressionService(
+		database.NewSuppressionRepository(),
+		callbackService,
+		cfg.Email.SoftBounceSuppressFor,
+	)
+	unsubscribeService := logicv1.NewUnsubscribeService(
+		database.NewPreferenceRepository(),
+		cfg.Email.UnsubscribeSecret,
+		cfg.Email.UnsubscribeURL,
+		cfg.Email.UnsubscribeTokenTTL,
+	)
+	// Circuit breakers of all channel providers, reported by the provider status endpoint
+	providers := health.NewRegistry(health.Config{
+		Window:      cfg.Providers.Window,
+		MinRequests: cfg.Providers.MinRequests,
+		FailureRate: cfg.Providers.FailureRate,
+		OpenFor:     cfg.Providers.OpenFor,
+	})
+	limiter := initThrottle(cfg, logger)
+	if !sms.KnownRegion(cfg.SMS.DefaultRegion) {
+		logger.Warn("No dialing rules for SMS_DEFAULT_REGION, SMS recipients must use international format",
+			zap.String("region", cfg.SMS.DefaultRegion))
+	}
+	service := logicv1.NewNotificationService(
+		repo,
+		deliveryRepo,
+		notificationIDs,
+		callbackService,
+		suppressionService,
+		unsubscribeService,
+		logicv1.NewAttachmentService(
+			int64(cfg.Email.MaxAttachmentSize),
+			int64(cfg.Email.MaxAttachmentsSize),
+			cfg.Email.AttachmentFetchTimeout,
+			cfg.Email.AttachmentAllowHTTP,
+		),
+		initEmail(cfg, logger, providers, limiter),
+		initSMS(cfg, logger, providers, limiter),
+		logicv1.SMSPolicy{
+			DefaultRegion: cfg.SMS.DefaultRegion,
+			MaxSegments:   cfg.SMS.MaxSegments,
+			Truncate:      cfg.SMS.TruncateLong,
+		},
+		logicv1.InboxPolicy{RestoreWindow: cfg.Inbox.RestoreWindow, ListWindow: cfg.Inbox.ListWindow},
+	)
+
+	deviceRepo := database.NewDeviceTokenRepository()
+	webPushRepo := database.NewWebPushSubscriptionRepository()
+	webPushClient := initWebPush(cfg, logger, providers, limiter)
+	pushService := logicv1.NewPushService(
+		repo,
+		deliveryRepo,
+		callbackService,
+		deviceRepo,
+		initPush(cfg, logger, deviceRepo, providers, limiter),
+		webPushRepo,
+		webPushClient,
+	)
+
+	return &app{
+		cfg:           cfg,
+		logger:        logger,
+		notifications: service,
+		deliveries:    logicv1.NewDeliveryService(repo, deliveryRepo, callbackService, service, pushService, deliveryPolicy(cfg)),
+		callbacks:     callbackService,
+		suppressions:  suppressionService,
+		unsubscribes:  unsubscribeService,
+		devices:       logicv1.NewDeviceService(deviceRepo, cfg.Devices.StaleAfter),
+		push:          pushService,
+		webPush:       logicv1.NewWebPushService(webPushRepo, webPushClient),
+		webhooks: logicv1.NewWebhookService(
+			database.NewWebhookEndpointRepository(),
+			database.NewWebhookDeliveryRepository(),
+			webhook.NewClient(cfg.Webhook.Timeout, retry.Policy{MaxAttempts: 1}, !cfg.Webhook.AllowPrivate),
+			retryPolicy(cfg),
+			cfg.Webhook.DisableAfter,
+			cfg.Webhook.AllowHTTP,
+			cfg.Webhook.AllowPrivate,
+		),
+		providers: providers,
+		retention: retentionService,
+		partitions: logicv1.NewPartitionService(
+			database.NewNotificationPartitionRepository(),
+			cfg.Retention.PartitionMonthsAhead,
+			cfg.Retention.PartitionDetachAfterMonths,
+		),
+	}, nil
+}
+
+// handlers returns the v1 HTTP handlers served by the serve command.
+func (a *app) handlers() *handlers {
+	return &handlers{
+		notification: webv1.NewHandler(a.notifications),
+		device:       webv1.NewDeviceHandler(a.devices),
+		push:         webv1.NewPushHandler(a.push),
+		webPush:      webv1.NewWebPushHandler(a.webPush),
+		webhook:      webv1.NewWebhookHandler(a.webhooks),
+		callback:     webv1.NewCallbackHandler(a.callbacks),
+		suppression:  webv1.NewSuppressionHandler(a.suppressions),
+		unsubscribe:  webv1.NewUnsubscribeHandler(a.unsubscribes),
+		provider:     webv1.NewProviderHandler(logicv1.NewProviderService(a.providers)),
+	}
+}
+
+// startWorker starts the delivery jobs: queued email, SMS and push sends, status
+// callbacks and webhook events are dispatched as they fall due.
+func (a *app) startWorker(jobs *backgroundJobs) {
+	cfg := a.cfg
+	jobs.every("notification_dispatch", cfg.Delivery.PollInterval, func(ctx context.Context) error {
+		for {
+			claimed, err := a.deliveries.DispatchDue(ctx, cfg.Delivery.BatchSize)
+			if err != nil || claimed < cfg.Delivery.BatchSize {
+				return err
+			}
+		}
+	})
+	jobs.every("callback_dispatch", cfg.Callback.PollInterval, func(ctx context.Context) error {
+		// Drain the backlog before waiting for the next tick.
+		for {
+			delivered, err := a.callbacks.DispatchDue(ctx, cfg.Callback.BatchSize)
+			if err != nil || delivered < cfg.Callback.BatchSize {
+				return err
+			}
+		}
+	})
+	jobs.every("webhook_dispatch", cfg.Webhook.PollInterval, func(ctx context.Context) error {
+		for {
+			delivered, err := a.webhooks.DispatchDue(ctx, cfg.Webhook.BatchSize)
+			if err != nil || delivered < cfg.Webhook.BatchSize {
+				return err
+			}
+		}
+	})
+}
+
+// startScheduler starts the maintenance jobs: stale device tokens, notification
+// partitions and retention. Each run takes a database lock, so replicas that all run
+// the scheduler (such as serve replicas) take turns instead of racing.
+func (a *app) startScheduler(jobs *backgroundJobs) {
+	cfg, logger := a.cfg, a.logger
+	exclusive := func(name string, interval time.Duration, job func(ctx context.Context) error) {
+		jobs.every(name, interval, func(ctx context.Context) error {
+			ran, err := database.RunExclusive(ctx, name, job)
+			if !ran && err == nil {
+				logger.Debug("Background job running elsewhere, skipped", zap.String("job", name))
+			}
+			return err
+		})
+	}
+	exclusive("device_token_prune", cfg.Devices.PruneInterval, func(ctx context.Context) error {
+		pruned, err := a.devices.PruneStale(ctx)
+		if pruned > 0 {
+			logger.Info("Stale device tokens pruned", zap.Int64("count", pruned))
+		}
+		return err
+	})
+	exclusive("notification_partitions", cfg.Retention.PartitionInterval, func(ctx context.Context) error {
+		created, detached, err := a.partitions.Maintain(ctx)
+		if created > 0 {
+			logger.Info("Notification partitions created", zap.Int("count", created))
+		}
+		if len(detached) > 0 {
+			logger.Info("Notification partitions detached", zap.Strings("partitions", detached))
+		}
+		return err
+	})
+	exclusive("notification_purge", cfg.Retention.PurgeInterval, func(ctx context.Context) error {
+		results, err := a.retention.Purge(ctx, false)
+		for _, result := range results {
+			if result.Count > 0 {
+				logger.Info("Purged past retention", zap.String("rule", result.Rule), zap.Int("count", result.Count))
+			}
+		}
+		return err
+	})
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/duynhne/notification-service/config"
+)
+
+// runConfig runs the config subcommand. config check prints the effective configuration
+// with its secrets redacted, then validates it.
+func runConfig(cfg *config.Config, args []string, out io.Writer) error {
+	if len(args) != 1 || args[0] != "check" {
+		return errors.New("usage: config check")
+	}
+
+	redacted := cfg.Redacted()
+	printConfig(out, "", reflect.ValueOf(redacted))
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "configuration is valid")
+	return nil
+}
+
+// printConfig prints the fields of a configuration struct as Section.Field = value lines.
+func printConfig(out io.Writer, prefix string, v reflect.Value) {
+	for i := range v.NumField() {
+		field, value := v.Type().Field(i), v.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := prefix + field.Name
+		if value.Kind() == reflect.Struct {
+			printConfig(out, name+".", value)
+			continue
+		}
+		if value.Kind() == reflect.String {
+			fmt.Fprintf(out, "%s = %q\n", name, value.String())
+			continue
+		}
+		fmt.Fprintf(out, "%s = %v\n", name, value.Interface())
+	}
+}
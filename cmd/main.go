@@ -3,8 +3,13 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
+	"fmt"
 	"net/http"
+	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
@@ -15,13 +20,55 @@ import (
 
 	"github.com/duynhne/notification-service/config"
 	database "github.com/duynhne/notification-service/internal/core"
-	logicv1 "github.com/duynhne/notification-service/internal/logic/v1"
+	"github.com/duynhne/notification-service/internal/logic/v1/email"
+	"github.com/duynhne/notification-service/internal/logic/v1/health"
+	"github.com/duynhne/notification-service/internal/logic/v1/push"
+	"github.com/duynhne/notification-service/internal/logic/v1/retry"
+	"github.com/duynhne/notification-service/internal/logic/v1/sms"
+	"github.com/duynhne/notification-service/internal/logic/v1/throttle"
 	webv1 "github.com/duynhne/notification-service/internal/web/v1"
 	"github.com/duynhne/notification-service/middleware"
 )
 
+// usage lists the commands of the service binary. Every command reads the same
+// configuration, so one image runs any process role.
+const usage = `Usage: notification-service [command] [flags]
+
+Commands:
+  serve         Serve the HTTP API and run the worker and scheduler jobs (default);
+                with -jobs=false only the API
+  worker        Send queued notifications, status callbacks and webhook events
+  scheduler     Run maintenance: partitions, retention purge, stale device tokens
+  migrate       Apply or inspect schema migrations: up [-out-of-order], down [-steps n], status
+  purge         Run the retention purge once [-dry-run]
+  send          Send a test notification: email, sms or push
+  config check  Validate the configuration and print it with secrets redacted
+`
+
 func main() {
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
 	cfg := config.Load()
+	switch command {
+	case "serve", "worker", "scheduler", "migrate", "purge", "send":
+	case "config":
+		// Reports invalid configuration instead of refusing to start
+		if err := runConfig(cfg, args, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+
 	if err := cfg.Validate(); err != nil {
 		panic("Configuration validation failed: " + err.Error())
 	}
@@ -36,13 +83,9 @@ func main() {
 		zap.String("service", cfg.Service.Name),
 		zap.String("version", cfg.Service.Version),
 		zap.String("env", cfg.Service.Env),
-		zap.String("port", cfg.Service.Port),
+		zap.String("command", command),
 	)
 
-	tp := initTracing(cfg, logger)
-
-	initProfiling(cfg, logger)
-
 	pool, err := database.Connect(context.Background())
 	if err != nil {
 		logger.Error("Failed to connect to database", zap.Error(err))
@@ -51,17 +94,125 @@ func main() {
 	defer pool.Close()
 	logger.Info("Database connection pool established")
 
+	// fail ends a one-off command with exit status 1; os.Exit skips the deferred calls
+	fail := func(msg string, err error) {
+		logger.Error(msg, zap.Error(err))
+		pool.Close()
+		_ = logger.Sync()
+		os.Exit(1)
+	}
+
+	switch command {
+	case "migrate":
+		if err := runMigrate(args, os.Stdout); err != nil {
+			fail("Migration failed", err)
+		}
+		return
+	case "purge":
+		retentionService, err := newRetentionService(cfg, database.NewNotificationRepository())
+		if err != nil {
+			fail("Failed to configure retention", err)
+		}
+		if err := runPurge(retentionService, args, os.Stdout); err != nil {
+			fail("Purge failed", err)
+		}
+		return
+	}
+
 	// Dependency Injection
-	repo := database.NewNotificationRepository()
-	service := logicv1.NewNotificationService(repo)
-	handler := webv1.NewHandler(service)
+	a, err := newApp(cfg, logger)
+	if err != nil {
+		fail("Failed to initialize services", err)
+	}
+	if command == "send" {
+		if err := runSend(a, args, os.Stdout); err != nil {
+			fail("Send failed", err)
+		}
+		return
+	}
+
+	if err := runRole(a, command, args, pool); err != nil {
+		fail("Failed to start "+command, err)
+	}
+}
+
+// runRole runs a long-lived process role until SIGTERM. serve serves the HTTP API;
+// worker and scheduler run their jobs and only serve health checks and metrics.
+func runRole(a *app, role string, args []string, pool interface{ Close() }) error {
+	flags := flag.NewFlagSet(role, flag.ContinueOnError)
+	withJobs := flags.Bool("jobs", true, "serve: also run the worker and scheduler jobs")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
 
-	// Auth Client
-	authClient := middleware.NewAuthClient(cfg.AuthServiceURL)
+	tp := initTracing(a.cfg, a.logger)
+	initProfiling(a.cfg, a.logger)
+
+	jobs := newBackgroundJobs(a.logger)
+	var routes *handlers
+	var authClient *middleware.AuthClient
+	switch role {
+	case "serve":
+		routes = a.handlers()
+		authClient = middleware.NewAuthClient(a.cfg.AuthServiceURL)
+		if *withJobs {
+			a.startWorker(jobs)
+			a.startScheduler(jobs)
+		}
+	case "worker":
+		a.startWorker(jobs)
+	case "scheduler":
+		a.startScheduler(jobs)
+	}
 
 	var isShuttingDown atomic.Bool
-	srv := setupServer(cfg, logger, &isShuttingDown, handler, authClient)
-	runGracefulShutdown(cfg, srv, tp, pool, logger, &isShuttingDown)
+	srv := setupServer(a.cfg, a.logger, &isShuttingDown, routes, authClient)
+	runGracefulShutdown(a.cfg, srv, tp, pool, jobs, a.logger, &isShuttingDown)
+	return nil
+}
+
+// handlers groups the v1 HTTP handlers registered by setupServer.
+type handlers struct {
+	notification *webv1.Handler
+	device       *webv1.DeviceHandler
+	push         *webv1.PushHandler
+	webPush      *webv1.WebPushHandler
+	webhook      *webv1.WebhookHandler
+	callback     *webv1.CallbackHandler
+	suppression  *webv1.SuppressionHandler
+	unsubscribe  *webv1.UnsubscribeHandler
+	provider     *webv1.ProviderHandler
+}
+
+// retryPolicy converts the retry configuration to the webhook outbox redelivery schedule.
+func retryPolicy(cfg *config.Config) retry.Policy {
+	return retry.Policy{
+		MaxAttempts:    cfg.Retry.MaxAttempts,
+		InitialBackoff: cfg.Retry.InitialBackoff,
+		MaxBackoff:     cfg.Retry.MaxBackoff,
+		Multiplier:     2,
+	}
+}
+
+// deliveryPolicy converts the delivery configuration to the retry schedule of queued
+// sends their provider cannot take yet.
+func deliveryPolicy(cfg *config.Config) retry.Policy {
+	return retry.Policy{
+		MaxAttempts:    cfg.Delivery.MaxAttempts,
+		InitialBackoff: cfg.Delivery.InitialBackoff,
+		MaxBackoff:     cfg.Delivery.MaxBackoff,
+		Multiplier:     2,
+	}
+}
+
+// callbackPolicy converts the callback configuration to the outbox redelivery schedule.
+func callbackPolicy(cfg *config.Config) retry.Policy {
+	return retry.Policy{
+		MaxAttempts:    cfg.Callback.MaxAttempts,
+		InitialBackoff: cfg.Callback.InitialBackoff,
+		MaxBackoff:     cfg.Callback.MaxBackoff,
+		Multiplier:     2,
+	}
 }
 
 func initTracing(cfg *config.Config, logger *zap.Logger) interface{ Shutdown(context.Context) error } {
@@ -93,11 +244,299 @@ func initProfiling(cfg *config.Config, logger *zap.Logger) {
 	logger.Info("Profiling initialized", zap.String("endpoint", cfg.Profiling.Endpoint))
 }
 
+// initEmail builds the SMTP sender over the primary and backup relays, or returns nil
+// when SMTP is disabled and email notifications are only recorded.
+func initEmail(
+	cfg *config.Config,
+	logger *zap.Logger,
+	providers *health.Registry,
+	limiter *throttle.Limiter,
+) email.Sender {
+	if !cfg.Email.SMTP.Enabled {
+		logger.Info("SMTP disabled (SMTP_ENABLED=false), email notifications are recorded only")
+		return nil
+	}
+	var dkim *email.DKIMSigner
+	if cfg.Email.DKIM.Enabled {
+		signer, err := initDKIM(cfg, logger)
+		if err != nil {
+			logger.Warn("Failed to initialize DKIM signing, email is sent unsigned", zap.Error(err))
+		} else {
+			dkim = signer
+		}
+	}
+	backups, err := cfg.Email.SMTP.BackupRelays()
+	if err != nil {
+		logger.Warn("Invalid SMTP backup relays, sending through the primary relay only", zap.Error(err))
+	}
+
+	primary := config.SMTPRelay{
+		Host:     cfg.Email.SMTP.Host,
+		Port:     cfg.Email.SMTP.Port,
+		Username: cfg.Email.SMTP.Username,
+		Password: cfg.Email.SMTP.Password,
+	}
+	var relays []email.Relay
+	for _, relay := range append([]config.SMTPRelay{primary}, backups...) {
+		client, err := email.NewSMTPClient(email.SMTPConfig{
+			Host:     relay.Host,
+			Port:     relay.Port,
+			Username: relay.Username,
+			Password: relay.Password,
+			From:     cfg.Email.From,
+			Timeout:  cfg.Email.SMTP.Timeout,
+			DKIM:     dkim,
+		})
+		if err != nil {
+			logger.Warn("Failed to initialize SMTP client", zap.String("host", relay.Host), zap.Error(err))
+			continue
+		}
+		relays = append(relays, email.Relay{Name: fmt.Sprintf("%s:%d", relay.Host, relay.Port), Sender: client})
+		logger.Info("SMTP relay initialized", zap.String("host", relay.Host), zap.Int("port", relay.Port))
+	}
+
+	sender, err := email.NewFailoverSender(providers, limiter, relays)
+	if err != nil {
+		logger.Warn("Failed to initialize SMTP client", zap.Error(err))
+		return nil
+	}
+	return sender
+}
+
+// initDKIM loads the configured DKIM keys and logs the DNS TXT record each one needs.
+func initDKIM(cfg *config.Config, logger *zap.Logger) (*email.DKIMSigner, error) {
+	sources, err := cfg.Email.DKIM.KeySources()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]email.DKIMKey, 0, len(sources))
+	for _, source := range sources {
+		pemData := []byte(source.PEM)
+		if source.File != "" {
+			if pemData, err = os.ReadFile(source.File); err != nil {
+				return nil, fmt.Errorf("read dkim key for %s: %w", source.Domain, err)
+			}
+		}
+		key, err := email.ParseDKIMKey(source.Domain, source.Selector, pemData)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source.Domain, err)
+		}
+		record, err := key.DNSRecord()
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("DKIM key loaded",
+			zap.String("dns_name", key.DNSName()),
+			zap.String("dns_txt", record),
+		)
+		keys = append(keys, key)
+	}
+	return email.NewDKIMSigner(keys)
+}
+
+// initSMS builds the SMS router from the enabled providers and SMS_ROUTES. It returns
+// nil when no provider is enabled or the routing table is invalid.
+func initSMS(
+	cfg *config.Config,
+	logger *zap.Logger,
+	providers *health.Registry,
+	limiter *throttle.Limiter,
+) *sms.Router {
+	clients := make(map[string]sms.Provider)
+	if cfg.SMS.Twilio.Enabled {
+		if client, err := sms.NewTwilioClient(sms.TwilioConfig{
+			AccountSID: cfg.SMS.Twilio.AccountSID,
+			AuthToken:  cfg.SMS.Twilio.AuthToken,
+			BaseURL:    cfg.SMS.Twilio.BaseURL,
+		}); err != nil {
+			logger.Warn("Failed to initialize Twilio client", zap.Error(err))
+		} else {
+			clients["twilio"] = client
+		}
+	}
+	if cfg.SMS.Vonage.Enabled {
+		if client, err := sms.NewVonageClient(sms.VonageConfig{
+			APIKey:    cfg.SMS.Vonage.APIKey,
+			APISecret: cfg.SMS.Vonage.APISecret,
+			BaseURL:   cfg.SMS.Vonage.BaseURL,
+		}); err != nil {
+			logger.Warn("Failed to initialize Vonage client", zap.Error(err))
+		} else {
+			clients["vonage"] = client
+		}
+	}
+	if len(clients) == 0 {
+		logger.Info("No SMS provider enabled, SMS notifications are recorded only")
+		return nil
+	}
+
+	table, err := cfg.SMS.RouteTable()
+	if err != nil {
+		logger.Warn("Invalid SMS routing table", zap.Error(err))
+		return nil
+	}
+	routes := make([]sms.Route, 0, len(table))
+	for _, entry := range table {
+		route := sms.Route{Prefix: entry.Prefix}
+		for _, target := range entry.Providers {
+			from, err := sms.ParseSenderID(target.SenderID)
+			if err != nil {
+				logger.Warn("Invalid SMS sender ID", zap.String("route", entry.Prefix), zap.Error(err))
+				return nil
+			}
+			route.Targets = append(route.Targets, sms.RouteTarget{Provider: target.Provider, Weight: target.Weight, From: from})
+		}
+		routes = append(routes, route)
+	}
+
+	router, err := sms.NewRouter(clients, routes, providers, limiter)
+	if err != nil {
+		logger.Warn("Invalid SMS routing table", zap.Error(err))
+		return nil
+	}
+	logger.Info("SMS routing initialized", zap.Int("routes", len(routes)), zap.Int("providers", len(clients)))
+	return router
+}
+
+// initThrottle builds the limiter that paces sends to rate-limited providers, with the
+// schedule shared by all replicas through the database.
+func initThrottle(cfg *config.Config, logger *zap.Logger) *throttle.Limiter {
+	rates, err := cfg.Providers.Rates()
+	if err != nil {
+		logger.Warn("Invalid PROVIDER_RATE_LIMITS, provider sends are not paced", zap.Error(err))
+		rates = nil
+	}
+	for key, rate := range rates {
+		logger.Info("Provider rate limit", zap.String("provider", key), zap.Float64("per_second", rate))
+	}
+	return throttle.NewLimiter(database.NewProviderSlotRepository(), rates, retry.Policy{
+		MaxAttempts:    cfg.Providers.RateLimitAttempts,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Multiplier:     2,
+	})
+}
+
+// initPush builds the push dispatcher from the enabled providers. A provider that
+// fails to initialize is logged and skipped so the service can still start.
+func initPush(
+	cfg *config.Config,
+	logger *zap.Logger,
+	deactivator push.TokenDeactivator,
+	providers *health.Registry,
+	limiter *throttle.Limiter,
+) *push.Dispatcher {
+	dispatcher := push.NewDispatcher(deactivator)
+
+	if cfg.Push.FCM.Enabled {
+		credentials, err := os.ReadFile(cfg.Push.FCM.CredentialsFile)
+		if err != nil {
+			logger.Warn("Failed to read FCM credentials", zap.Error(err))
+		} else if client, err := push.NewFCMClient(push.FCMConfig{
+			ProjectID:       cfg.Push.FCM.ProjectID,
+			CredentialsJSON: credentials,
+			BaseURL:         cfg.Push.FCM.BaseURL,
+		}); err != nil {
+			logger.Warn("Failed to initialize FCM client", zap.Error(err))
+		} else {
+			sender := push.WithBreaker(client, providers.Breaker("push", "fcm"))
+			dispatcher.Register(push.PlatformAndroid, push.WithThrottle(sender, limiter, "fcm"))
+			logger.Info("FCM push initialized", zap.String("base_url", cfg.Push.FCM.BaseURL))
+		}
+	}
+
+	if cfg.Push.APNs.Enabled {
+		key, err := os.ReadFile(cfg.Push.APNs.KeyFile)
+		if err != nil {
+			logger.Warn("Failed to read APNs key", zap.Error(err))
+		} else if client, err := push.NewAPNsClient(push.APNsConfig{
+			KeyPEM:  key,
+			KeyID:   cfg.Push.APNs.KeyID,
+			TeamID:  cfg.Push.APNs.TeamID,
+			Topic:   cfg.Push.APNs.Topic,
+			BaseURL: cfg.Push.APNs.BaseURL,
+		}); err != nil {
+			logger.Warn("Failed to initialize APNs client", zap.Error(err))
+		} else {
+			sender := push.WithBreaker(client, providers.Breaker("push", "apns"))
+			dispatcher.Register(push.PlatformIOS, push.WithThrottle(sender, limiter, "apns"))
+			logger.Info("APNs push initialized", zap.String("base_url", cfg.Push.APNs.BaseURL))
+		}
+	}
+
+	return dispatcher
+}
+
+// initWebPush builds the Web Push client, or returns nil when Web Push is disabled.
+func initWebPush(
+	cfg *config.Config,
+	logger *zap.Logger,
+	providers *health.Registry,
+	limiter *throttle.Limiter,
+) *push.WebPushClient {
+	if !cfg.Push.WebPush.Enabled {
+		logger.Info("Web Push disabled (WEBPUSH_ENABLED=false)")
+		return nil
+	}
+	client, err := push.NewWebPushClient(push.WebPushConfig{
+		VAPIDPrivateKey: cfg.Push.WebPush.VAPIDPrivateKey,
+		Subject:         cfg.Push.WebPush.VAPIDSubject,
+		Health:          providers,
+		Limiter:         limiter,
+	})
+	if err != nil {
+		logger.Warn("Failed to initialize Web Push client", zap.Error(err))
+		return nil
+	}
+	logger.Info("Web Push initialized", zap.String("vapid_public_key", client.PublicKey()))
+	return client
+}
+
+// backgroundJobs runs periodic maintenance tasks until Stop is called.
+type backgroundJobs struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	logger *zap.Logger
+}
+
+func newBackgroundJobs(logger *zap.Logger) *backgroundJobs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &backgroundJobs{ctx: ctx, cancel: cancel, logger: logger}
+}
+
+// every runs job immediately and then once per interval.
+func (b *backgroundJobs) every(name string, interval time.Duration, job func(ctx context.Context) error) {
+	b.wg.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := job(b.ctx); err != nil && b.ctx.Err() == nil {
+				b.logger.Error("Background job failed", zap.String("job", name), zap.Error(err))
+			}
+			select {
+			case <-b.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Stop cancels all jobs and waits for in-flight runs to return.
+func (b *backgroundJobs) Stop() {
+	b.cancel()
+	b.wg.Wait()
+}
+
+// setupServer creates the HTTP server: health checks and metrics, plus the API routes
+// unless h is nil.
 func setupServer(
 	cfg *config.Config,
 	logger *zap.Logger,
 	isShuttingDown *atomic.Bool,
-	handler *webv1.Handler,
+	h *handlers,
 	authClient *middleware.AuthClient,
 ) *http.Server {
 	r := gin.Default()
@@ -117,30 +556,76 @@ func setupServer(
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	if h != nil {
+		registerRoutes(r, h, authClient)
+	}
+
+	return &http.Server{
+		Addr:              ":" + cfg.Service.Port,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
 
+// registerRoutes registers the v1 API routes.
+func registerRoutes(r *gin.Engine, h *handlers, authClient *middleware.AuthClient) {
 	// Notification v1 routes — Variant A edge naming (see api-naming-convention.md)
 
-	// Private: user-facing notification list/count/detail/mark-read (JWT required)
+	// Public: one-click unsubscribe links in email (no login; the token is the credential)
+	publicNotif := r.Group("/notification/v1/public")
+	{
+		publicNotif.GET("/unsubscribe", h.unsubscribe.ShowUnsubscribe)
+		publicNotif.POST("/unsubscribe", h.unsubscribe.Unsubscribe)
+	}
+
+	// Private: user-facing notification list/count/detail/mark-read/archive/delete/actions (JWT required)
 	privateNotif := r.Group("/notification/v1/private")
 	privateNotif.Use(middleware.AuthMiddleware(authClient))
 	{
-		privateNotif.GET("/notifications", handler.ListNotifications)
-		privateNotif.GET("/notifications/count", handler.GetUnreadCount)
-		privateNotif.GET("/notifications/:id", handler.GetNotification)
-		privateNotif.PATCH("/notifications/:id", handler.MarkAsRead)
+		privateNotif.GET("/notifications", h.notification.ListNotifications)
+		privateNotif.GET("/notifications/count", h.notification.GetUnreadCount)
+		privateNotif.GET("/notifications/:id", h.notification.GetNotification)
+		privateNotif.PATCH("/notifications/:id", h.notification.MarkAsRead)
+		privateNotif.PATCH("/notifications/groups/:group_key", h.notification.MarkGroupAsRead)
+		privateNotif.DELETE("/notifications/:id", h.notification.DeleteNotification)
+		privateNotif.POST("/notifications/:id/archive", h.notification.ArchiveNotification)
+		privateNotif.POST("/notifications/:id/restore", h.notification.RestoreNotification)
+		privateNotif.POST("/notifications/bulk", h.notification.BulkUpdateNotifications)
+		privateNotif.POST("/notifications/:id/actions/:action", h.notification.TakeAction)
+
+		privateNotif.POST("/devices", h.device.RegisterDevice)
+		privateNotif.PATCH("/devices", h.device.RefreshDevice)
+		privateNotif.DELETE("/devices", h.device.UnregisterDevice)
+
+		privateNotif.GET("/webpush/vapid-public-key", h.webPush.GetVAPIDPublicKey)
+		privateNotif.POST("/webpush/subscriptions", h.webPush.Subscribe)
+		privateNotif.DELETE("/webpush/subscriptions", h.webPush.Unsubscribe)
 	}
 
 	// Internal: service-to-service (e.g. order-service triggers email). Not on gateway.
 	internalNotif := r.Group("/notification/v1/internal")
 	{
-		internalNotif.POST("/notify/email", handler.SendEmail)
-		internalNotif.POST("/notify/sms", handler.SendSMS)
-	}
+		internalNotif.POST("/notify/email", h.notification.SendEmail)
+		internalNotif.POST("/notify/sms", h.notification.SendSMS)
+		internalNotif.POST("/notify/push", h.push.SendPush)
+		internalNotif.POST("/notify/webhook", h.webhook.SendWebhook)
 
-	return &http.Server{
-		Addr:              ":" + cfg.Service.Port,
-		Handler:           r,
-		ReadHeaderTimeout: 10 * time.Second,
+		internalNotif.POST("/webhooks", h.webhook.CreateWebhook)
+		internalNotif.GET("/webhooks", h.webhook.ListWebhooks)
+		internalNotif.DELETE("/webhooks/:id", h.webhook.DeleteWebhook)
+
+		internalNotif.PUT("/clients/:client_id", h.callback.RegisterClient)
+		internalNotif.GET("/clients/:client_id", h.callback.GetClient)
+		internalNotif.DELETE("/clients/:client_id", h.callback.DeleteClient)
+		internalNotif.POST("/notifications/:id/status", h.callback.UpdateStatus)
+
+		internalNotif.POST("/email/events", h.suppression.ProcessEmailEvents)
+		internalNotif.POST("/email/dsn", h.suppression.ProcessDSN)
+		internalNotif.POST("/suppressions", h.suppression.AddSuppression)
+		internalNotif.GET("/suppressions/:address", h.suppression.GetSuppression)
+		internalNotif.DELETE("/suppressions/:address", h.suppression.RemoveSuppression)
+
+		internalNotif.GET("/providers", h.provider.GetProviderStatus)
 	}
 }
 
@@ -149,6 +634,7 @@ func runGracefulShutdown(
 	srv *http.Server,
 	tp interface{ Shutdown(context.Context) error },
 	pool interface{ Close() },
+	jobs *backgroundJobs,
 	logger *zap.Logger,
 	isShuttingDown *atomic.Bool,
 ) {
@@ -184,6 +670,9 @@ func runGracefulShutdown(
 		logger.Info("HTTP server shutdown complete")
 	}
 
+	jobs.Stop()
+	logger.Info("Background jobs stopped")
+
 	pool.Close()
 	logger.Info("Database pool closed")
 
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/duynhne/notification-service/db/migrations"
+	database "github.com/duynhne/notification-service/internal/core"
+)
+
+// runMigrate runs the migrate subcommand on the embedded migrations: up [-out-of-order]
+// applies the pending ones, down [-steps n] undoes the latest, and status lists them all.
+func runMigrate(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up [-out-of-order]|down [-steps n]|status")
+	}
+	migrator, err := database.NewMigrator(migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	// Each migration commits on its own; an interrupted run keeps those already applied
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch args[0] {
+	case "up":
+		flags := flag.NewFlagSet("migrate up", flag.ContinueOnError)
+		outOfOrder := flags.Bool("out-of-order", false, "apply pending migrations older than the latest applied one")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		applied, err := migrator.Up(ctx, *outOfOrder)
+		for _, migration := range applied {
+			fmt.Fprintf(out, "applied  V%-6s %s\n", migration.Version, migration.Description)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(out, "schema is up to date")
+		}
+		return err
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		steps := flags.Int("steps", 1, "number of migrations to undo")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		undone, err := migrator.Down(ctx, *steps)
+		for _, migration := range undone {
+			fmt.Fprintf(out, "undone   V%-6s %s\n", migration.Version, migration.Description)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range statuses {
+			installedOn := ""
+			if migration.InstalledOn != nil {
+				installedOn = migration.InstalledOn.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%-8s V%-6s %-40s %s\n", migration.State, migration.Version, migration.Description, installedOn)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q: want up, down or status", args[0])
+	}
+}
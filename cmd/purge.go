@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/duynhne/notification-service/config"
+	database "github.com/duynhne/notification-service/internal/core"
+	"github.com/duynhne/notification-service/internal/core/domain"
+	logicv1 "github.com/duynhne/notification-service/internal/logic/v1"
+)
+
+// newRetentionService builds the retention purge from NOTIFICATION_RETENTION. Deleted
+// notifications are purged once their restore window has passed, finished webhook
+// deliveries after WEBHOOK_DELIVERY_RETENTION and callback events after
+// CALLBACK_EVENT_RETENTION.
+func newRetentionService(cfg *config.Config, repo domain.NotificationRepository) (*logicv1.RetentionService, error) {
+	rules, err := cfg.Retention.RetentionRules()
+	if err != nil {
+		return nil, err
+	}
+	retention := make([]domain.RetentionRule, 0, len(rules))
+	for _, rule := range rules {
+		retention = append(retention, domain.RetentionRule{Type: rule.Type, ReadOnly: rule.ReadOnly, MaxAge: rule.MaxAge})
+	}
+	return logicv1.NewRetentionService(
+		repo,
+		retention,
+		cfg.Inbox.RestoreWindow,
+		database.NewWebhookDeliveryRepository(),
+		cfg.Webhook.DeliveryRetention,
+		database.NewCallbackEventRepository(),
+		cfg.Callback.EventRetention,
+		cfg.Retention.PurgeBatchSize,
+	), nil
+}
+
+// runPurge runs the purge subcommand: a one-off retention purge that prints the count
+// of each rule, or with -dry-run only counts what the purge would delete.
+func runPurge(service *logicv1.RetentionService, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("purge", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "count the rows past their retention without deleting them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// Stop between batches on Ctrl-C; deleted batches stay deleted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	results, err := service.Purge(ctx, *dryRun)
+	verb := "purged"
+	if *dryRun {
+		verb = "would purge"
+	}
+	for _, result := range results {
+		fmt.Fprintf(out, "%-32s %s %d\n", result.Rule, verb, result.Count)
+	}
+	return err
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+)
+
+// runSend runs the send subcommand: it queues a test notification as the internal API
+// would, sends the due queue through the configured providers like the worker, and
+// prints the notification with its resulting status as JSON.
+func runSend(a *app, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: send email|sms|push [flags]")
+	}
+	channel, args := args[0], args[1:]
+
+	flags := flag.NewFlagSet("send "+channel, flag.ContinueOnError)
+	priority := flags.String("priority", "", "critical, high, normal or low (default normal)")
+	var build func() any
+	switch channel {
+	case "email":
+		to := flags.String("to", "", "recipient address")
+		subject := flags.String("subject", "Test notification", "subject")
+		body := flags.String("body", "This is a test notification.", "plain-text body")
+		build = func() any {
+			return &domain.SendEmailRequest{To: *to, Subject: *subject, Body: *body, Priority: *priority}
+		}
+	case "sms":
+		to := flags.String("to", "", "recipient number, E.164 or national format of SMS_DEFAULT_REGION")
+		message := flags.String("message", "This is a test notification.", "message")
+		build = func() any {
+			return &domain.SendSMSRequest{To: *to, Message: *message, Priority: *priority}
+		}
+	case "push":
+		userID := flags.Int("user", 0, "ID of the user whose devices receive the push")
+		title := flags.String("title", "Test notification", "title")
+		body := flags.String("body", "This is a test notification.", "body")
+		build = func() any {
+			return &domain.SendPushRequest{UserID: *userID, Title: *title, Body: *body, Priority: *priority}
+		}
+	default:
+		return fmt.Errorf("unknown channel %q: want email, sms or push", channel)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// Requests are validated like the internal API's
+	req := build()
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var notification *domain.Notification
+	var err error
+	switch req := req.(type) {
+	case *domain.SendEmailRequest:
+		notification, err = a.notifications.SendEmail(ctx, *req)
+	case *domain.SendSMSRequest:
+		notification, err = a.notifications.SendSMS(ctx, *req)
+	case *domain.SendPushRequest:
+		notification, err = a.push.SendPush(ctx, *req)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Send without waiting for a worker; one that runs meanwhile may take the send instead
+	batch := a.cfg.Delivery.BatchSize
+	for {
+		claimed, err := a.deliveries.DispatchDue(ctx, batch)
+		if err != nil {
+			return err
+		}
+		if claimed < batch {
+			break
+		}
+	}
+	result, err := a.notifications.GetNotification(ctx, notification.PublicID.String())
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
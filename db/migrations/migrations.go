@@ -0,0 +1,12 @@
+// Package migrations embeds the SQL migrations of the notifications database so the
+// service binary can apply them itself. sql/ holds the Flyway versioned migrations, also
+// shipped in the Flyway image; undo/ holds their optional undo scripts, which only the
+// built-in runner uses.
+package migrations
+
+import "embed"
+
+// FS contains sql/V<version>__<description>.sql and undo/U<version>__<description>.sql.
+//
+//go:embed sql/*.sql undo/*.sql
+var FS embed.FS
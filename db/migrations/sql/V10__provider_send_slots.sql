@@ -0,0 +1,7 @@
+-- V10__provider_send_slots.sql
+-- Send schedule of rate-limited providers, shared by all replicas
+
+CREATE TABLE IF NOT EXISTS provider_send_slots (
+    provider VARCHAR(255) PRIMARY KEY,  -- channel/provider, e.g. sms/twilio
+    next_slot TIMESTAMPTZ NOT NULL       -- Earliest start of the next send
+);
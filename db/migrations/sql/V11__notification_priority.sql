@@ -0,0 +1,4 @@
+-- V11__notification_priority.sql
+-- Delivery priority of notifications (critical, high, normal, low)
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS priority VARCHAR(10) NOT NULL DEFAULT 'normal';
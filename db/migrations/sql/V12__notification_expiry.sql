@@ -0,0 +1,4 @@
+-- V12__notification_expiry.sql
+-- Expiry of notifications: expired notifications are not sent and are hidden from in-app lists
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;  -- NULL = never expires
@@ -0,0 +1,11 @@
+-- V13__notification_metadata.sql
+-- Structured context of notifications: metadata, links, images and the source entity
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS metadata JSONB;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS action_url TEXT;         -- Web URL or app deep link
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS image_url TEXT;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS icon_url TEXT;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS entity_type VARCHAR(50);  -- e.g. order
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS entity_id VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_entity ON notifications(entity_type, entity_id) WHERE entity_type IS NOT NULL;
@@ -0,0 +1,6 @@
+-- V14__notification_actions.sql
+-- Actionable notifications: buttons shown in-app and the action the user chose
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS actions JSONB;              -- [{"id", "label", "url"}]
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS action_taken VARCHAR(50);   -- id of the chosen action
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS action_taken_at TIMESTAMP;
@@ -0,0 +1,8 @@
+-- V15__notification_archive.sql
+-- Archive and soft delete of in-app notifications; deleted rows are restorable for a grace period
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;   -- NULL = not deleted
+
+-- Inbox and archive lists only read live rows
+CREATE INDEX IF NOT EXISTS idx_notifications_user_live ON notifications(user_id, created_at DESC) WHERE deleted_at IS NULL;
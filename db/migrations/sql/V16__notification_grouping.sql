@@ -0,0 +1,12 @@
+-- V16__notification_grouping.sql
+-- Group keys for grouped in-app lists and collapse keys for replacing unread notifications
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS group_key VARCHAR(100);
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS collapse_key VARCHAR(100);
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS replaced_by INTEGER;  -- Newer notification with the same collapse key
+
+-- Group mark-read and collapsing only touch a user's live rows with a key
+CREATE INDEX IF NOT EXISTS idx_notifications_user_group ON notifications(user_id, group_key)
+    WHERE group_key IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_user_collapse ON notifications(user_id, collapse_key)
+    WHERE collapse_key IS NOT NULL AND deleted_at IS NULL AND read = false;
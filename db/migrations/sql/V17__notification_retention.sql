@@ -0,0 +1,5 @@
+-- V17__notification_retention.sql
+-- Indexes for the retention purge of old and long-deleted notifications
+
+CREATE INDEX IF NOT EXISTS idx_notifications_type_created ON notifications(type, created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_deleted ON notifications(deleted_at) WHERE deleted_at IS NOT NULL;
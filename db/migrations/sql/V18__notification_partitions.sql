@@ -0,0 +1,130 @@
+-- V18__notification_partitions.sql
+-- Monthly range partitions of notifications by created_at. The table is rebuilt: the
+-- primary key becomes (id, created_at) and existing rows are copied into their month.
+
+-- Creates the missing monthly partitions notifications_pYYYY_MM from from_month through
+-- to_month and returns how many it created. Rows of the month that went to the default
+-- partition while its partition was missing are moved into it.
+CREATE OR REPLACE FUNCTION create_notification_partitions(from_month DATE, to_month DATE) RETURNS INTEGER AS $$
+DECLARE
+    part_month DATE := date_trunc('month', from_month)::date;
+    part_name TEXT;
+    part_from TIMESTAMP;
+    part_to TIMESTAMP;
+    created INTEGER := 0;
+BEGIN
+    WHILE part_month <= to_month LOOP
+        part_name := 'notifications_p' || to_char(part_month, 'YYYY_MM');
+        part_from := part_month;
+        part_to := part_month + INTERVAL '1 month';
+        IF to_regclass(part_name) IS NULL THEN
+            -- Attaching takes a weaker lock on notifications than CREATE TABLE ... PARTITION OF
+            EXECUTE format('CREATE TABLE %I (LIKE notifications INCLUDING CONSTRAINTS)', part_name);
+            EXECUTE format('WITH moved AS (DELETE FROM notifications_default WHERE created_at >= %L AND created_at < %L RETURNING *)
+                INSERT INTO %I SELECT * FROM moved', part_from, part_to, part_name);
+            EXECUTE format('ALTER TABLE notifications ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)',
+                part_name, part_from, part_to);
+            created := created + 1;
+        END IF;
+        part_month := (part_month + INTERVAL '1 month')::date;
+    END LOOP;
+    RETURN created;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Detaches the monthly partitions that end on or before before_month and returns their
+-- names. Detached tables are kept for archiving; dropping them is left to operators.
+CREATE OR REPLACE FUNCTION detach_notification_partitions(before_month DATE) RETURNS SETOF TEXT AS $$
+DECLARE
+    part_name TEXT;
+BEGIN
+    FOR part_name IN
+        SELECT c.relname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+        WHERE i.inhparent = 'notifications'::regclass
+            AND c.relname ~ '^notifications_p[0-9]{4}_[0-9]{2}$'
+            AND to_date(substr(c.relname, 16), 'YYYY_MM') + INTERVAL '1 month' <= before_month
+        ORDER BY c.relname
+    LOOP
+        EXECUTE format('ALTER TABLE notifications DETACH PARTITION %I', part_name);
+        RETURN NEXT part_name;
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE notifications RENAME TO notifications_unpartitioned;
+ALTER SEQUENCE notifications_id_seq OWNED BY NONE;  -- Keep the ids when the old table is dropped
+
+CREATE TABLE notifications (
+    id INTEGER NOT NULL DEFAULT nextval('notifications_id_seq'),
+    user_id INTEGER NOT NULL,  -- References auth.users.id (cross-cluster, no FK)
+    title VARCHAR(255) NOT NULL,
+    message TEXT,
+    type VARCHAR(50),
+    read BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'sent',
+    status_reason TEXT,
+    status_updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    recipient VARCHAR(320),
+    client_id VARCHAR(64),
+    callback_url TEXT,
+    sms_encoding VARCHAR(8),
+    sms_segments SMALLINT,
+    priority VARCHAR(10) NOT NULL DEFAULT 'normal',
+    expires_at TIMESTAMP,
+    metadata JSONB,
+    action_url TEXT,
+    image_url TEXT,
+    icon_url TEXT,
+    entity_type VARCHAR(50),
+    entity_id VARCHAR(255),
+    actions JSONB,
+    action_taken VARCHAR(50),
+    action_taken_at TIMESTAMP,
+    archived_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    group_key VARCHAR(100),
+    collapse_key VARCHAR(100),
+    replaced_by INTEGER,
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+ALTER SEQUENCE notifications_id_seq OWNED BY notifications.id;
+
+-- Takes the rows of months whose partition is missing, so inserts never fail when
+-- partition maintenance falls behind; creating the partition moves them out
+CREATE TABLE notifications_default PARTITION OF notifications DEFAULT;
+
+-- Partitions for every month with rows, and three months ahead
+SELECT create_notification_partitions(
+    COALESCE((SELECT MIN(created_at) FROM notifications_unpartitioned), LOCALTIMESTAMP)::date,
+    GREATEST((SELECT MAX(created_at) FROM notifications_unpartitioned), LOCALTIMESTAMP + INTERVAL '3 months')::date);
+
+INSERT INTO notifications (id, user_id, title, message, type, read, created_at,
+        status, status_reason, status_updated_at, recipient, client_id, callback_url, sms_encoding, sms_segments,
+        priority, expires_at, metadata, action_url, image_url, icon_url, entity_type, entity_id,
+        actions, action_taken, action_taken_at, archived_at, deleted_at, group_key, collapse_key, replaced_by)
+    SELECT id, user_id, title, message, type, read, COALESCE(created_at, LOCALTIMESTAMP),
+        status, status_reason, status_updated_at, recipient, client_id, callback_url, sms_encoding, sms_segments,
+        priority, expires_at, metadata, action_url, image_url, icon_url, entity_type, entity_id,
+        actions, action_taken, action_taken_at, archived_at, deleted_at, group_key, collapse_key, replaced_by
+    FROM notifications_unpartitioned;
+
+-- Seeded rows were inserted with explicit ids
+SELECT setval('notifications_id_seq', (SELECT COALESCE(MAX(id), 0) + 1 FROM notifications), false);
+
+DROP TABLE notifications_unpartitioned;
+
+-- User-facing reads filter by user and order by created_at; the boolean read index is
+-- replaced by a partial index of unread rows
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON notifications(user_id, created_at DESC)
+    WHERE read = false AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_user_deleted ON notifications(user_id, deleted_at)
+    WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_user_group ON notifications(user_id, group_key)
+    WHERE group_key IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_user_collapse ON notifications(user_id, collapse_key)
+    WHERE collapse_key IS NOT NULL AND deleted_at IS NULL AND read = false;
+CREATE INDEX IF NOT EXISTS idx_notifications_entity ON notifications(entity_type, entity_id) WHERE entity_type IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_type_created ON notifications(type, created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_deleted ON notifications(deleted_at) WHERE deleted_at IS NOT NULL;
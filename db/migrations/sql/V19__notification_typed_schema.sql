@@ -0,0 +1,182 @@
+-- V19__notification_typed_schema.sql
+-- BIGINT ids, timestamptz times, NOT NULL/CHECK constraints on type and message, and
+-- read_at/updated_at columns. created_at is the partition key and cannot change type
+-- in place, so the table is rebuilt as in V18. Existing times are taken to be UTC.
+--
+-- Ids become GENERATED ALWAYS AS IDENTITY, so they can no longer be set by callers.
+-- Identity columns on partitioned tables need PostgreSQL 17 or later.
+--
+-- Partitions detached by maintenance are renamed notifications_pYYYY_MM_detached, so
+-- that their names are free for partitions of the rebuilt table.
+
+DO $$
+BEGIN
+    IF current_setting('server_version_num')::int < 170000 THEN
+        RAISE EXCEPTION 'V19 needs PostgreSQL 17 or later (server is %)', current_setting('server_version');
+    END IF;
+END;
+$$;
+
+-- Partition bounds are UTC month starts
+CREATE OR REPLACE FUNCTION create_notification_partitions(from_month DATE, to_month DATE) RETURNS INTEGER AS $$
+DECLARE
+    part_month DATE := date_trunc('month', from_month)::date;
+    part_name TEXT;
+    part_from TIMESTAMPTZ;
+    part_to TIMESTAMPTZ;
+    created INTEGER := 0;
+BEGIN
+    WHILE part_month <= to_month LOOP
+        part_name := 'notifications_p' || to_char(part_month, 'YYYY_MM');
+        part_from := part_month::timestamp AT TIME ZONE 'UTC';
+        part_to := (part_month + INTERVAL '1 month')::timestamp AT TIME ZONE 'UTC';
+        IF to_regclass(part_name) IS NULL THEN
+            EXECUTE format('CREATE TABLE %I (LIKE notifications INCLUDING CONSTRAINTS)', part_name);
+            EXECUTE format('WITH moved AS (DELETE FROM notifications_default WHERE created_at >= %L AND created_at < %L RETURNING *)
+                INSERT INTO %I SELECT * FROM moved', part_from, part_to, part_name);
+            EXECUTE format('ALTER TABLE notifications ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)',
+                part_name, part_from, part_to);
+            created := created + 1;
+        END IF;
+        part_month := (part_month + INTERVAL '1 month')::date;
+    END LOOP;
+    RETURN created;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Detaches the monthly partitions that end on or before before_month, renames them
+-- notifications_pYYYY_MM_detached and returns their new names. Detached tables are kept
+-- for archiving; dropping them is left to operators.
+CREATE OR REPLACE FUNCTION detach_notification_partitions(before_month DATE) RETURNS SETOF TEXT AS $$
+DECLARE
+    part_name TEXT;
+BEGIN
+    FOR part_name IN
+        SELECT c.relname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+        WHERE i.inhparent = 'notifications'::regclass
+            AND c.relname ~ '^notifications_p[0-9]{4}_[0-9]{2}$'
+            AND to_date(substr(c.relname, 16), 'YYYY_MM') + INTERVAL '1 month' <= before_month
+        ORDER BY c.relname
+    LOOP
+        EXECUTE format('ALTER TABLE notifications DETACH PARTITION %I', part_name);
+        EXECUTE format('ALTER TABLE %I RENAME TO %I', part_name, part_name || '_detached');
+        RETURN NEXT part_name || '_detached';
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Move the old table and its partitions out of the way of the new names
+ALTER TABLE notifications RENAME TO notifications_v18;
+DO $$
+DECLARE
+    part_name TEXT;
+BEGIN
+    FOR part_name IN
+        SELECT c.relname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+        WHERE i.inhparent = 'notifications_v18'::regclass
+    LOOP
+        EXECUTE format('ALTER TABLE %I RENAME TO %I', part_name, replace(part_name, 'notifications_', 'notifications_v18_'));
+    END LOOP;
+END;
+$$;
+-- Partitions detached before V19 still hold the names of their months
+DO $$
+DECLARE
+    part_name TEXT;
+BEGIN
+    FOR part_name IN
+        SELECT c.relname FROM pg_class c
+        WHERE c.relkind = 'r' AND c.relnamespace = current_schema()::regnamespace
+            AND c.relname ~ '^notifications_p[0-9]{4}_[0-9]{2}$'
+    LOOP
+        EXECUTE format('ALTER TABLE %I RENAME TO %I', part_name, part_name || '_detached');
+    END LOOP;
+END;
+$$;
+
+-- Free the sequence name for the identity column; the sequence goes with the old table
+ALTER SEQUENCE notifications_id_seq RENAME TO notifications_v18_id_seq;
+ALTER SEQUENCE notifications_v18_id_seq OWNED BY notifications_v18.id;
+
+CREATE TABLE notifications (
+    id BIGINT GENERATED ALWAYS AS IDENTITY,
+    user_id INTEGER NOT NULL,  -- References auth.users.id (cross-cluster, no FK)
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL CHECK (message <> ''),
+    type VARCHAR(50) NOT NULL CHECK (type <> ''),
+    read BOOLEAN NOT NULL DEFAULT FALSE,
+    read_at TIMESTAMPTZ,  -- NULL for notifications read before V19
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'sent',
+    status_reason TEXT,
+    status_updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    recipient VARCHAR(320),
+    client_id VARCHAR(64),
+    callback_url TEXT,
+    sms_encoding VARCHAR(8),
+    sms_segments SMALLINT,
+    priority VARCHAR(10) NOT NULL DEFAULT 'normal',
+    expires_at TIMESTAMPTZ,
+    metadata JSONB,
+    action_url TEXT,
+    image_url TEXT,
+    icon_url TEXT,
+    entity_type VARCHAR(50),
+    entity_id VARCHAR(255),
+    actions JSONB,
+    action_taken VARCHAR(50),
+    action_taken_at TIMESTAMPTZ,
+    archived_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    group_key VARCHAR(100),
+    collapse_key VARCHAR(100),
+    replaced_by BIGINT,
+    PRIMARY KEY (id, created_at),
+    CHECK (read OR read_at IS NULL)
+) PARTITION BY RANGE (created_at);
+
+CREATE TABLE notifications_default PARTITION OF notifications DEFAULT;
+
+SELECT create_notification_partitions(
+    COALESCE((SELECT MIN(created_at) FROM notifications_v18), LOCALTIMESTAMP)::date,
+    GREATEST((SELECT MAX(created_at) FROM notifications_v18), LOCALTIMESTAMP + INTERVAL '3 months')::date);
+
+INSERT INTO notifications (id, user_id, title, message, type, read, created_at, updated_at,
+        status, status_reason, status_updated_at, recipient, client_id, callback_url, sms_encoding, sms_segments,
+        priority, expires_at, metadata, action_url, image_url, icon_url, entity_type, entity_id,
+        actions, action_taken, action_taken_at, archived_at, deleted_at, group_key, collapse_key, replaced_by)
+    OVERRIDING SYSTEM VALUE
+    SELECT id, user_id, title,
+        COALESCE(NULLIF(message, ''), NULLIF(title, ''), COALESCE(NULLIF(type, ''), 'general')),
+        COALESCE(NULLIF(type, ''), 'general'),
+        COALESCE(read, false),
+        created_at AT TIME ZONE 'UTC',
+        GREATEST(created_at, status_updated_at, action_taken_at, archived_at, deleted_at) AT TIME ZONE 'UTC',
+        status, status_reason, status_updated_at AT TIME ZONE 'UTC', recipient, client_id, callback_url, sms_encoding, sms_segments,
+        priority, expires_at AT TIME ZONE 'UTC', metadata, action_url, image_url, icon_url, entity_type, entity_id,
+        actions, action_taken, action_taken_at AT TIME ZONE 'UTC', archived_at AT TIME ZONE 'UTC', deleted_at AT TIME ZONE 'UTC',
+        group_key, collapse_key, replaced_by
+    FROM notifications_v18;
+
+SELECT setval(pg_get_serial_sequence('notifications', 'id'),
+    (SELECT COALESCE(MAX(id), 0) + 1 FROM notifications), false);
+
+DROP TABLE notifications_v18;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON notifications(user_id, created_at DESC)
+    WHERE read = false AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_user_deleted ON notifications(user_id, deleted_at)
+    WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_user_group ON notifications(user_id, group_key)
+    WHERE group_key IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_user_collapse ON notifications(user_id, collapse_key)
+    WHERE collapse_key IS NOT NULL AND deleted_at IS NULL AND read = false;
+CREATE INDEX IF NOT EXISTS idx_notifications_entity ON notifications(entity_type, entity_id) WHERE entity_type IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_type_created ON notifications(type, created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_deleted ON notifications(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Tables referencing notifications by id
+ALTER TABLE callback_events ALTER COLUMN notification_id TYPE BIGINT;
+ALTER TABLE suppressions ALTER COLUMN notification_id TYPE BIGINT;
@@ -0,0 +1,30 @@
+-- V20__notification_public_ids.sql
+-- Public notification IDs: a UUIDv7 per notification, used by the API instead of the
+-- sequential integer id, which stays the internal key. The service assigns them at
+-- create time; the default covers rows inserted by other means.
+
+-- Returns a version 7 UUID for ts: 48 bits of Unix milliseconds followed by random bits,
+-- so IDs sort by creation time
+CREATE OR REPLACE FUNCTION notification_uuid_v7(ts TIMESTAMPTZ DEFAULT clock_timestamp()) RETURNS UUID AS $$
+    SELECT encode(
+        set_bit(set_bit(
+            overlay(uuid_send(gen_random_uuid())
+                PLACING substring(int8send(floor(extract(epoch FROM ts) * 1000)::bigint) FROM 3)
+                FROM 1 FOR 6),
+            52, 1), 53, 1),
+        'hex')::uuid;
+$$ LANGUAGE sql VOLATILE;
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS public_id UUID;
+UPDATE notifications SET public_id = notification_uuid_v7(created_at) WHERE public_id IS NULL;
+ALTER TABLE notifications ALTER COLUMN public_id SET DEFAULT notification_uuid_v7();
+ALTER TABLE notifications ALTER COLUMN public_id SET NOT NULL;
+
+-- Unique indexes of a partitioned table must include created_at, which does not make
+-- public_id unique by itself; UUIDv7 values do not collide in practice
+CREATE INDEX IF NOT EXISTS idx_notifications_public_id ON notifications(public_id);
+
+-- Suppressions keep the ID found in the bounce, public or (from mail sent before V20) numeric
+ALTER TABLE suppressions ALTER COLUMN notification_id TYPE TEXT;
+UPDATE suppressions s SET notification_id = n.public_id::text
+    FROM notifications n WHERE s.notification_id = n.id::text;
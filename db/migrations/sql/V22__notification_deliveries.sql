@@ -0,0 +1,20 @@
+-- V22__notification_deliveries.sql
+-- Queue of email, SMS and push sends: the API records the notification and queues its
+-- send here, and the worker claims due sends and hands them to the providers, so
+-- provider rate limits and outages delay sends instead of holding API requests
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id BIGINT NOT NULL,  -- notifications is partitioned, so no foreign key
+    notification_created_at TIMESTAMPTZ NOT NULL,  -- Partition key of the notification
+    channel VARCHAR(20) NOT NULL,  -- email | sms | push
+    priority VARCHAR(20) NOT NULL DEFAULT 'normal',
+    payload JSONB,  -- Channel-specific send details not stored on the notification
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_due ON notification_deliveries(next_attempt_at);
@@ -0,0 +1,5 @@
+-- V23__webhook_delivery_retention.sql
+-- Delivered and dead webhook deliveries are purged once older than
+-- WEBHOOK_DELIVERY_RETENTION; the purge finds them by age
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_finished ON webhook_deliveries(created_at) WHERE status <> 'pending';
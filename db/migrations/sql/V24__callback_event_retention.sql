@@ -0,0 +1,6 @@
+-- V24__callback_event_retention.sql
+-- Delivered and dead callback events are purged once older than
+-- CALLBACK_EVENT_RETENTION, and together with their notification; the purge finds
+-- them by age
+
+CREATE INDEX IF NOT EXISTS idx_callback_events_finished ON callback_events(created_at) WHERE status <> 'pending';
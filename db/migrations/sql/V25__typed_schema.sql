@@ -0,0 +1,43 @@
+-- V25__typed_schema.sql
+-- BIGINT ids and timestamptz times for the tables V19 left alone, as for notifications.
+-- Existing times are taken to be UTC.
+
+ALTER TABLE device_tokens
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN last_seen_at TYPE TIMESTAMPTZ USING last_seen_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC';
+ALTER SEQUENCE device_tokens_id_seq AS BIGINT;
+
+ALTER TABLE web_push_subscriptions
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN expiration_time TYPE TIMESTAMPTZ USING expiration_time AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC';
+ALTER SEQUENCE web_push_subscriptions_id_seq AS BIGINT;
+
+ALTER TABLE webhook_endpoints
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN disabled_at TYPE TIMESTAMPTZ USING disabled_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC';
+ALTER SEQUENCE webhook_endpoints_id_seq AS BIGINT;
+ALTER TABLE webhook_deliveries ALTER COLUMN endpoint_id TYPE BIGINT;
+
+ALTER TABLE api_clients
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+
+ALTER TABLE callback_events
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN next_attempt_at TYPE TIMESTAMPTZ USING next_attempt_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN delivered_at TYPE TIMESTAMPTZ USING delivered_at AT TIME ZONE 'UTC';
+ALTER SEQUENCE callback_events_id_seq AS BIGINT;
+
+ALTER TABLE suppressions
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN expires_at TYPE TIMESTAMPTZ USING expires_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+ALTER SEQUENCE suppressions_id_seq AS BIGINT;
+
+ALTER TABLE notification_preferences
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
@@ -0,0 +1,19 @@
+-- V3__device_tokens.sql
+-- Push device token registry
+
+CREATE TABLE IF NOT EXISTS device_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,  -- References auth.users.id (cross-cluster, no FK)
+    platform VARCHAR(20) NOT NULL,  -- android (FCM) | ios (APNs)
+    token TEXT NOT NULL,
+    app_version VARCHAR(50),
+    locale VARCHAR(35),
+    active BOOLEAN NOT NULL DEFAULT TRUE,  -- FALSE after the provider reports the token invalid
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_device_tokens_token UNIQUE (token)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_device_tokens_user_active ON device_tokens(user_id) WHERE active;
+CREATE INDEX IF NOT EXISTS idx_device_tokens_last_seen ON device_tokens(last_seen_at);
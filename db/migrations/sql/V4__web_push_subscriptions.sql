@@ -0,0 +1,17 @@
+-- V4__web_push_subscriptions.sql
+-- Browser Web Push (RFC 8030) subscriptions
+
+CREATE TABLE IF NOT EXISTS web_push_subscriptions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,  -- References auth.users.id (cross-cluster, no FK)
+    endpoint TEXT NOT NULL,  -- Push service URL, unique per browser installation
+    p256dh VARCHAR(128) NOT NULL,  -- Base64url user agent public key
+    auth VARCHAR(64) NOT NULL,  -- Base64url authentication secret
+    user_agent VARCHAR(512),
+    expiration_time TIMESTAMP,  -- Set when the browser reports a subscription expiry
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_web_push_subscriptions_endpoint UNIQUE (endpoint)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_web_push_subscriptions_user ON web_push_subscriptions(user_id);
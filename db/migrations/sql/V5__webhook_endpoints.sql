@@ -0,0 +1,20 @@
+-- V5__webhook_endpoints.sql
+-- Outbound webhook channel: partner endpoints owned by a user or a tenant
+
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER,  -- References auth.users.id (cross-cluster, no FK)
+    tenant_id VARCHAR(64),
+    url TEXT NOT NULL,
+    secret VARCHAR(128) NOT NULL,  -- HMAC-SHA256 signing secret
+    description VARCHAR(255),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMP,  -- Set when disabled after sustained failures
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_webhook_endpoints_owner CHECK ((user_id IS NULL) <> (tenant_id IS NULL))
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_user ON webhook_endpoints(user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_tenant ON webhook_endpoints(tenant_id) WHERE tenant_id IS NOT NULL;
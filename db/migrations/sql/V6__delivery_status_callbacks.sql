@@ -0,0 +1,39 @@
+-- V6__delivery_status_callbacks.sql
+-- Delivery status tracking and status-change callbacks to calling services
+
+-- Delivery status on notifications (existing rows were delivered synchronously)
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'sent';
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS status_reason TEXT;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS status_updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS recipient VARCHAR(320);
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS client_id VARCHAR(64);
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS callback_url TEXT;
+
+-- Calling services (X-Client-ID) with their default callback URL and signing secret
+CREATE TABLE IF NOT EXISTS api_clients (
+    client_id VARCHAR(64) PRIMARY KEY,
+    callback_url TEXT,
+    secret VARCHAR(128) NOT NULL,  -- HMAC-SHA256 signing secret for callbacks
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Outbox of status-change callbacks (at-least-once delivery with retries)
+CREATE TABLE IF NOT EXISTS callback_events (
+    id SERIAL PRIMARY KEY,
+    notification_id INTEGER NOT NULL,
+    client_id VARCHAR(64) NOT NULL,
+    url TEXT NOT NULL,
+    event_id VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',  -- pending | delivered | dead
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_callback_events_due ON callback_events(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_callback_events_notification ON callback_events(notification_id);
@@ -0,0 +1,15 @@
+-- V7__email_suppressions.sql
+-- Email suppression list fed by bounces and complaints
+
+CREATE TABLE IF NOT EXISTS suppressions (
+    id SERIAL PRIMARY KEY,
+    address VARCHAR(320) NOT NULL UNIQUE,  -- Normalized (lower-case) email address
+    reason VARCHAR(20) NOT NULL,           -- hard_bounce | soft_bounce | complaint | manual
+    source VARCHAR(50) NOT NULL,           -- Provider name, dsn or api
+    detail TEXT,
+    notification_id INTEGER,               -- Notification that bounced, if known
+    expires_at TIMESTAMP,                  -- NULL = permanent
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
@@ -0,0 +1,11 @@
+-- V8__notification_preferences.sql
+-- Per-user notification preferences (one-click unsubscribe)
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id INTEGER NOT NULL,  -- References auth.users.id (cross-cluster, no FK)
+    channel VARCHAR(20) NOT NULL,
+    category VARCHAR(50) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, channel, category)
+);
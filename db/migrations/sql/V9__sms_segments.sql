@@ -0,0 +1,5 @@
+-- V9__sms_segments.sql
+-- SMS encoding and segment count, recorded at send time for billing and reporting
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS sms_encoding VARCHAR(8);  -- gsm7 or ucs2
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS sms_segments SMALLINT;
@@ -0,0 +1,12 @@
+-- U20__notification_public_ids.sql
+-- Undoes V20: drops public notification IDs. Suppressions referencing a public ID are
+-- pointed back at the numeric ID; other non-numeric references are cleared.
+
+UPDATE suppressions s SET notification_id = n.id::text
+    FROM notifications n WHERE s.notification_id = n.public_id::text;
+UPDATE suppressions SET notification_id = NULL WHERE notification_id !~ '^[0-9]+$';
+ALTER TABLE suppressions ALTER COLUMN notification_id TYPE BIGINT USING notification_id::bigint;
+
+DROP INDEX IF EXISTS idx_notifications_public_id;
+ALTER TABLE notifications DROP COLUMN IF EXISTS public_id;
+DROP FUNCTION IF EXISTS notification_uuid_v7(TIMESTAMPTZ);
@@ -0,0 +1,4 @@
+-- U21__webhook_deliveries.sql
+-- Undoes V21: drops the webhook outbox, including events not yet delivered.
+
+DROP TABLE IF EXISTS webhook_deliveries;
@@ -0,0 +1,5 @@
+-- U22__notification_deliveries.sql
+-- Undoes V22: drops the send queue. Notifications still queued stay queued and are
+-- never sent.
+
+DROP TABLE IF EXISTS notification_deliveries;
@@ -0,0 +1,4 @@
+-- U23__webhook_delivery_retention.sql
+-- Undoes V23: drops the index of the webhook delivery purge.
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_finished;
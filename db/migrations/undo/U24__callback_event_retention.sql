@@ -0,0 +1,4 @@
+-- U24__callback_event_retention.sql
+-- Undoes V24: drops the index of the callback event purge.
+
+DROP INDEX IF EXISTS idx_callback_events_finished;
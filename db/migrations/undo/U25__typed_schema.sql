@@ -0,0 +1,43 @@
+-- U25__typed_schema.sql
+-- Undoes V25: times go back to TIMESTAMP in UTC and ids to INTEGER. Fails if an id
+-- no longer fits in an INTEGER.
+
+ALTER TABLE notification_preferences
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+
+ALTER SEQUENCE suppressions_id_seq AS INTEGER;
+ALTER TABLE suppressions
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN expires_at TYPE TIMESTAMP USING expires_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+
+ALTER SEQUENCE callback_events_id_seq AS INTEGER;
+ALTER TABLE callback_events
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN next_attempt_at TYPE TIMESTAMP USING next_attempt_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN delivered_at TYPE TIMESTAMP USING delivered_at AT TIME ZONE 'UTC';
+
+ALTER TABLE api_clients
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+
+ALTER TABLE webhook_deliveries ALTER COLUMN endpoint_id TYPE INTEGER;
+ALTER SEQUENCE webhook_endpoints_id_seq AS INTEGER;
+ALTER TABLE webhook_endpoints
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN disabled_at TYPE TIMESTAMP USING disabled_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC';
+
+ALTER SEQUENCE web_push_subscriptions_id_seq AS INTEGER;
+ALTER TABLE web_push_subscriptions
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN expiration_time TYPE TIMESTAMP USING expiration_time AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC';
+
+ALTER SEQUENCE device_tokens_id_seq AS INTEGER;
+ALTER TABLE device_tokens
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN last_seen_at TYPE TIMESTAMP USING last_seen_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC';
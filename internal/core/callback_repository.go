@@ -0,0 +1,262 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// APIClientRepository handles database operations for calling services and their callback settings.
+type APIClientRepository struct{}
+
+// NewAPIClientRepository creates a new APIClientRepository.
+func NewAPIClientRepository() *APIClientRepository {
+	return &APIClientRepository{}
+}
+
+// Upsert registers a client or updates its callback URL. The stored secret is only replaced
+// when rotateSecret is set. Returns true if the client was created.
+func (r *APIClientRepository) Upsert(ctx context.Context, client *domain.APIClient, rotateSecret bool) (bool, error) {
+	db := GetPool()
+	if db == nil {
+		return false, errors.New("database connection not available")
+	}
+
+	query := `INSERT INTO api_clients (client_id, callback_url, secret)
+		VALUES ($1, NULLIF($2, ''), $3)
+		ON CONFLICT (client_id) DO UPDATE SET
+			callback_url = EXCLUDED.callback_url,
+			secret = CASE WHEN $4 THEN EXCLUDED.secret ELSE api_clients.secret END,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING client_id, callback_url, secret, created_at, updated_at, (xmax = 0) AS inserted`
+
+	var created bool
+	row := db.QueryRow(ctx, query, client.ClientID, client.CallbackURL, client.Secret, rotateSecret)
+	if err := scanAPIClient(row, client, &created); err != nil {
+		return false, fmt.Errorf("upsert api client: %w", err)
+	}
+
+	return created, nil
+}
+
+// FindByID returns a client, or nil if it is not registered.
+func (r *APIClientRepository) FindByID(ctx context.Context, clientID string) (*domain.APIClient, error) {
+	db := GetPool()
+	if db == nil {
+		return nil, errors.New("database connection not available")
+	}
+
+	query := `SELECT client_id, callback_url, secret, created_at, updated_at, false FROM api_clients WHERE client_id = $1`
+
+	var client domain.APIClient
+	var created bool
+	if err := scanAPIClient(db.QueryRow(ctx, query, clientID), &client, &created); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query api client: %w", err)
+	}
+
+	return &client, nil
+}
+
+// Delete removes a client. Returns false if it is not registered.
+func (r *APIClientRepository) Delete(ctx context.Context, clientID string) (bool, error) {
+	db := GetPool()
+	if db == nil {
+		return false, errors.New("database connection not available")
+	}
+
+	result, err := db.Exec(ctx, `DELETE FROM api_clients WHERE client_id = $1`, clientID)
+	if err != nil {
+		return false, fmt.Errorf("delete api client: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+func scanAPIClient(row pgx.Row, client *domain.APIClient, created *bool) error {
+	var callbackURL *string
+
+	if err := row.Scan(&client.ClientID, &callbackURL, &client.Secret, &client.CreatedAt, &client.UpdatedAt, created); err != nil {
+		return err
+	}
+
+	client.CallbackURL = ""
+	if callbackURL != nil {
+		client.CallbackURL = *callbackURL
+	}
+	client.CreatedAt = client.CreatedAt.UTC()
+	client.UpdatedAt = client.UpdatedAt.UTC()
+	return nil
+}
+
+// CallbackEventRepository handles database operations for the status callback outbox.
+type CallbackEventRepository struct{}
+
+// NewCallbackEventRepository creates a new CallbackEventRepository.
+func NewCallbackEventRepository() *CallbackEventRepository {
+	return &CallbackEventRepository{}
+}
+
+// Enqueue stores a callback event for immediate delivery.
+func (r *CallbackEventRepository) Enqueue(ctx context.Context, event *domain.CallbackEvent) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	query := `INSERT INTO callback_events (notification_id, client_id, url, event_id, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	err := db.QueryRow(ctx, query, event.NotificationID, event.ClientID, event.URL, event.EventID, event.Payload).Scan(&event.ID)
+	if err != nil {
+		return fmt.Errorf("insert callback event: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimDue leases up to limit pending events whose next attempt is due. Each claimed event's
+// attempt counter is incremented and its next attempt pushed back by lease, so an event whose
+// worker dies mid-delivery is picked up again once the lease expires. SKIP LOCKED lets several
+// replicas claim disjoint batches concurrently.
+func (r *CallbackEventRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.CallbackEvent, error) {
+	db := GetPool()
+	if db == nil {
+		return nil, errors.New("database connection not available")
+	}
+
+	query := `WITH claimed AS (
+			UPDATE callback_events SET
+				attempts = attempts + 1,
+				next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+			WHERE id IN (
+				SELECT id FROM callback_events
+				WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, notification_id, client_id, url, event_id, payload, attempts
+		)
+		SELECT c.id, c.notification_id, c.client_id, c.url, c.event_id, c.payload, c.attempts, a.secret
+		FROM claimed c LEFT JOIN api_clients a ON a.client_id = c.client_id
+		ORDER BY c.id`
+
+	rows, err := db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim callback events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []domain.CallbackEvent
+	for rows.Next() {
+		var event domain.CallbackEvent
+		var secret *string
+		err := rows.Scan(&event.ID, &event.NotificationID, &event.ClientID, &event.URL, &event.EventID,
+			&event.Payload, &event.Attempts, &secret)
+		if err != nil {
+			return nil, fmt.Errorf("scan callback event: %w", err)
+		}
+		if secret != nil {
+			event.Secret = *secret
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate callback events: %w", err)
+	}
+
+	return events, nil
+}
+
+// MarkDelivered records a successful delivery.
+func (r *CallbackEventRepository) MarkDelivered(ctx context.Context, id int64) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	query := `UPDATE callback_events SET status = 'delivered', delivered_at = CURRENT_TIMESTAMP, last_error = NULL WHERE id = $1`
+	if _, err := db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("mark callback event delivered: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed attempt and schedules the next one after retryAfter.
+func (r *CallbackEventRepository) MarkFailed(ctx context.Context, id int64, retryAfter time.Duration, lastError string) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	query := `UPDATE callback_events SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2), last_error = $3
+		WHERE id = $1`
+	if _, err := db.Exec(ctx, query, id, retryAfter.Seconds(), lastError); err != nil {
+		return fmt.Errorf("mark callback event failed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkDead stops retrying an event after its last failed attempt.
+func (r *CallbackEventRepository) MarkDead(ctx context.Context, id int64, lastError string) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	query := `UPDATE callback_events SET status = 'dead', last_error = $2 WHERE id = $1`
+	if _, err := db.Exec(ctx, query, id, lastError); err != nil {
+		return fmt.Errorf("mark callback event dead: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFinished permanently deletes up to limit delivered or dead events created more
+// than olderThan ago. Returns the number deleted.
+func (r *CallbackEventRepository) DeleteFinished(ctx context.Context, olderThan time.Duration, limit int) (int, error) {
+	db := GetPool()
+	if db == nil {
+		return 0, errors.New("database connection not available")
+	}
+
+	query := `DELETE FROM callback_events WHERE id IN (
+		SELECT id FROM callback_events
+		WHERE status <> 'pending' AND created_at < CURRENT_TIMESTAMP - make_interval(secs => $1::float8)
+		ORDER BY created_at LIMIT $2 FOR UPDATE SKIP LOCKED)`
+	result, err := db.Exec(ctx, query, olderThan.Seconds(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("purge callback events: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// CountFinished returns the number of delivered or dead events created more than
+// olderThan ago.
+func (r *CallbackEventRepository) CountFinished(ctx context.Context, olderThan time.Duration) (int, error) {
+	db := GetPool()
+	if db == nil {
+		return 0, errors.New("database connection not available")
+	}
+
+	var count int
+	query := `SELECT COUNT(*) FROM callback_events
+		WHERE status <> 'pending' AND created_at < CURRENT_TIMESTAMP - make_interval(secs => $1::float8)`
+	if err := db.QueryRow(ctx, query, olderThan.Seconds()).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count callback events to purge: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// DeviceTokenRepository handles database operations for push device tokens.
+type DeviceTokenRepository struct{}
+
+// NewDeviceTokenRepository creates a new DeviceTokenRepository.
+func NewDeviceTokenRepository() *DeviceTokenRepository {
+	return &DeviceTokenRepository{}
+}
+
+const deviceTokenColumns = `id, user_id, platform, token, app_version, locale, active, last_seen_at, created_at`
+
+// Upsert registers a device token. Tokens are unique: re-registering the user's own
+// token refreshes and reactivates it, and a token of another user is only moved to
+// this user when it is inactive or was last seen before reassignBefore (e.g. the
+// device changed account without unregistering). Returns domain.ErrDeviceTokenInUse
+// if the token is active for another user.
+func (r *DeviceTokenRepository) Upsert(ctx context.Context, device *domain.DeviceToken, reassignBefore time.Time) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	query := `INSERT INTO device_tokens (user_id, platform, token, app_version, locale, active, last_seen_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), true, CURRENT_TIMESTAMP)
+		ON CONFLICT (token) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			platform = EXCLUDED.platform,
+			app_version = COALESCE(EXCLUDED.app_version, device_tokens.app_version),
+			locale = COALESCE(EXCLUDED.locale, device_tokens.locale),
+			active = true,
+			last_seen_at = CURRENT_TIMESTAMP
+		WHERE ` + reassignableToken + `
+		RETURNING ` + deviceTokenColumns
+
+	row := db.QueryRow(ctx, query, device.UserID, device.Platform, device.Token, device.AppVersion, device.Locale, reassignBefore)
+	if err := scanDeviceToken(row, device); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// The conflicting row failed the WHERE clause
+			return domain.ErrDeviceTokenInUse
+		}
+		return fmt.Errorf("upsert device token: %w", err)
+	}
+
+	return nil
+}
+
+// reassignableToken is true for a device_tokens row that $1 may register: its own,
+// an inactive one, or one last seen before $6.
+const reassignableToken = `(device_tokens.user_id = $1 OR NOT device_tokens.active OR device_tokens.last_seen_at < $6)`
+
+// Rotate refreshes a user's device token, optionally replacing it with a new token value.
+// Returns false if the old token is not registered to the user, and
+// domain.ErrDeviceTokenInUse if the new token is active for another user and was seen
+// since reassignBefore, as for Upsert.
+func (r *DeviceTokenRepository) Rotate(ctx context.Context, userID int, oldToken string, device *domain.DeviceToken, reassignBefore time.Time) (bool, error) {
+	db := GetPool()
+	if db == nil {
+		return false, errors.New("database connection not available")
+	}
+
+	newToken := device.Token
+	if newToken == "" {
+		newToken = oldToken
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// A rotated-to token may already exist (registered before the refresh call arrived).
+	// It is replaced under the same rule as Upsert; another user's active token is left alone.
+	if newToken != oldToken {
+		var owner int
+		var active bool
+		var lastSeenAt time.Time
+		err := tx.QueryRow(ctx, `SELECT user_id, active, last_seen_at FROM device_tokens WHERE token = $1 FOR UPDATE`,
+			newToken).Scan(&owner, &active, &lastSeenAt)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+		case err != nil:
+			return false, fmt.Errorf("find device token owner: %w", err)
+		case owner != userID && active && !lastSeenAt.Before(reassignBefore):
+			return false, domain.ErrDeviceTokenInUse
+		default:
+			if _, err := tx.Exec(ctx, `DELETE FROM device_tokens WHERE token = $1`, newToken); err != nil {
+				return false, fmt.Errorf("remove duplicate device token: %w", err)
+			}
+		}
+	}
+
+	query := `UPDATE device_tokens SET
+			token = $3,
+			app_version = COALESCE(NULLIF($4, ''), app_version),
+			locale = COALESCE(NULLIF($5, ''), locale),
+			active = true,
+			last_seen_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND token = $2
+		RETURNING ` + deviceTokenColumns
+
+	row := tx.QueryRow(ctx, query, userID, oldToken, newToken, device.AppVersion, device.Locale)
+	if err := scanDeviceToken(row, device); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("refresh device token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// Delete unregisters a user's device token. Returns false if it was not registered to the user.
+func (r *DeviceTokenRepository) Delete(ctx context.Context, userID int, token string) (bool, error) {
+	db := GetPool()
+	if db == nil {
+		return false, errors.New("database connection not available")
+	}
+
+	result, err := db.Exec(ctx, `DELETE FROM device_tokens WHERE user_id = $1 AND token = $2`, userID, token)
+	if err != nil {
+		return false, fmt.Errorf("delete device token: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// ListActiveByUserID returns all deliverable device tokens of a user.
+func (r *DeviceTokenRepository) ListActiveByUserID(ctx context.Context, userID int) ([]domain.DeviceToken, error) {
+	db := GetPool()
+	if db == nil {
+		return nil, errors.New("database connection not available")
+	}
+
+	query := `SELECT ` + deviceTokenColumns + ` FROM device_tokens
+		WHERE user_id = $1 AND active = true ORDER BY last_seen_at DESC`
+	rows, err := db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query device tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var devices []domain.DeviceToken
+	for rows.Next() {
+		var device domain.DeviceToken
+		if err := scanDeviceToken(rows, &device); err != nil {
+			return nil, fmt.Errorf("scan device token: %w", err)
+		}
+		devices = append(devices, device)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate device tokens: %w", err)
+	}
+
+	return devices, nil
+}
+
+// DeactivateToken marks a token undeliverable after the push provider rejected it.
+// Implements push.TokenDeactivator.
+func (r *DeviceTokenRepository) DeactivateToken(ctx context.Context, token string) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	if _, err := db.Exec(ctx, `UPDATE device_tokens SET active = false WHERE token = $1`, token); err != nil {
+		return fmt.Errorf("deactivate device token: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteStale removes inactive tokens and tokens not seen since lastSeenBefore.
+func (r *DeviceTokenRepository) DeleteStale(ctx context.Context, lastSeenBefore time.Time) (int64, error) {
+	db := GetPool()
+	if db == nil {
+		return 0, errors.New("database connection not available")
+	}
+
+	result, err := db.Exec(ctx, `DELETE FROM device_tokens WHERE active = false OR last_seen_at < $1`, lastSeenBefore)
+	if err != nil {
+		return 0, fmt.Errorf("delete stale device tokens: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func scanDeviceToken(row pgx.Row, device *domain.DeviceToken) error {
+	var appVersion, locale *string
+
+	err := row.Scan(&device.ID, &device.UserID, &device.Platform, &device.Token, &appVersion, &locale,
+		&device.Active, &device.LastSeenAt, &device.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	device.AppVersion = ""
+	if appVersion != nil {
+		device.AppVersion = *appVersion
+	}
+	device.Locale = ""
+	if locale != nil {
+		device.Locale = *locale
+	}
+	device.LastSeenAt = device.LastSeenAt.UTC()
+	device.CreatedAt = device.CreatedAt.UTC()
+	return nil
+}
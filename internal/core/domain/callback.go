@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Delivery statuses of a notification, reported to callers through status callbacks.
+const (
+	StatusQueued    = "queued"    // Accepted, not yet handed to a provider
+	StatusSent      = "sent"      // Accepted by the provider
+	StatusDelivered = "delivered" // Provider confirmed delivery to the recipient
+	StatusFailed    = "failed"    // Delivery permanently failed
+	StatusBounced   = "bounced"   // Recipient's server rejected the message after it was sent
+	StatusExpired   = "expired"   // Expired before it was handed to a provider; never sent
+)
+
+// APIClientRepository persists calling services and their callback settings.
+type APIClientRepository interface {
+	Upsert(ctx context.Context, client *APIClient, rotateSecret bool) (bool, error)
+	FindByID(ctx context.Context, clientID string) (*APIClient, error)
+	Delete(ctx context.Context, clientID string) (bool, error)
+}
+
+// CallbackEventRepository is the outbox of status-change callbacks awaiting delivery.
+type CallbackEventRepository interface {
+	Enqueue(ctx context.Context, event *CallbackEvent) error
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]CallbackEvent, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, retryAfter time.Duration, lastError string) error
+	MarkDead(ctx context.Context, id int64, lastError string) error
+	DeleteFinished(ctx context.Context, olderThan time.Duration, limit int) (int, error)
+	CountFinished(ctx context.Context, olderThan time.Duration) (int, error)
+}
+
+// APIClient is a calling service (e.g. order-service) identified by the X-Client-ID header.
+type APIClient struct {
+	ClientID    string    `json:"client_id"`
+	CallbackURL string    `json:"callback_url,omitempty"`
+	Secret      string    `json:"secret,omitempty"` // Only returned when created or rotated
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CallbackEvent is a signed status-change event queued for at-least-once delivery.
+type CallbackEvent struct {
+	ID             int64
+	NotificationID int64
+	ClientID       string
+	URL            string
+	EventID        string // Stable across retries so receivers can de-duplicate
+	Payload        []byte // JSON webhook event, signed at send time
+	Attempts       int    // Attempts made, including the one in progress
+	Secret         string // Signing secret of the client (empty if the client was deleted)
+}
+
+type RegisterClientRequest struct {
+	CallbackURL  string `json:"callback_url" binding:"omitempty,url,max=2048"`
+	RotateSecret bool   `json:"rotate_secret"`
+}
+
+type UpdateStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=sent delivered failed bounced"`
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+// ActionTakenEvent is the data of a "notification.action_taken" callback.
+type ActionTakenEvent struct {
+	NotificationID uuid.UUID `json:"notification_id"`
+	Type           string    `json:"type"`
+	UserID         int       `json:"user_id"`
+	Action         string    `json:"action"`
+	EntityType     string    `json:"entity_type,omitempty"`
+	EntityID       string    `json:"entity_id,omitempty"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+// StatusChangedEvent is the data of a "notification.status_changed" callback.
+type StatusChangedEvent struct {
+	NotificationID uuid.UUID `json:"notification_id"`
+	Channel        string    `json:"channel"`
+	Recipient      string    `json:"recipient,omitempty"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
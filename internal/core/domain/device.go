@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrDeviceTokenInUse is returned by DeviceTokenRepository.Upsert and Rotate when the
+// token is active for another user.
+var ErrDeviceTokenInUse = errors.New("device token registered to another user")
+
+// DeviceTokenRepository persists push device tokens registered by users.
+type DeviceTokenRepository interface {
+	// Upsert and Rotate only take a token over from another user when it is inactive
+	// or was last seen before reassignBefore.
+	Upsert(ctx context.Context, device *DeviceToken, reassignBefore time.Time) error
+	Rotate(ctx context.Context, userID int, oldToken string, device *DeviceToken, reassignBefore time.Time) (bool, error)
+	Delete(ctx context.Context, userID int, token string) (bool, error)
+	ListActiveByUserID(ctx context.Context, userID int) ([]DeviceToken, error)
+	DeactivateToken(ctx context.Context, token string) error
+	DeleteStale(ctx context.Context, lastSeenBefore time.Time) (int64, error)
+}
+
+type DeviceToken struct {
+	ID         int64     `json:"id,string"`
+	UserID     int       `json:"-"`
+	Platform   string    `json:"platform"`
+	Token      string    `json:"token"`
+	AppVersion string    `json:"app_version,omitempty"`
+	Locale     string    `json:"locale,omitempty"`
+	Active     bool      `json:"active"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type RegisterDeviceRequest struct {
+	Platform   string `json:"platform" binding:"required,oneof=android ios"`
+	Token      string `json:"token" binding:"required,max=4096"`
+	AppVersion string `json:"app_version" binding:"max=50"`
+	Locale     string `json:"locale" binding:"max=35"`
+}
+
+type RefreshDeviceRequest struct {
+	Token      string `json:"token" binding:"required,max=4096"`
+	NewToken   string `json:"new_token" binding:"max=4096"` // Set when the provider rotated the token
+	AppVersion string `json:"app_version" binding:"max=50"`
+	Locale     string `json:"locale" binding:"max=35"`
+}
+
+type UnregisterDeviceRequest struct {
+	Token string `json:"token" binding:"required,max=4096"`
+}
+
+type SendPushRequest struct {
+	UserID     int               `json:"user_id" binding:"required,min=1"`
+	Title      string            `json:"title" binding:"required"`
+	Body       string            `json:"body" binding:"required"`
+	Badge      *int              `json:"badge" binding:"omitempty,min=0"`
+	Data       map[string]string `json:"data"`
+	CollapseID string            `json:"collapse_id" binding:"max=64"`
+	TTLSeconds int               `json:"ttl_seconds" binding:"min=0"`
+	Priority   string            `json:"priority" binding:"omitempty,oneof=critical high normal low"` // Default: normal
+	ExpiresAt  *time.Time        `json:"expires_at"`                                                  // RFC 3339; not sent, nor listed in-app, after this time
+	Type       string            `json:"type" binding:"omitempty,max=50"`                             // In-app notification type (e.g. order_shipped); default: push
+	ClientID   string            `json:"-"`                                                           // Set from the X-Client-ID header
+
+	NotificationContext
+}
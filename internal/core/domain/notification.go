@@ -1,32 +1,236 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
 
+	"github.com/google/uuid"
+)
+
+// Notification priorities. Higher priorities are delivered first when sends queue up
+// behind a provider's rate limit.
+const (
+	PriorityCritical = "critical" // Security alerts, password resets
+	PriorityHigh     = "high"     // Time-sensitive transactional messages
+	PriorityNormal   = "normal"   // Default
+	PriorityLow      = "low"      // Marketing and digests
+)
+
+// Folders of a user's notification list.
+const (
+	FolderInbox   = "inbox"   // Neither archived nor deleted (default)
+	FolderArchive = "archive" // Archived, not deleted
+	FolderTrash   = "trash"   // Deleted and still restorable
+)
+
+// NotificationFilter selects the notifications of a user's list.
+type NotificationFilter struct {
+	Folder         string        // FolderInbox (default), FolderArchive or FolderTrash
+	IncludeExpired bool          // Include notifications past their expires_at
+	RestoreWindow  time.Duration // How long deleted notifications stay in the trash
+	ListWindow     time.Duration // Only notifications created within this window (0 = all)
+}
+
+// RetentionRule selects notifications old enough to be purged.
+type RetentionRule struct {
+	Type     string        // Notification type ("" = every type without a rule of its own)
+	ReadOnly bool          // Only purge notifications the user has read
+	MaxAge   time.Duration // Age after which notifications are purged
+}
+
+// NotificationKey locates a notification: its internal ID and the creation time the
+// table is partitioned by, so that lookups only read the partition holding it.
+type NotificationKey struct {
+	ID        int64
+	CreatedAt time.Time
+}
+
+// NotificationRepository persists notifications. Deleted notifications are invisible
+// to every method except ListByUserID's trash folder and Restore.
 type NotificationRepository interface {
 	Create(ctx context.Context, notification *Notification, userID int) error
-	FindByID(ctx context.Context, id int) (*Notification, error)
-	ListByUserID(ctx context.Context, userID int) ([]Notification, error)
-	MarkAsRead(ctx context.Context, id int) (bool, error)
-	CountUnreadByUserID(ctx context.Context, userID int) (int, error)
+	FindByID(ctx context.Context, key NotificationKey) (*Notification, error)
+	// FindKeys returns the keys of the notifications with the given public IDs, deleted
+	// ones included. Unknown public IDs are skipped.
+	FindKeys(ctx context.Context, publicIDs []uuid.UUID) ([]NotificationKey, error)
+	// FindLegacyKeys returns the keys of the notifications with the given internal IDs,
+	// issued before public IDs existed. Unknown IDs are skipped.
+	FindLegacyKeys(ctx context.Context, ids []int64) ([]NotificationKey, error)
+	ListByUserID(ctx context.Context, userID int, filter NotificationFilter) ([]Notification, error)
+	ListGroupsByUserID(ctx context.Context, userID int, filter NotificationFilter) ([]NotificationGroup, error)
+	MarkAsRead(ctx context.Context, key NotificationKey) (bool, error)
+	MarkGroupAsRead(ctx context.Context, userID int, groupKey string) (int, error)
+	CountUnreadByUserID(ctx context.Context, userID int, filter NotificationFilter) (int, error)
+	UpdateStatus(ctx context.Context, key NotificationKey, from, to, reason string) (bool, error)
+	RecordAction(ctx context.Context, key NotificationKey, action string) (bool, error)
+	Archive(ctx context.Context, userID int, keys []NotificationKey) (int, error)
+	SoftDelete(ctx context.Context, userID int, keys []NotificationKey) (int, error)
+	Restore(ctx context.Context, userID int, keys []NotificationKey, window time.Duration) (int, error)
+
+	// Retention: Delete* remove at most limit notifications per call; Count* report how
+	// many would be removed. excludeTypes are skipped by rules without a type.
+	DeleteOlderThan(ctx context.Context, rule RetentionRule, excludeTypes []string, limit int) (int, error)
+	CountOlderThan(ctx context.Context, rule RetentionRule, excludeTypes []string) (int, error)
+	DeleteTrashed(ctx context.Context, deletedFor time.Duration, limit int) (int, error)
+	CountTrashed(ctx context.Context, deletedFor time.Duration) (int, error)
 }
 
+// NotificationDeliveryRepository is the queue of email, SMS and push sends awaiting the
+// worker. Sends are removed once they reach a final status.
+type NotificationDeliveryRepository interface {
+	Enqueue(ctx context.Context, delivery *NotificationDelivery) error
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]NotificationDelivery, error)
+	Reschedule(ctx context.Context, id int64, retryAfter time.Duration, lastError string) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// NotificationDelivery is the queued send of a notification to its channel's providers.
+type NotificationDelivery struct {
+	ID           int64
+	Notification NotificationKey
+	Channel      string // email, sms or push
+	Priority     string // Sends of higher priority are claimed first
+	Payload      []byte // JSON send details not stored on the notification
+	Attempts     int    // Attempts made, including the one in progress
+}
+
+// NotificationPartitionRepository maintains the monthly partitions of the notifications
+// table. Months are taken from the database clock.
+type NotificationPartitionRepository interface {
+	// CreateAhead creates the missing partitions of the current month through monthsAhead
+	// months later and returns how many it created.
+	CreateAhead(ctx context.Context, monthsAhead int) (int, error)
+	// DetachOlderThan detaches the partitions that ended more than months months ago and
+	// returns the names they are kept under.
+	DetachOlderThan(ctx context.Context, months int) ([]string, error)
+}
+
+// Notification is a notification of any channel. Clients know it by PublicID; the
+// integer ID is internal. Times are serialized as RFC 3339 in UTC.
 type Notification struct {
-	ID        string `json:"id"`
-	Type      string `json:"type"`
-	Title     string `json:"title,omitempty"`
-	Message   string `json:"message"`
-	Status    string `json:"status"`
-	Read      bool   `json:"read"`
-	CreatedAt string `json:"created_at,omitempty"`
+	ID        int64      `json:"-"`
+	PublicID  uuid.UUID  `json:"id"` // UUIDv7, sortable by creation time
+	Type      string     `json:"type"`
+	Title     string     `json:"title,omitempty"`
+	Message   string     `json:"message"`
+	Status    string     `json:"status"`
+	Priority  string     `json:"priority"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"` // Unknown for notifications read before it was recorded
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// ExpiresAt is when the notification stops being relevant: it is not sent after
+	// this time and is hidden from the user's list. Nil = never expires.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	NotificationContext
+
+	// Action the user chose from Actions, and when
+	ActionTaken   string     `json:"action_taken,omitempty"`
+	ActionTakenAt *time.Time `json:"action_taken_at,omitempty"`
+
+	// When the user archived or deleted the notification
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+
+	// SMS only: encoding (gsm7 or ucs2) and billed segment count
+	SMSEncoding string `json:"sms_encoding,omitempty"`
+	SMSSegments int    `json:"sms_segments,omitempty"`
+
+	// Delivery tracking (internal; not exposed by the private API)
+	UserID       int    `json:"-"`
+	Recipient    string `json:"-"` // Email address or phone number the message was sent to
+	ClientID     string `json:"-"` // Calling service that requested the notification (X-Client-ID)
+	CallbackURL  string `json:"-"` // Per-request status callback URL, overrides the client's URL
+	StatusReason string `json:"-"`
+}
+
+// Key returns the key locating the notification.
+func (n *Notification) Key() NotificationKey {
+	return NotificationKey{ID: n.ID, CreatedAt: n.CreatedAt}
+}
+
+// NotificationContext tells clients what a notification is about so they can render
+// it, link to it and group it without parsing the message. Metadata is validated
+// against the schema registered for the notification type, if any.
+type NotificationContext struct {
+	Metadata   map[string]any `json:"metadata,omitempty"`
+	ActionURL  string         `json:"action_url,omitempty" binding:"omitempty,url,max=2048"`         // Web URL or app deep link (e.g. shop://orders/2)
+	ImageURL   string         `json:"image_url,omitempty" binding:"omitempty,http_url,max=2048"`     // Large image shown with the notification
+	IconURL    string         `json:"icon_url,omitempty" binding:"omitempty,http_url,max=2048"`      // Small icon or avatar
+	EntityType string         `json:"entity_type,omitempty" binding:"required_with=EntityID,max=50"` // Source entity (e.g. order)
+	EntityID   string         `json:"entity_id,omitempty" binding:"required_with=EntityType,max=255"`
+
+	// Notifications with the same group key are listed as one entry (e.g. price_drop:product-42).
+	// A new notification replaces the user's unread notifications with the same collapse key.
+	GroupKey    string `json:"group_key,omitempty" binding:"omitempty,max=100,excludesall=/"`
+	CollapseKey string `json:"collapse_key,omitempty" binding:"omitempty,max=100"`
+
+	Actions []NotificationAction `json:"actions,omitempty" binding:"omitempty,max=5,dive"` // Buttons shown by the in-app UI
+}
+
+// NotificationAction is a button of an actionable notification. The user's choice is
+// recorded and forwarded to the originating service as a "notification.action_taken"
+// callback.
+type NotificationAction struct {
+	ID    string `json:"id" binding:"required,max=50"` // Letters, digits, - and _ (e.g. rate_5)
+	Label string `json:"label" binding:"required,max=100"`
+	URL   string `json:"url,omitempty" binding:"omitempty,url,max=2048"` // Opened by the client after the action is recorded
+}
+
+// NotificationGroup is one entry of a grouped notification list: the latest notification
+// of a group and how many notifications the group holds. Notifications without a group
+// key form a group of their own.
+type NotificationGroup struct {
+	GroupKey    string       `json:"group_key,omitempty"`
+	Count       int          `json:"count"`
+	UnreadCount int          `json:"unread_count"`
+	Latest      Notification `json:"latest"`
+}
+
+// BulkNotificationRequest applies an inbox operation to several notifications of the user.
+type BulkNotificationRequest struct {
+	Operation string   `json:"operation" binding:"required,oneof=archive delete restore"`
+	IDs       []string `json:"ids" binding:"required,min=1,max=100,dive,uuid|numeric"`
 }
 
 type SendEmailRequest struct {
-	To      string `json:"to" binding:"required,email"`
-	Subject string `json:"subject" binding:"required"`
-	Body    string `json:"body" binding:"required"`
+	To          string            `json:"to" binding:"required,email"`
+	Cc          []string          `json:"cc" binding:"omitempty,max=50,dive,email"`
+	Bcc         []string          `json:"bcc" binding:"omitempty,max=50,dive,email"`
+	ReplyTo     string            `json:"reply_to" binding:"omitempty,email"`
+	Subject     string            `json:"subject" binding:"required"`
+	Body        string            `json:"body" binding:"required_without=HTML"` // Plain text
+	HTML        string            `json:"html"`                                 // Inline images are referenced as cid:<content_id>
+	Attachments []EmailAttachment `json:"attachments" binding:"omitempty,max=20,dive"`
+	UserID      int               `json:"user_id" binding:"omitempty,min=1"`
+	Category    string            `json:"category" binding:"omitempty,oneof=transactional promotion newsletter"` // Default: transactional
+	Priority    string            `json:"priority" binding:"omitempty,oneof=critical high normal low"`           // Default: normal
+	ExpiresAt   *time.Time        `json:"expires_at"`                                                            // RFC 3339; not sent after this time
+	CallbackURL string            `json:"callback_url" binding:"omitempty,url,max=2048"`
+	ClientID    string            `json:"-"` // Set from the X-Client-ID header
+
+	NotificationContext
+}
+
+// EmailAttachment is a file given either inline as base64 content or by a URL the
+// service fetches. Setting ContentID makes it an inline image of the HTML body.
+type EmailAttachment struct {
+	Filename    string `json:"filename" binding:"required,max=255"`
+	ContentType string `json:"content_type" binding:"omitempty,max=255"` // Sniffed from the content when empty
+	Content     string `json:"content" binding:"required_without=URL,excluded_with=URL,omitempty,base64"`
+	URL         string `json:"url" binding:"required_without=Content,omitempty,url,max=2048"`
+	ContentID   string `json:"content_id" binding:"omitempty,max=255,printascii"`
 }
 
 type SendSMSRequest struct {
-	To      string `json:"to" binding:"required"`
-	Message string `json:"message" binding:"required"`
+	To          string     `json:"to" binding:"required,max=32"` // E.164, or national format of the default region
+	Message     string     `json:"message" binding:"required"`
+	Priority    string     `json:"priority" binding:"omitempty,oneof=critical high normal low"` // Default: normal
+	ExpiresAt   *time.Time `json:"expires_at"`                                                  // RFC 3339; not sent after this time
+	CallbackURL string     `json:"callback_url" binding:"omitempty,url,max=2048"`
+	ClientID    string     `json:"-"` // Set from the X-Client-ID header
+
+	NotificationContext
 }
@@ -0,0 +1,24 @@
+package domain
+
+import "context"
+
+// Email categories. Transactional mail (receipts, password resets) cannot be opted out of;
+// every other category carries one-click unsubscribe headers.
+const (
+	CategoryTransactional = "transactional"
+	CategoryPromotion     = "promotion"
+	CategoryNewsletter    = "newsletter"
+)
+
+// PreferenceRepository persists per-user opt-outs by channel and category.
+// Users are opted in to every category they have no preference for.
+type PreferenceRepository interface {
+	Set(ctx context.Context, userID int, channel, category string, enabled bool) error
+	IsEnabled(ctx context.Context, userID int, channel, category string) (bool, error)
+}
+
+// UnsubscribeResult describes the preference changed by an unsubscribe token.
+type UnsubscribeResult struct {
+	Channel  string `json:"channel"`
+	Category string `json:"category"`
+}
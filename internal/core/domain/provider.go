@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ProviderSlotRepository keeps the send schedule of rate-limited providers, shared
+// by all replicas. Providers are keyed "channel/provider".
+type ProviderSlotRepository interface {
+	// Reserve books the provider's next free slot, interval after the previous one,
+	// and returns how long until it starts.
+	Reserve(ctx context.Context, provider string, interval time.Duration) (time.Duration, error)
+	// Pause moves the provider's next free slot to at least d from now.
+	Pause(ctx context.Context, provider string, d time.Duration) error
+}
+
+// ProviderHealth is the circuit breaker state and rolling health of a channel provider.
+type ProviderHealth struct {
+	Provider     string  `json:"provider"`
+	State        string  `json:"state"` // closed, half_open or open
+	StateSince   string  `json:"state_since"`
+	Requests     int     `json:"requests"` // Calls in the rolling window
+	Failures     int     `json:"failures"`
+	ErrorRate    float64 `json:"error_rate"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
+
+// ChannelHealth lists a channel's providers in priority order.
+type ChannelHealth struct {
+	Channel   string           `json:"channel"`
+	Active    string           `json:"active,omitempty"` // Provider taking the traffic; empty when all breakers are open
+	Providers []ProviderHealth `json:"providers"`
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Suppression reasons.
+const (
+	SuppressionHardBounce = "hard_bounce" // Mailbox does not exist or permanently rejects mail
+	SuppressionSoftBounce = "soft_bounce" // Transient failure (mailbox full, greylisting); expires
+	SuppressionComplaint  = "complaint"   // Recipient marked a message as spam
+	SuppressionManual     = "manual"      // Added by an operator or calling service
+)
+
+// SuppressionRepository persists email addresses that must not be mailed.
+type SuppressionRepository interface {
+	Upsert(ctx context.Context, suppression *Suppression, ttl time.Duration) error
+	FindActive(ctx context.Context, address string) (*Suppression, error)
+	Delete(ctx context.Context, address string) (bool, error)
+}
+
+// Suppression is a suppressed email address. Permanent suppressions have no ExpiresAt.
+type Suppression struct {
+	Address        string     `json:"address"`
+	Reason         string     `json:"reason"`
+	Source         string     `json:"source"` // Provider name, "dsn" or "api"
+	Detail         string     `json:"detail,omitempty"`
+	NotificationID string     `json:"notification_id,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type CreateSuppressionRequest struct {
+	Address          string `json:"address" binding:"required,email,max=320"`
+	Reason           string `json:"reason" binding:"omitempty,oneof=hard_bounce soft_bounce complaint manual"`
+	Detail           string `json:"detail" binding:"max=1000"`
+	ExpiresInSeconds int    `json:"expires_in_seconds" binding:"omitempty,min=1"`
+}
+
+// EmailEvent is a bounce or complaint reported by an email provider webhook.
+type EmailEvent struct {
+	Type           string `json:"type" binding:"required,oneof=bounce complaint"`
+	BounceType     string `json:"bounce_type" binding:"omitempty,oneof=hard soft"` // Defaults to hard
+	Recipient      string `json:"recipient" binding:"required,email,max=320"`
+	Diagnostic     string `json:"diagnostic" binding:"max=1000"`
+	NotificationID string `json:"notification_id"`
+}
+
+type EmailEventsRequest struct {
+	Provider string       `json:"provider" binding:"required,max=50"`
+	Events   []EmailEvent `json:"events" binding:"required,min=1,max=500,dive"`
+}
+
+// EmailEventsResult summarizes processed bounce and complaint events.
+type EmailEventsResult struct {
+	Processed  int `json:"processed"`
+	Suppressed int `json:"suppressed"`
+	Ignored    int `json:"ignored"` // Successful deliveries and soft bounces when soft suppression is off
+}
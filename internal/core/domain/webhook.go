@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// WebhookEndpointRepository persists partner webhook endpoints.
+type WebhookEndpointRepository interface {
+	Create(ctx context.Context, endpoint *WebhookEndpoint) error
+	ListByOwner(ctx context.Context, owner WebhookOwner, activeOnly bool) ([]WebhookEndpoint, error)
+	Delete(ctx context.Context, id int64) (bool, error)
+	RecordSuccess(ctx context.Context, id int64) error
+	RecordFailure(ctx context.Context, id int64, disableAfter int) (bool, error)
+}
+
+// WebhookDeliveryRepository is the outbox of webhook events awaiting delivery.
+type WebhookDeliveryRepository interface {
+	Enqueue(ctx context.Context, eventID string, payload []byte, endpointIDs []int64) error
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, retryAfter time.Duration, lastError string) error
+	MarkDead(ctx context.Context, id int64, lastError string) error
+	DeleteFinished(ctx context.Context, olderThan time.Duration, limit int) (int, error)
+	CountFinished(ctx context.Context, olderThan time.Duration) (int, error)
+}
+
+// WebhookOwner identifies who an endpoint belongs to: exactly one of UserID or TenantID is set.
+type WebhookOwner struct {
+	UserID   int
+	TenantID string
+}
+
+type WebhookEndpoint struct {
+	ID                  int64      `json:"id,string"`
+	UserID              int        `json:"user_id,omitempty"`
+	TenantID            string     `json:"tenant_id,omitempty"`
+	URL                 string     `json:"url"`
+	Secret              string     `json:"secret,omitempty"` // Only returned on creation
+	Description         string     `json:"description,omitempty"`
+	Active              bool       `json:"active"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+type CreateWebhookRequest struct {
+	UserID      int    `json:"user_id" binding:"omitempty,min=1"`
+	TenantID    string `json:"tenant_id" binding:"max=64"`
+	URL         string `json:"url" binding:"required,url,max=2048"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+type SendWebhookRequest struct {
+	UserID   int             `json:"user_id" binding:"omitempty,min=1"`
+	TenantID string          `json:"tenant_id" binding:"max=64"`
+	Event    string          `json:"event" binding:"required,max=100"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// WebhookDelivery is a signed webhook event queued for at-least-once delivery to one endpoint.
+type WebhookDelivery struct {
+	ID                  int64
+	EndpointID          int64
+	EventID             string // Stable across retries so receivers can de-duplicate
+	Payload             []byte // JSON webhook event, signed at send time
+	Attempts            int    // Attempts made, including the one in progress
+	URL                 string // Endpoint URL at the time of the attempt
+	Secret              string // Endpoint signing secret
+	Active              bool   // Whether the endpoint still accepts deliveries
+	ConsecutiveFailures int    // Failed deliveries of the endpoint since its last success
+}
+
+type WebhookResult struct {
+	EventID     string   `json:"event_id"`
+	EndpointIDs []string `json:"endpoint_ids"` // Endpoints the event was queued for
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// WebPushSubscriptionRepository persists browser Web Push subscriptions.
+type WebPushSubscriptionRepository interface {
+	Upsert(ctx context.Context, sub *WebPushSubscription) error
+	Delete(ctx context.Context, userID int, endpoint string) (bool, error)
+	DeleteByEndpoint(ctx context.Context, endpoint string) error
+	ListActiveByUserID(ctx context.Context, userID int) ([]WebPushSubscription, error)
+}
+
+type WebPushSubscription struct {
+	ID             int64     `json:"id,string"`
+	UserID         int       `json:"-"`
+	Endpoint       string    `json:"endpoint"`
+	P256dh         string    `json:"-"`
+	Auth           string    `json:"-"`
+	UserAgent      string    `json:"user_agent,omitempty"`
+	ExpirationTime *int64    `json:"expiration_time,omitempty"` // Unix milliseconds, as reported by the browser
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// PushSubscriptionRequest mirrors the browser's PushSubscription.toJSON() shape.
+type PushSubscriptionRequest struct {
+	Endpoint       string `json:"endpoint" binding:"required,url,startswith=https://,max=2048"`
+	ExpirationTime *int64 `json:"expirationTime"`
+	Keys           struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys" binding:"required"`
+}
+
+type UnsubscribeWebPushRequest struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+}
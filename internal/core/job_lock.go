@@ -0,0 +1,34 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// RunExclusive runs job unless another process is running the job of the same name, and
+// reports whether it ran. The lock is a transaction-level advisory lock, so it also
+// works behind transaction-mode poolers; the transaction holding it stays open while
+// job runs on other connections.
+func RunExclusive(ctx context.Context, name string, job func(ctx context.Context) error) (bool, error) {
+	db := GetPool()
+	if db == nil {
+		return false, errors.New("database connection not available")
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext('job:' || $1))`, name).Scan(&locked); err != nil {
+		return false, fmt.Errorf("lock job %s: %w", name, err)
+	}
+	if !locked {
+		return false, nil
+	}
+
+	return true, job(ctx)
+}
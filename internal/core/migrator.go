@@ -0,0 +1,492 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Migration states reported by Migrator.Status.
+const (
+	MigrationApplied = "applied"
+	MigrationPending = "pending"
+	MigrationFailed  = "failed"  // Recorded as failed by Flyway; repair the schema and the history row
+	MigrationChanged = "changed" // Script differs from the one applied (checksum mismatch)
+	MigrationMissing = "missing" // Applied, but no longer embedded
+)
+
+// historyTable is Flyway's schema history table, so the built-in runner and the Flyway
+// image can take turns on the same database.
+const historyTable = "flyway_schema_history"
+
+// migrationName matches Flyway's naming: V<version>__<description>.sql for migrations and
+// U<version>__<description>.sql for undo scripts. Version parts are separated by . or _.
+var migrationName = regexp.MustCompile(`^([VU])([0-9]+(?:[._][0-9]+)*)__(.+)\.sql$`)
+
+// Migration is a versioned SQL migration and, with Status, its state in the database.
+type Migration struct {
+	Version     string // Dotted version (e.g. 19 or 2.1)
+	Description string
+	Script      string
+	Checksum    int32 // Flyway's CRC32 of the script's lines
+
+	State       string
+	InstalledOn *time.Time
+
+	sql  string
+	undo *Migration
+}
+
+// Migrator applies the embedded migrations in version order and records them in Flyway's
+// history table. Each migration runs in its own transaction under an advisory lock, so
+// concurrent runners wait for each other and never apply a migration twice.
+type Migrator struct {
+	migrations []*Migration
+}
+
+// migrationHistory is the state of each version according to the history table.
+type migrationHistory struct {
+	entries  map[string]*historyEntry
+	baseline string // Versions up to the baseline count as applied
+}
+
+// historyEntry is the state of a version according to the history table.
+type historyEntry struct {
+	applied     bool
+	failed      bool
+	checksum    *int32
+	installedOn time.Time
+}
+
+// latest returns the highest applied version, or "" when none is.
+func (h *migrationHistory) latest() string {
+	latest := h.baseline
+	for version, entry := range h.entries {
+		if entry.applied && (latest == "" || compareVersions(version, latest) > 0) {
+			latest = version
+		}
+	}
+	return latest
+}
+
+// entry returns the state of version, if recorded or covered by the baseline.
+func (h *migrationHistory) entry(version string) (*historyEntry, bool) {
+	if entry, ok := h.entries[version]; ok {
+		return entry, true
+	}
+	if h.baseline != "" && compareVersions(version, h.baseline) <= 0 {
+		return &historyEntry{applied: true}, true
+	}
+	return nil, false
+}
+
+// state returns the state of migration according to the history.
+func (h *migrationHistory) state(migration *Migration) string {
+	entry, ok := h.entry(migration.Version)
+	if !ok {
+		return MigrationPending
+	}
+	return entry.state(migration)
+}
+
+// NewMigrator reads the migrations in fsys: sql/V*.sql and their optional undo/U*.sql.
+func NewMigrator(fsys fs.FS) (*Migrator, error) {
+	scripts, err := readMigrations(fsys, "sql", "V")
+	if err != nil {
+		return nil, err
+	}
+	undos, err := readMigrations(fsys, "undo", "U")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[string]*Migration, len(scripts))
+	for _, migration := range scripts {
+		if other, ok := byVersion[migration.Version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s have the same version", other.Script, migration.Script)
+		}
+		byVersion[migration.Version] = migration
+	}
+	for _, undo := range undos {
+		migration, ok := byVersion[undo.Version]
+		if !ok {
+			return nil, fmt.Errorf("undo script %s has no migration", undo.Script)
+		}
+		migration.undo = undo
+	}
+
+	slices.SortFunc(scripts, func(a, b *Migration) int { return compareVersions(a.Version, b.Version) })
+	return &Migrator{migrations: scripts}, nil
+}
+
+// readMigrations reads the scripts of dir whose names start with prefix.
+func readMigrations(fsys fs.FS, dir, prefix string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	var migrations []*Migration
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationName.FindStringSubmatch(entry.Name())
+		if match == nil || match[1] != prefix {
+			return nil, fmt.Errorf("migration %s/%s is not named %s<version>__<description>.sql", dir, entry.Name(), prefix)
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", entry.Name(), err)
+		}
+		migrations = append(migrations, &Migration{
+			Version:     normalizeVersion(match[2]),
+			Description: strings.ReplaceAll(match[3], "_", " "),
+			Script:      entry.Name(),
+			Checksum:    checksum(content),
+			sql:         string(content),
+		})
+	}
+	return migrations, nil
+}
+
+// Status returns every migration, embedded or recorded, with its state.
+func (m *Migrator) Status(ctx context.Context) ([]Migration, error) {
+	db := GetPool()
+	if db == nil {
+		return nil, errors.New("database connection not available")
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, historyTable).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("find migration history: %w", err)
+	}
+	history := &migrationHistory{entries: map[string]*historyEntry{}}
+	if exists {
+		if history, err = readHistory(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]Migration, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := *migration
+		status.State = MigrationPending
+		if entry, ok := history.entry(migration.Version); ok {
+			status.State = entry.state(migration)
+			if !entry.installedOn.IsZero() {
+				installedOn := entry.installedOn
+				status.InstalledOn = &installedOn
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	for version, entry := range history.entries {
+		if entry.applied && !slices.ContainsFunc(m.migrations, func(mg *Migration) bool { return mg.Version == version }) {
+			installedOn := entry.installedOn
+			statuses = append(statuses, Migration{Version: version, State: MigrationMissing, InstalledOn: &installedOn})
+		}
+	}
+	slices.SortFunc(statuses, func(a, b Migration) int { return compareVersions(a.Version, b.Version) })
+
+	return statuses, nil
+}
+
+// Up applies the pending migrations in version order and returns those it applied. It
+// stops at the first failure; the failed migration is rolled back and not recorded.
+// Pending migrations older than the latest applied one are refused unless outOfOrder is
+// set, like Flyway's outOfOrder setting.
+func (m *Migrator) Up(ctx context.Context, outOfOrder bool) ([]Migration, error) {
+	var applied []Migration
+	for {
+		var next *Migration
+		err := m.step(ctx, func(tx pgx.Tx, history *migrationHistory) error {
+			var err error
+			if next, err = m.next(history, outOfOrder); err != nil || next == nil {
+				return err
+			}
+			return apply(ctx, tx, next, "SQL")
+		})
+		if err != nil || next == nil {
+			return applied, err
+		}
+		applied = append(applied, *next)
+	}
+}
+
+// next returns the first pending migration, or nil when there is none. Pending
+// migrations older than the latest applied one were typically merged after it; they are
+// only returned with outOfOrder, as they may not expect the schema of later migrations.
+func (m *Migrator) next(history *migrationHistory, outOfOrder bool) (*Migration, error) {
+	latest := history.latest()
+	var next *Migration
+	var older []string
+	for _, migration := range m.migrations {
+		switch history.state(migration) {
+		case MigrationFailed:
+			return nil, fmt.Errorf("migration %s failed previously; repair it before migrating", migration.Script)
+		case MigrationChanged:
+			return nil, fmt.Errorf("migration %s changed since it was applied", migration.Script)
+		case MigrationPending:
+			if next == nil {
+				next = migration
+			}
+			if latest != "" && compareVersions(migration.Version, latest) < 0 {
+				older = append(older, "V"+migration.Version)
+			}
+		}
+	}
+	if len(older) > 0 && !outOfOrder {
+		return nil, fmt.Errorf("pending migrations %s are older than applied V%s; apply them out of order explicitly",
+			strings.Join(older, ", "), latest)
+	}
+	return next, nil
+}
+
+// Down undoes the latest steps applied migrations with their undo scripts and returns
+// those it undid. Migrations without an undo script cannot be undone; if any of the
+// steps has none, nothing is undone.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	err := m.step(ctx, func(_ pgx.Tx, history *migrationHistory) error {
+		_, err := m.undoPlan(history, steps)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var undone []Migration
+	for range steps {
+		var last *Migration
+		err := m.step(ctx, func(tx pgx.Tx, history *migrationHistory) error {
+			plan, err := m.undoPlan(history, 1)
+			if err != nil || len(plan) == 0 {
+				return err
+			}
+			last = plan[0]
+			return apply(ctx, tx, last.undo, "UNDO_SQL")
+		})
+		if err != nil || last == nil {
+			return undone, err
+		}
+		undone = append(undone, *last)
+	}
+	return undone, nil
+}
+
+// undoPlan returns the latest steps applied migrations, latest first. It fails if any of
+// them has no undo script, naming those and the versions that can be undone.
+func (m *Migrator) undoPlan(history *migrationHistory, steps int) ([]*Migration, error) {
+	var plan []*Migration
+	var missing []string
+	for _, migration := range slices.Backward(m.migrations) {
+		if len(plan) == steps {
+			break
+		}
+		if entry, ok := history.entry(migration.Version); ok && entry.applied {
+			plan = append(plan, migration)
+			if migration.undo == nil {
+				missing = append(missing, "V"+migration.Version)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		var undoable []string
+		for _, migration := range m.migrations {
+			if migration.undo != nil {
+				undoable = append(undoable, "V"+migration.Version)
+			}
+		}
+		return nil, fmt.Errorf("cannot undo %s: no undo script (migrations with one: %s)",
+			strings.Join(missing, ", "), strings.Join(undoable, ", "))
+	}
+	return plan, nil
+}
+
+// step runs fn in a transaction holding the migration lock, with the history as of
+// when the lock was taken.
+func (m *Migrator) step(ctx context.Context, fn func(tx pgx.Tx, history *migrationHistory) error) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('notification_migrations'))`); err != nil {
+		return fmt.Errorf("lock migrations: %w", err)
+	}
+	query := `CREATE TABLE IF NOT EXISTS ` + historyTable + ` (
+			installed_rank INT NOT NULL,
+			version VARCHAR(50),
+			description VARCHAR(200) NOT NULL,
+			type VARCHAR(20) NOT NULL,
+			script VARCHAR(1000) NOT NULL,
+			checksum INT,
+			installed_by VARCHAR(100) NOT NULL,
+			installed_on TIMESTAMP NOT NULL DEFAULT now(),
+			execution_time INT NOT NULL,
+			success BOOLEAN NOT NULL,
+			CONSTRAINT ` + historyTable + `_pk PRIMARY KEY (installed_rank)
+		);
+		CREATE INDEX IF NOT EXISTS ` + historyTable + `_s_idx ON ` + historyTable + ` (success)`
+	if _, err := tx.Exec(ctx, query); err != nil {
+		return fmt.Errorf("create migration history: %w", err)
+	}
+
+	history, err := readHistory(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx, history); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// apply runs a script and records it in the history as kind (SQL or UNDO_SQL).
+func apply(ctx context.Context, tx pgx.Tx, migration *Migration, kind string) error {
+	start := time.Now()
+	if _, err := tx.Exec(ctx, migration.sql); err != nil {
+		return fmt.Errorf("run migration %s: %w", migration.Script, err)
+	}
+
+	query := `INSERT INTO ` + historyTable + ` (installed_rank, version, description, type, script, checksum,
+			installed_by, execution_time, success)
+		VALUES ((SELECT COALESCE(MAX(installed_rank), 0) + 1 FROM ` + historyTable + `), $1, $2, $3, $4, $5,
+			current_user, $6, true)`
+	_, err := tx.Exec(ctx, query, migration.Version, migration.Description, kind, migration.Script,
+		migration.Checksum, time.Since(start).Milliseconds())
+	if err != nil {
+		return fmt.Errorf("record migration %s: %w", migration.Script, err)
+	}
+	return nil
+}
+
+// readHistory reads the history table. Later rows override earlier ones: an undo
+// unapplies its version.
+func readHistory(ctx context.Context, tx pgx.Tx) (*migrationHistory, error) {
+	query := `SELECT COALESCE(version, ''), type, checksum, installed_on, success
+		FROM ` + historyTable + ` ORDER BY installed_rank`
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query migration history: %w", err)
+	}
+	defer rows.Close()
+
+	history := &migrationHistory{entries: map[string]*historyEntry{}}
+	for rows.Next() {
+		var version, kind string
+		var entry historyEntry
+		var success bool
+		if err := rows.Scan(&version, &kind, &entry.checksum, &entry.installedOn, &success); err != nil {
+			return nil, fmt.Errorf("scan migration history: %w", err)
+		}
+		if version == "" {
+			continue // Schema creation and other unversioned rows
+		}
+		history.record(version, kind, success, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate migration history: %w", err)
+	}
+
+	return history, nil
+}
+
+// record adds a history row of kind to the history. Rows must be recorded in order of
+// installation: an undo unapplies its version and a new run replaces a failed one.
+func (h *migrationHistory) record(version, kind string, success bool, entry historyEntry) {
+	version = normalizeVersion(version)
+	switch kind {
+	case "BASELINE":
+		h.baseline = version
+	case "UNDO_SQL":
+		if success {
+			delete(h.entries, version)
+		}
+	default:
+		entry.applied = success
+		entry.failed = !success
+		h.entries[version] = &entry
+	}
+}
+
+// state returns the state of migration given its history entry.
+func (e *historyEntry) state(migration *Migration) string {
+	switch {
+	case e.failed:
+		return MigrationFailed
+	case !e.applied:
+		return MigrationPending
+	case e.checksum != nil && *e.checksum != migration.Checksum:
+		return MigrationChanged
+	default:
+		return MigrationApplied
+	}
+}
+
+// checksum is Flyway's checksum of a script: the CRC32 of its lines without line
+// terminators or byte order mark, so it does not depend on line endings.
+func checksum(content []byte) int32 {
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+	crc := crc32.NewIEEE()
+	for _, line := range bytes.FieldsFunc(content, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		_, _ = crc.Write(line)
+	}
+	return int32(crc.Sum32()) //nolint:gosec // Flyway stores the CRC as a signed int
+}
+
+// normalizeVersion returns a version with . separators and without leading zeros.
+func normalizeVersion(version string) string {
+	parts := strings.FieldsFunc(version, func(r rune) bool { return r == '.' || r == '_' })
+	for i, part := range parts {
+		if n, err := strconv.Atoi(part); err == nil {
+			parts[i] = strconv.Itoa(n)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// compareVersions orders dotted versions numerically, part by part.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range max(len(as), len(bs)) {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
@@ -0,0 +1,233 @@
+package database
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/duynhne/notification-service/db/migrations"
+)
+
+func TestChecksum(t *testing.T) {
+	script := func(name string) string {
+		content, err := fs.ReadFile(migrations.FS, "sql/"+name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+	tests := []struct {
+		name    string
+		content string
+		want    int32
+	}{
+		// The seed migrations are stored with CRLF line endings; Flyway's ChecksumCalculator
+		// reads them line by line, as Java's BufferedReader splits them
+		{"V1", script("V1__init_schema.sql"), -910373571},
+		{"V2", script("V2__seed_notifications.sql"), 1695708575},
+		{"empty", "", 0},
+		{"one line", "SELECT 1;", 78787420},
+		{"lf", "SELECT 1;\nSELECT 2;\n", -1665099012},
+		{"crlf", "SELECT 1;\r\nSELECT 2;\r\n", -1665099012},
+		{"cr", "SELECT 1;\rSELECT 2;", -1665099012},
+		{"blank lines", "SELECT 1;\n\n\r\nSELECT 2;\n\n", -1665099012},
+		{"byte order mark", "\ufeffSELECT 1;\nSELECT 2;", -1665099012},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checksum([]byte(tt.content)); got != tt.want {
+				t.Errorf("checksum = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int // Sign of the comparison
+	}{
+		{"1", "1", 0},
+		{"1", "2", -1},
+		{"2", "10", -1},
+		{"19", "9", 1},
+		{"1.1", "1", 1},
+		{"1.0", "1", 0},
+		{"2.9", "2.10", -1},
+		{"3", "2.99", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			got := compareVersions(tt.a, tt.b)
+			if sign := min(max(got, -1), 1); sign != tt.want {
+				t.Errorf("compareVersions = %d, want sign %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"19", "19"},
+		{"2_1", "2.1"},
+		{"2.1", "2.1"},
+		{"010", "10"},
+		{"1.02_3", "1.2.3"},
+	}
+	for _, tt := range tests {
+		if got := normalizeVersion(tt.version); got != tt.want {
+			t.Errorf("normalizeVersion(%q) = %q, want %q", tt.version, got, tt.want)
+		}
+	}
+}
+
+// historyRow is a row of the history table.
+type historyRow struct {
+	version  string
+	kind     string
+	success  bool
+	checksum *int32
+}
+
+func appliedRow(version string, checksum int32) historyRow {
+	return historyRow{version, "SQL", true, &checksum}
+}
+
+func newHistory(rows ...historyRow) *migrationHistory {
+	history := &migrationHistory{entries: map[string]*historyEntry{}}
+	for _, row := range rows {
+		history.record(row.version, row.kind, row.success, historyEntry{checksum: row.checksum})
+	}
+	return history
+}
+
+func TestHistoryState(t *testing.T) {
+	migration := &Migration{Version: "5", Script: "V5__test.sql", Checksum: 42}
+	tests := []struct {
+		name    string
+		history []historyRow
+		want    string
+	}{
+		{"not recorded", nil, MigrationPending},
+		{"applied", []historyRow{appliedRow("5", 42)}, MigrationApplied},
+		{"applied with padded version", []historyRow{appliedRow("05", 42)}, MigrationApplied},
+		{"applied without checksum", []historyRow{{"5", "SQL", true, nil}}, MigrationApplied},
+		{"changed", []historyRow{appliedRow("5", 7)}, MigrationChanged},
+		{"failed", []historyRow{{"5", "SQL", false, nil}}, MigrationFailed},
+		{"failed then applied", []historyRow{{"5", "SQL", false, nil}, appliedRow("5", 42)}, MigrationApplied},
+		{"undone", []historyRow{appliedRow("5", 42), {"5", "UNDO_SQL", true, nil}}, MigrationPending},
+		{"failed undo", []historyRow{appliedRow("5", 42), {"5", "UNDO_SQL", false, nil}}, MigrationApplied},
+		{"undone then reapplied", []historyRow{appliedRow("5", 42), {"5", "UNDO_SQL", true, nil}, appliedRow("5", 42)}, MigrationApplied},
+		{"baseline above", []historyRow{{"7", "BASELINE", true, nil}}, MigrationApplied},
+		{"baseline at", []historyRow{{"5", "BASELINE", true, nil}}, MigrationApplied},
+		{"baseline below", []historyRow{{"4", "BASELINE", true, nil}}, MigrationPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newHistory(tt.history...).state(migration); got != tt.want {
+				t.Errorf("state = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// testMigrator returns a migrator of versions 1 to 4; those listed in undoable have an
+// undo script.
+func testMigrator(undoable ...string) *Migrator {
+	m := &Migrator{}
+	for _, version := range []string{"1", "2", "3", "4"} {
+		migration := &Migration{Version: version, Script: "V" + version + "__test.sql", Checksum: 1}
+		for _, u := range undoable {
+			if u == version {
+				migration.undo = &Migration{Version: version, Script: "U" + version + "__test.sql"}
+			}
+		}
+		m.migrations = append(m.migrations, migration)
+	}
+	return m
+}
+
+func TestMigratorNext(t *testing.T) {
+	tests := []struct {
+		name       string
+		history    []historyRow
+		outOfOrder bool
+		want       string // Version of the next migration; "" for none
+		wantErr    string
+	}{
+		{"empty database", nil, false, "1", ""},
+		{"in order", []historyRow{appliedRow("1", 1), appliedRow("2", 1)}, false, "3", ""},
+		{"up to date", []historyRow{appliedRow("1", 1), appliedRow("2", 1), appliedRow("3", 1), appliedRow("4", 1)}, false, "", ""},
+		{"after baseline", []historyRow{{"2", "BASELINE", true, nil}}, false, "3", ""},
+		{"out of order", []historyRow{appliedRow("1", 1), appliedRow("3", 1)}, false, "", "pending migrations V2 are older than applied V3"},
+		{"out of order allowed", []historyRow{appliedRow("1", 1), appliedRow("3", 1)}, true, "2", ""},
+		{"behind a missing migration", []historyRow{appliedRow("1", 1), appliedRow("9", 1)}, false, "", "older than applied V9"},
+		{"changed", []historyRow{appliedRow("1", 2)}, false, "", "V1__test.sql changed"},
+		{"failed", []historyRow{appliedRow("1", 1), {"2", "SQL", false, nil}}, false, "", "V2__test.sql failed previously"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := testMigrator().next(newHistory(tt.history...), tt.outOfOrder)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("next error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("next: %v", err)
+			}
+			got := ""
+			if next != nil {
+				got = next.Version
+			}
+			if got != tt.want {
+				t.Errorf("next = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigratorUndoPlan(t *testing.T) {
+	all := []historyRow{appliedRow("1", 1), appliedRow("2", 1), appliedRow("3", 1), appliedRow("4", 1)}
+	tests := []struct {
+		name     string
+		undoable []string
+		history  []historyRow
+		steps    int
+		want     []string
+		wantErr  string
+	}{
+		{"latest", []string{"3", "4"}, all, 1, []string{"4"}, ""},
+		{"several", []string{"3", "4"}, all, 2, []string{"4", "3"}, ""},
+		{"beyond the first", []string{"1", "2", "3", "4"}, all, 9, []string{"4", "3", "2", "1"}, ""},
+		{"nothing applied", []string{"4"}, nil, 1, nil, ""},
+		{"skips undone", []string{"3", "4"}, append(all, historyRow{"4", "UNDO_SQL", true, nil}), 1, []string{"3"}, ""},
+		{"no undo script", []string{"3", "4"}, all, 3, nil, "cannot undo V2: no undo script (migrations with one: V3, V4)"},
+		{"several without", []string{"4"}, all, 3, nil, "cannot undo V3, V2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := testMigrator(tt.undoable...).undoPlan(newHistory(tt.history...), tt.steps)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("undoPlan error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("undoPlan: %v", err)
+			}
+			var got []string
+			for _, migration := range plan {
+				got = append(got, migration.Version)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("undoPlan = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+)
+
+// NotificationDeliveryRepository handles database operations for the queue of email,
+// SMS and push sends.
+type NotificationDeliveryRepository struct{}
+
+// NewNotificationDeliveryRepository creates a new NotificationDeliveryRepository.
+func NewNotificationDeliveryRepository() *NotificationDeliveryRepository {
+	return &NotificationDeliveryRepository{}
+}
+
+// Enqueue queues a send for immediate delivery.
+func (r *NotificationDeliveryRepository) Enqueue(ctx context.Context, delivery *domain.NotificationDelivery) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	var payload any // NULL rather than empty JSON
+	if len(delivery.Payload) > 0 {
+		payload = delivery.Payload
+	}
+	query := `INSERT INTO notification_deliveries (notification_id, notification_created_at, channel, priority, payload)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	err := db.QueryRow(ctx, query, delivery.Notification.ID, delivery.Notification.CreatedAt,
+		delivery.Channel, delivery.Priority, payload).Scan(&delivery.ID)
+	if err != nil {
+		return fmt.Errorf("insert notification delivery: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimDue leases up to limit sends whose next attempt is due, highest priority first,
+// like CallbackEventRepository.ClaimDue.
+func (r *NotificationDeliveryRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.NotificationDelivery, error) {
+	db := GetPool()
+	if db == nil {
+		return nil, errors.New("database connection not available")
+	}
+
+	query := `UPDATE notification_deliveries SET
+			attempts = attempts + 1,
+			next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM notification_deliveries
+			WHERE next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY CASE priority WHEN 'critical' THEN 0 WHEN 'high' THEN 1 WHEN 'normal' THEN 2 ELSE 3 END,
+				next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, notification_id, notification_created_at, channel, priority, payload, attempts`
+
+	rows, err := db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim notification deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []domain.NotificationDelivery
+	for rows.Next() {
+		var d domain.NotificationDelivery
+		if err := rows.Scan(&d.ID, &d.Notification.ID, &d.Notification.CreatedAt, &d.Channel, &d.Priority, &d.Payload, &d.Attempts); err != nil {
+			return nil, fmt.Errorf("scan notification delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notification deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Reschedule records a failed attempt and schedules the next one after retryAfter.
+func (r *NotificationDeliveryRepository) Reschedule(ctx context.Context, id int64, retryAfter time.Duration, lastError string) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	query := `UPDATE notification_deliveries SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2), last_error = $3
+		WHERE id = $1`
+	if _, err := db.Exec(ctx, query, id, retryAfter.Seconds(), lastError); err != nil {
+		return fmt.Errorf("reschedule notification delivery: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a send that reached a final status.
+func (r *NotificationDeliveryRepository) Delete(ctx context.Context, id int64) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	if _, err := db.Exec(ctx, `DELETE FROM notification_deliveries WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete notification delivery: %w", err)
+	}
+
+	return nil
+}
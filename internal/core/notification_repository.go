@@ -4,10 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -20,15 +20,20 @@ func NewNotificationRepository() *NotificationRepository {
 	return &NotificationRepository{}
 }
 
-// CountUnreadByUserID returns the count of unread notifications for a user.
-func (r *NotificationRepository) CountUnreadByUserID(ctx context.Context, userID int) (int, error) {
+// CountUnreadByUserID returns the count of unread notifications in a user's inbox, within
+// filter.ListWindow. Expired notifications are only counted when filter.IncludeExpired is set.
+func (r *NotificationRepository) CountUnreadByUserID(ctx context.Context, userID int, filter domain.NotificationFilter) (int, error) {
 	db := GetPool()
 	if db == nil {
 		return 0, errors.New("database connection not available")
 	}
 
 	var count int
-	err := db.QueryRow(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read = false`, userID).Scan(&count)
+	args := []any{userID, filter.IncludeExpired}
+	query := `SELECT COUNT(*) FROM notifications
+		WHERE user_id = $1 AND read = false AND deleted_at IS NULL AND archived_at IS NULL
+			AND ($2 OR expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)` + createdWithin(filter.ListWindow, &args)
+	err := db.QueryRow(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("count unread notifications: %w", err)
 	}
@@ -36,16 +41,113 @@ func (r *NotificationRepository) CountUnreadByUserID(ctx context.Context, userID
 	return count, nil
 }
 
-// Create inserts a new notification into the database.
+// createdWithin returns a condition limiting notifications to those created by now and,
+// unless window is 0, within window, appending its parameter to args. Bounding created_at
+// lets the planner skip the partitions created ahead and those of older months.
+func createdWithin(window time.Duration, args *[]any) string {
+	if window <= 0 {
+		return " AND created_at <= CURRENT_TIMESTAMP"
+	}
+	*args = append(*args, window.Seconds())
+	return fmt.Sprintf(" AND created_at <= CURRENT_TIMESTAMP AND created_at > CURRENT_TIMESTAMP - make_interval(secs => $%d::float8)", len(*args))
+}
+
+// publicIDClockSkew is how far a notification's created_at may be from the time in its
+// UUIDv7 public ID: the service generates the ID on its own clock before the database
+// stamps the row.
+const publicIDClockSkew = time.Hour
+
+// keyArgs returns the IDs and creation times of keys, the parameters of
+// "id = ANY($n) AND created_at = ANY($m)". IDs are unique, so the pairs match no other
+// notification, and the creation times limit the query to the partitions holding them.
+func keyArgs(keys []domain.NotificationKey) ([]int64, []time.Time) {
+	ids := make([]int64, 0, len(keys))
+	createdAt := make([]time.Time, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, key.ID)
+		createdAt = append(createdAt, key.CreatedAt)
+	}
+	return ids, createdAt
+}
+
+// notificationColumns selects a notification in the order of scanNotification.
+const notificationColumns = `id, public_id, user_id, title, message, type, read, read_at, created_at, updated_at,
+	status, COALESCE(status_reason, ''), COALESCE(recipient, ''), COALESCE(client_id, ''), COALESCE(callback_url, ''),
+	COALESCE(sms_encoding, ''), COALESCE(sms_segments, 0), priority, expires_at, archived_at, deleted_at,
+	metadata, COALESCE(action_url, ''), COALESCE(image_url, ''), COALESCE(icon_url, ''),
+	COALESCE(entity_type, ''), COALESCE(entity_id, ''), COALESCE(group_key, ''), COALESCE(collapse_key, ''),
+	actions, COALESCE(action_taken, ''), action_taken_at`
+
+// scanNotification scans a row of notificationColumns, followed by columns scanned into
+// extra. Times are returned in UTC.
+func scanNotification(row pgx.Row, n *domain.Notification, extra ...any) error {
+	c := &n.NotificationContext
+	dest := []any{&n.ID, &n.PublicID, &n.UserID, &n.Title, &n.Message, &n.Type, &n.Read, &n.ReadAt, &n.CreatedAt, &n.UpdatedAt,
+		&n.Status, &n.StatusReason, &n.Recipient, &n.ClientID, &n.CallbackURL,
+		&n.SMSEncoding, &n.SMSSegments, &n.Priority, &n.ExpiresAt, &n.ArchivedAt, &n.DeletedAt,
+		&c.Metadata, &c.ActionURL, &c.ImageURL, &c.IconURL,
+		&c.EntityType, &c.EntityID, &c.GroupKey, &c.CollapseKey,
+		&c.Actions, &n.ActionTaken, &n.ActionTakenAt}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return err
+	}
+
+	n.CreatedAt = n.CreatedAt.UTC()
+	n.UpdatedAt = n.UpdatedAt.UTC()
+	for _, t := range []*time.Time{n.ReadAt, n.ExpiresAt, n.ArchivedAt, n.DeletedAt, n.ActionTakenAt} {
+		if t != nil {
+			*t = t.UTC()
+		}
+	}
+	if n.Title == "" {
+		n.Title = n.Message
+	}
+	return nil
+}
+
+// Create inserts a new notification into the database. When the notification has a
+// collapse key, the user's unread notifications with the same key are replaced: they
+// are deleted without going to the trash.
 func (r *NotificationRepository) Create(ctx context.Context, notification *domain.Notification, userID int) error {
 	db := GetPool()
 	if db == nil {
 		return errors.New("database connection not available")
 	}
 
-	query := `INSERT INTO notifications (user_id, title, message, type, read) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
-	var id int
-	var createdAt time.Time
+	if notification.PublicID == uuid.Nil {
+		publicID, err := uuid.NewV7()
+		if err != nil {
+			return fmt.Errorf("generate notification id: %w", err)
+		}
+		notification.PublicID = publicID
+	}
+	status := notification.Status
+	if status == "" {
+		status = domain.StatusSent
+	}
+	priority := notification.Priority
+	if priority == "" {
+		priority = domain.PriorityNormal
+	}
+
+	// Both statements see the table as it was before the insert, so the new
+	// notification never replaces itself
+	query := `WITH inserted AS (
+			INSERT INTO notifications (public_id, user_id, title, message, type, read, status, recipient, client_id, callback_url,
+				sms_encoding, sms_segments, priority, expires_at,
+				metadata, action_url, image_url, icon_url, entity_type, entity_id, actions, group_key, collapse_key)
+			VALUES ($23::uuid, $1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, 0), $12,
+				CURRENT_TIMESTAMP + make_interval(secs => $13::float8),
+				$14, NULLIF($15, ''), NULLIF($16, ''), NULLIF($17, ''), NULLIF($18, ''), NULLIF($19, ''), $20,
+				NULLIF($21, ''), NULLIF($22, ''))
+			RETURNING id, created_at, updated_at, expires_at
+		), collapsed AS (
+			UPDATE notifications SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP,
+				replaced_by = (SELECT id FROM inserted)
+			WHERE user_id = $1 AND collapse_key = $22 AND read = false AND deleted_at IS NULL
+		)
+		SELECT id, created_at, updated_at, expires_at FROM inserted`
 
 	// Use title as message if not provided, or vice versa, to match existing logic
 	title := notification.Title
@@ -57,75 +159,147 @@ func (r *NotificationRepository) Create(ctx context.Context, notification *domai
 		message = title
 	}
 
-	err := db.QueryRow(ctx, query, userID, title, message, notification.Type, false).Scan(&id, &createdAt)
+	// Expiry is stored relative to the database clock, like suppression expiry
+	var expiresIn *float64
+	if notification.ExpiresAt != nil {
+		seconds := time.Until(*notification.ExpiresAt).Seconds()
+		expiresIn = &seconds
+	}
+	var metadata, actions any // NULL rather than empty JSON
+	if len(notification.Metadata) > 0 {
+		metadata = notification.Metadata
+	}
+	if len(notification.Actions) > 0 {
+		actions = notification.Actions
+	}
+
+	var id int64
+	var createdAt, updatedAt time.Time
+	var expiresAt *time.Time
+	err := db.QueryRow(ctx, query, userID, title, message, notification.Type, false,
+		status, notification.Recipient, notification.ClientID, notification.CallbackURL,
+		notification.SMSEncoding, notification.SMSSegments, priority, expiresIn,
+		metadata, notification.ActionURL, notification.ImageURL, notification.IconURL,
+		notification.EntityType, notification.EntityID, actions,
+		notification.GroupKey, notification.CollapseKey, notification.PublicID.String()).Scan(&id, &createdAt, &updatedAt, &expiresAt)
 	if err != nil {
 		return fmt.Errorf("insert notification: %w", err)
 	}
 
-	notification.ID = strconv.Itoa(id)
-	notification.CreatedAt = createdAt.Format(time.RFC3339)
+	notification.ID = id
+	notification.CreatedAt = createdAt.UTC()
+	notification.UpdatedAt = updatedAt.UTC()
+	if expiresAt != nil {
+		*expiresAt = expiresAt.UTC()
+		notification.ExpiresAt = expiresAt
+	}
 	notification.Read = false
-	notification.Status = "sent" // Default status
+	notification.Status = status
+	notification.Priority = priority
+	notification.UserID = userID
 
 	return nil
 }
 
-// FindByID retrieves a notification by its ID.
-func (r *NotificationRepository) FindByID(ctx context.Context, id int) (*domain.Notification, error) {
+// FindByID retrieves a notification by its key, unless it was deleted.
+func (r *NotificationRepository) FindByID(ctx context.Context, key domain.NotificationKey) (*domain.Notification, error) {
 	db := GetPool()
 	if db == nil {
 		return nil, errors.New("database connection not available")
 	}
 
-	query := `SELECT id, user_id, title, message, type, read, created_at FROM notifications WHERE id = $1`
-	var notificationID, userID int
-	var title, message, notifType *string
-	var read bool
-	var createdAt time.Time
-
-	err := db.QueryRow(ctx, query, id).Scan(&notificationID, &userID, &title, &message, &notifType, &read, &createdAt)
-	if err != nil {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE id = $1 AND created_at = $2 AND deleted_at IS NULL`
+	var notification domain.Notification
+	if err := scanNotification(db.QueryRow(ctx, query, key.ID, key.CreatedAt), &notification); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil // Return nil if not found, let caller handle specific error
 		}
 		return nil, fmt.Errorf("query notification: %w", err)
 	}
 
-	notification := &domain.Notification{
-		ID:        strconv.Itoa(notificationID),
-		Status:    "sent",
-		Read:      read,
-		CreatedAt: createdAt.Format(time.RFC3339),
+	return &notification, nil
+}
+
+// FindKeys returns the keys of the notifications with the given public IDs, deleted
+// ones included, so that Restore can reach the trash. Unknown public IDs are skipped.
+// Only the months around the times in the UUIDv7 public IDs are searched; other UUIDs
+// cannot name a notification.
+func (r *NotificationRepository) FindKeys(ctx context.Context, publicIDs []uuid.UUID) ([]domain.NotificationKey, error) {
+	db := GetPool()
+	if db == nil {
+		return nil, errors.New("database connection not available")
 	}
-	if title != nil {
-		notification.Title = *title
+
+	values := make([]string, 0, len(publicIDs))
+	var from, to time.Time
+	for _, publicID := range publicIDs {
+		if publicID.Version() != 7 {
+			continue
+		}
+		created := time.Unix(publicID.Time().UnixTime())
+		if len(values) == 0 || created.Before(from) {
+			from = created
+		}
+		if len(values) == 0 || created.After(to) {
+			to = created
+		}
+		values = append(values, publicID.String())
 	}
-	if message != nil {
-		notification.Message = *message
+	if len(values) == 0 {
+		return nil, nil
 	}
-	// Fallback/Backward compat logic
-	if notification.Title == "" && notification.Message != "" {
-		notification.Title = notification.Message
-	} else if notification.Message == "" && notification.Title != "" {
-		notification.Message = notification.Title
+
+	query := `SELECT id, created_at FROM notifications
+		WHERE public_id = ANY($1::uuid[]) AND created_at >= $2 AND created_at <= $3`
+	rows, err := db.Query(ctx, query, values, from.Add(-publicIDClockSkew), to.Add(publicIDClockSkew))
+	if err != nil {
+		return nil, fmt.Errorf("query notification keys: %w", err)
 	}
 
-	if notifType != nil {
-		notification.Type = *notifType
+	keys, err := pgx.CollectRows(rows, pgx.RowToStructByPos[domain.NotificationKey])
+	if err != nil {
+		return nil, fmt.Errorf("scan notification keys: %w", err)
 	}
 
-	return notification, nil
+	return keys, nil
 }
 
-// ListByUserID retrieves all notifications for a specific user.
-func (r *NotificationRepository) ListByUserID(ctx context.Context, userID int) ([]domain.Notification, error) {
+// FindLegacyKeys returns the keys of the notifications with the given internal IDs,
+// deleted ones included. Unknown IDs are skipped. Internal IDs say nothing about when a
+// notification was created, so every partition is searched.
+func (r *NotificationRepository) FindLegacyKeys(ctx context.Context, ids []int64) ([]domain.NotificationKey, error) {
 	db := GetPool()
 	if db == nil {
 		return nil, errors.New("database connection not available")
 	}
 
-	query := `SELECT id, user_id, title, message, type, read, created_at FROM notifications WHERE user_id = $1 ORDER BY created_at DESC`
-	rows, err := db.Query(ctx, query, userID)
+	rows, err := db.Query(ctx, `SELECT id, created_at FROM notifications WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("query notification keys: %w", err)
+	}
+
+	keys, err := pgx.CollectRows(rows, pgx.RowToStructByPos[domain.NotificationKey])
+	if err != nil {
+		return nil, fmt.Errorf("scan notification keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// ListByUserID retrieves the notifications of a user in the filter's folder, within
+// filter.ListWindow. Expired notifications are only included when filter.IncludeExpired is set.
+func (r *NotificationRepository) ListByUserID(ctx context.Context, userID int, filter domain.NotificationFilter) ([]domain.Notification, error) {
+	db := GetPool()
+	if db == nil {
+		return nil, errors.New("database connection not available")
+	}
+
+	condition, args := listCondition(userID, filter)
+	query := `SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE ` + condition + `
+		ORDER BY created_at DESC`
+	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query notifications: %w", err)
 	}
@@ -133,61 +307,306 @@ func (r *NotificationRepository) ListByUserID(ctx context.Context, userID int) (
 
 	var notifications []domain.Notification
 	for rows.Next() {
-		var notificationID, dbUserID int
-		var title, message, notifType *string
-		var read bool
-		var createdAt time.Time
-
-		err := rows.Scan(&notificationID, &dbUserID, &title, &message, &notifType, &read, &createdAt)
-		if err != nil {
+		var notification domain.Notification
+		if err := scanNotification(rows, &notification); err != nil {
 			return nil, fmt.Errorf("scan notification: %w", err)
 		}
+		notifications = append(notifications, notification)
+	}
 
-		notif := domain.Notification{
-			ID:        strconv.Itoa(notificationID),
-			Status:    "sent",
-			Read:      read,
-			CreatedAt: createdAt.Format(time.RFC3339),
-		}
-		if title != nil {
-			notif.Title = *title
-		}
-		if message != nil {
-			notif.Message = *message
-		}
-		// Fallback/Backward compat logic
-		if notif.Title == "" && notif.Message != "" {
-			notif.Title = notif.Message
-		} else if notif.Message == "" && notif.Title != "" {
-			notif.Message = notif.Title
-		}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notifications: %w", err)
+	}
 
-		if notifType != nil {
-			notif.Type = *notifType
-		}
+	return notifications, nil
+}
+
+// ListGroupsByUserID retrieves the notifications listed by ListByUserID folded into
+// groups by group key, each with its latest notification and counts, latest group first.
+// Notifications without a group key form a group of their own.
+func (r *NotificationRepository) ListGroupsByUserID(ctx context.Context, userID int, filter domain.NotificationFilter) ([]domain.NotificationGroup, error) {
+	db := GetPool()
+	if db == nil {
+		return nil, errors.New("database connection not available")
+	}
 
-		notifications = append(notifications, notif)
+	// Group keys cannot contain "/", so ungrouped notifications never share a group
+	condition, args := listCondition(userID, filter)
+	query := `SELECT ` + notificationColumns + `, group_count, group_unread
+		FROM (
+			SELECT DISTINCT ON (grp) *,
+				COUNT(*) OVER (PARTITION BY grp) AS group_count,
+				COUNT(*) FILTER (WHERE NOT read) OVER (PARTITION BY grp) AS group_unread
+			FROM (SELECT *, COALESCE(group_key, '/' || id) AS grp FROM notifications WHERE ` + condition + `) listed
+			ORDER BY grp, created_at DESC, id DESC
+		) latest
+		ORDER BY created_at DESC, id DESC`
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query notification groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []domain.NotificationGroup
+	for rows.Next() {
+		var group domain.NotificationGroup
+		if err := scanNotification(rows, &group.Latest, &group.Count, &group.UnreadCount); err != nil {
+			return nil, fmt.Errorf("scan notification group: %w", err)
+		}
+		group.GroupKey = group.Latest.GroupKey
+		groups = append(groups, group)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate notifications: %w", err)
+		return nil, fmt.Errorf("iterate notification groups: %w", err)
 	}
 
-	return notifications, nil
+	return groups, nil
+}
+
+// listCondition returns the condition selecting the notifications of a user's list and
+// its parameters.
+func listCondition(userID int, filter domain.NotificationFilter) (string, []any) {
+	args := []any{userID, filter.IncludeExpired}
+	var folder string
+	switch filter.Folder {
+	case domain.FolderArchive:
+		folder = `deleted_at IS NULL AND archived_at IS NOT NULL`
+	case domain.FolderTrash:
+		folder = `deleted_at > CURRENT_TIMESTAMP - make_interval(secs => $3::float8) AND replaced_by IS NULL`
+		args = append(args, filter.RestoreWindow.Seconds())
+	default:
+		folder = `deleted_at IS NULL AND archived_at IS NULL`
+	}
+
+	condition := `user_id = $1 AND ($2 OR expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP) AND ` + folder +
+		createdWithin(filter.ListWindow, &args)
+	return condition, args
 }
 
 // MarkAsRead marks a notification as read. Returns true if updated, false if not found.
-func (r *NotificationRepository) MarkAsRead(ctx context.Context, id int) (bool, error) {
+func (r *NotificationRepository) MarkAsRead(ctx context.Context, key domain.NotificationKey) (bool, error) {
 	db := GetPool()
 	if db == nil {
 		return false, errors.New("database connection not available")
 	}
 
-	query := `UPDATE notifications SET read = true WHERE id = $1`
-	result, err := db.Exec(ctx, query, id)
+	query := `UPDATE notifications SET read = true, read_at = COALESCE(read_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND created_at = $2 AND deleted_at IS NULL`
+	result, err := db.Exec(ctx, query, key.ID, key.CreatedAt)
 	if err != nil {
 		return false, fmt.Errorf("update notification: %w", err)
 	}
 
 	return result.RowsAffected() > 0, nil
 }
+
+// MarkGroupAsRead marks the unread notifications of a user's group as read. Returns the
+// number of notifications updated.
+func (r *NotificationRepository) MarkGroupAsRead(ctx context.Context, userID int, groupKey string) (int, error) {
+	db := GetPool()
+	if db == nil {
+		return 0, errors.New("database connection not available")
+	}
+
+	query := `UPDATE notifications SET read = true, read_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND group_key = $2 AND read = false AND deleted_at IS NULL`
+	result, err := db.Exec(ctx, query, userID, groupKey)
+	if err != nil {
+		return 0, fmt.Errorf("update notification group: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// RecordAction stores the action a user chose and marks the notification read. Only the
+// first action is recorded; returns false if the notification does not exist or an
+// action was already taken.
+func (r *NotificationRepository) RecordAction(ctx context.Context, key domain.NotificationKey, action string) (bool, error) {
+	db := GetPool()
+	if db == nil {
+		return false, errors.New("database connection not available")
+	}
+
+	query := `UPDATE notifications SET action_taken = $3, action_taken_at = CURRENT_TIMESTAMP,
+			read = true, read_at = COALESCE(read_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND created_at = $2 AND action_taken IS NULL AND deleted_at IS NULL`
+	result, err := db.Exec(ctx, query, key.ID, key.CreatedAt, action)
+	if err != nil {
+		return false, fmt.Errorf("record notification action: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// Archive moves notifications of a user to the archive. Archiving an archived
+// notification keeps its original time. Returns the number of notifications found.
+func (r *NotificationRepository) Archive(ctx context.Context, userID int, keys []domain.NotificationKey) (int, error) {
+	db := GetPool()
+	if db == nil {
+		return 0, errors.New("database connection not available")
+	}
+
+	query := `UPDATE notifications SET archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND id = ANY($2) AND created_at = ANY($3) AND deleted_at IS NULL`
+	ids, createdAt := keyArgs(keys)
+	result, err := db.Exec(ctx, query, userID, ids, createdAt)
+	if err != nil {
+		return 0, fmt.Errorf("archive notifications: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// SoftDelete moves notifications of a user to the trash. Returns the number deleted.
+func (r *NotificationRepository) SoftDelete(ctx context.Context, userID int, keys []domain.NotificationKey) (int, error) {
+	db := GetPool()
+	if db == nil {
+		return 0, errors.New("database connection not available")
+	}
+
+	query := `UPDATE notifications SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND id = ANY($2) AND created_at = ANY($3) AND deleted_at IS NULL`
+	ids, createdAt := keyArgs(keys)
+	result, err := db.Exec(ctx, query, userID, ids, createdAt)
+	if err != nil {
+		return 0, fmt.Errorf("delete notifications: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// Restore moves notifications of a user back to the inbox, from the archive or from the
+// trash if they were deleted less than window ago. Notifications replaced by a newer one
+// with the same collapse key cannot be restored. Returns the number of notifications found.
+func (r *NotificationRepository) Restore(ctx context.Context, userID int, keys []domain.NotificationKey, window time.Duration) (int, error) {
+	db := GetPool()
+	if db == nil {
+		return 0, errors.New("database connection not available")
+	}
+
+	query := `UPDATE notifications SET archived_at = NULL, deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND id = ANY($2) AND created_at = ANY($3) AND replaced_by IS NULL
+			AND (deleted_at IS NULL OR deleted_at > CURRENT_TIMESTAMP - make_interval(secs => $4::float8))`
+	ids, createdAt := keyArgs(keys)
+	result, err := db.Exec(ctx, query, userID, ids, createdAt, window.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("restore notifications: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// UpdateStatus moves a notification from one delivery status to another. The update only
+// applies if the current status is still from, so concurrent reports cannot skip a transition.
+// Returns false if the notification does not exist or its status has changed.
+func (r *NotificationRepository) UpdateStatus(ctx context.Context, key domain.NotificationKey, from, to, reason string) (bool, error) {
+	db := GetPool()
+	if db == nil {
+		return false, errors.New("database connection not available")
+	}
+
+	query := `UPDATE notifications SET status = $4, status_reason = NULLIF($5, ''),
+			status_updated_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND created_at = $2 AND status = $3 AND deleted_at IS NULL`
+	result, err := db.Exec(ctx, query, key.ID, key.CreatedAt, from, to, reason)
+	if err != nil {
+		return false, fmt.Errorf("update notification status: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// retentionCondition selects the notifications of a retention rule. Its parameters are
+// the rule's age in seconds, type, excluded types and read-only flag.
+const retentionCondition = `created_at < CURRENT_TIMESTAMP - make_interval(secs => $1::float8)
+	AND (type = $2 OR $2 = '') AND COALESCE(type, '') <> ALL($3) AND (read OR NOT $4)`
+
+// retentionArgs returns the parameters of retentionCondition.
+func retentionArgs(rule domain.RetentionRule, excludeTypes []string) []any {
+	if excludeTypes == nil {
+		excludeTypes = []string{} // NULL would exclude every row
+	}
+	return []any{rule.MaxAge.Seconds(), rule.Type, excludeTypes, rule.ReadOnly}
+}
+
+// purgeQuery wraps a DELETE of notifications RETURNING their id so that the delivered
+// and dead callback events of the deleted notifications go with them, and counts the
+// notifications deleted. Pending events are still sent and purged by age later.
+func purgeQuery(deleteNotifications string) string {
+	return `WITH purged AS (` + deleteNotifications + `),
+		events AS (DELETE FROM callback_events WHERE notification_id IN (SELECT id FROM purged) AND status <> 'pending')
+		SELECT COUNT(*) FROM purged`
+}
+
+// DeleteOlderThan permanently deletes up to limit notifications selected by a retention
+// rule, with their finished callback events. Rows locked by other transactions are
+// skipped. Returns the number of notifications deleted.
+func (r *NotificationRepository) DeleteOlderThan(ctx context.Context, rule domain.RetentionRule, excludeTypes []string, limit int) (int, error) {
+	db := GetPool()
+	if db == nil {
+		return 0, errors.New("database connection not available")
+	}
+
+	query := purgeQuery(`DELETE FROM notifications WHERE (id, created_at) IN (
+		SELECT id, created_at FROM notifications WHERE ` + retentionCondition + `
+		ORDER BY id LIMIT $5 FOR UPDATE SKIP LOCKED) RETURNING id`)
+	var deleted int
+	if err := db.QueryRow(ctx, query, append(retentionArgs(rule, excludeTypes), limit)...).Scan(&deleted); err != nil {
+		return 0, fmt.Errorf("purge notifications: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// CountOlderThan returns the number of notifications selected by a retention rule.
+func (r *NotificationRepository) CountOlderThan(ctx context.Context, rule domain.RetentionRule, excludeTypes []string) (int, error) {
+	db := GetPool()
+	if db == nil {
+		return 0, errors.New("database connection not available")
+	}
+
+	var count int
+	query := `SELECT COUNT(*) FROM notifications WHERE ` + retentionCondition
+	if err := db.QueryRow(ctx, query, retentionArgs(rule, excludeTypes)...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count notifications to purge: %w", err)
+	}
+
+	return count, nil
+}
+
+// DeleteTrashed permanently deletes up to limit notifications deleted by their user more
+// than deletedFor ago, with their finished callback events. Returns the number of
+// notifications deleted.
+func (r *NotificationRepository) DeleteTrashed(ctx context.Context, deletedFor time.Duration, limit int) (int, error) {
+	db := GetPool()
+	if db == nil {
+		return 0, errors.New("database connection not available")
+	}
+
+	query := purgeQuery(`DELETE FROM notifications WHERE (id, created_at) IN (
+		SELECT id, created_at FROM notifications WHERE deleted_at < CURRENT_TIMESTAMP - make_interval(secs => $1::float8)
+		ORDER BY id LIMIT $2 FOR UPDATE SKIP LOCKED) RETURNING id`)
+	var deleted int
+	if err := db.QueryRow(ctx, query, deletedFor.Seconds(), limit).Scan(&deleted); err != nil {
+		return 0, fmt.Errorf("purge deleted notifications: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// CountTrashed returns the number of notifications deleted more than deletedFor ago.
+func (r *NotificationRepository) CountTrashed(ctx context.Context, deletedFor time.Duration) (int, error) {
+	db := GetPool()
+	if db == nil {
+		return 0, errors.New("database connection not available")
+	}
+
+	var count int
+	query := `SELECT COUNT(*) FROM notifications WHERE deleted_at < CURRENT_TIMESTAMP - make_interval(secs => $1::float8)`
+	if err := db.QueryRow(ctx, query, deletedFor.Seconds()).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count deleted notifications to purge: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// partitionLockTimeout bounds how long partition DDL waits for the table lock, so
+// maintenance gives way to busy traffic and retries on its next run.
+const partitionLockTimeout = "5s"
+
+// NotificationPartitionRepository maintains the monthly partitions of notifications
+// through the functions of migrations V18 and V19.
+type NotificationPartitionRepository struct{}
+
+// NewNotificationPartitionRepository creates a new NotificationPartitionRepository.
+func NewNotificationPartitionRepository() *NotificationPartitionRepository {
+	return &NotificationPartitionRepository{}
+}
+
+// CreateAhead creates the missing partitions of the current month through monthsAhead
+// months later and returns how many it created.
+func (r *NotificationPartitionRepository) CreateAhead(ctx context.Context, monthsAhead int) (int, error) {
+	var created int
+	err := r.maintain(ctx, func(tx pgx.Tx) error {
+		query := `SELECT create_notification_partitions((CURRENT_TIMESTAMP AT TIME ZONE 'UTC')::date,
+			((CURRENT_TIMESTAMP AT TIME ZONE 'UTC') + make_interval(months => $1))::date)`
+		return tx.QueryRow(ctx, query, monthsAhead).Scan(&created)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("create notification partitions: %w", err)
+	}
+
+	return created, nil
+}
+
+// DetachOlderThan detaches the partitions that ended more than months months ago and
+// returns the names they are kept under, notifications_pYYYY_MM_detached.
+func (r *NotificationPartitionRepository) DetachOlderThan(ctx context.Context, months int) ([]string, error) {
+	var detached []string
+	err := r.maintain(ctx, func(tx pgx.Tx) error {
+		query := `SELECT detach_notification_partitions(
+			(date_trunc('month', CURRENT_TIMESTAMP AT TIME ZONE 'UTC') - make_interval(months => $1))::date)`
+		rows, err := tx.Query(ctx, query, months)
+		if err != nil {
+			return err
+		}
+		detached, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("detach notification partitions: %w", err)
+	}
+
+	return detached, nil
+}
+
+// maintain runs partition DDL in a transaction that replicas take turns on.
+func (r *NotificationPartitionRepository) maintain(ctx context.Context, ddl func(tx pgx.Tx) error) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('notification_partitions'))`); err != nil {
+		return fmt.Errorf("lock partition maintenance: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `SET LOCAL lock_timeout = '`+partitionLockTimeout+`'`); err != nil {
+		return fmt.Errorf("set lock timeout: %w", err)
+	}
+	if err := ddl(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PreferenceRepository handles database operations for user notification preferences.
+type PreferenceRepository struct{}
+
+// NewPreferenceRepository creates a new PreferenceRepository.
+func NewPreferenceRepository() *PreferenceRepository {
+	return &PreferenceRepository{}
+}
+
+// Set stores whether the user receives a category on a channel.
+func (r *PreferenceRepository) Set(ctx context.Context, userID int, channel, category string, enabled bool) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	query := `INSERT INTO notification_preferences (user_id, channel, category, enabled)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, channel, category) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			updated_at = CURRENT_TIMESTAMP`
+	if _, err := db.Exec(ctx, query, userID, channel, category, enabled); err != nil {
+		return fmt.Errorf("upsert notification preference: %w", err)
+	}
+
+	return nil
+}
+
+// IsEnabled reports whether the user receives a category on a channel (true if unset).
+func (r *PreferenceRepository) IsEnabled(ctx context.Context, userID int, channel, category string) (bool, error) {
+	db := GetPool()
+	if db == nil {
+		return false, errors.New("database connection not available")
+	}
+
+	query := `SELECT enabled FROM notification_preferences WHERE user_id = $1 AND channel = $2 AND category = $3`
+	var enabled bool
+	if err := db.QueryRow(ctx, query, userID, channel, category).Scan(&enabled); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return true, nil
+		}
+		return false, fmt.Errorf("query notification preference: %w", err)
+	}
+
+	return enabled, nil
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ProviderSlotRepository handles the shared send schedule of rate-limited providers.
+// Times are taken from the database clock so replica clock skew does not matter.
+type ProviderSlotRepository struct{}
+
+// NewProviderSlotRepository creates a new ProviderSlotRepository.
+func NewProviderSlotRepository() *ProviderSlotRepository {
+	return &ProviderSlotRepository{}
+}
+
+// Reserve books the provider's next free slot and returns how long until it starts.
+func (r *ProviderSlotRepository) Reserve(ctx context.Context, provider string, interval time.Duration) (time.Duration, error) {
+	db := GetPool()
+	if db == nil {
+		return 0, errors.New("database connection not available")
+	}
+
+	query := `INSERT INTO provider_send_slots (provider, next_slot)
+		VALUES ($1, clock_timestamp() + make_interval(secs => $2::float8))
+		ON CONFLICT (provider) DO UPDATE SET
+			next_slot = GREATEST(provider_send_slots.next_slot, clock_timestamp()) + make_interval(secs => $2::float8)
+		RETURNING EXTRACT(EPOCH FROM provider_send_slots.next_slot - clock_timestamp())::float8 - $2::float8`
+	var wait float64
+	if err := db.QueryRow(ctx, query, provider, interval.Seconds()).Scan(&wait); err != nil {
+		return 0, fmt.Errorf("reserve provider send slot: %w", err)
+	}
+
+	return max(time.Duration(wait*float64(time.Second)), 0), nil
+}
+
+// Pause moves the provider's next free slot to at least d from now.
+func (r *ProviderSlotRepository) Pause(ctx context.Context, provider string, d time.Duration) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	query := `INSERT INTO provider_send_slots (provider, next_slot)
+		VALUES ($1, clock_timestamp() + make_interval(secs => $2::float8))
+		ON CONFLICT (provider) DO UPDATE SET
+			next_slot = GREATEST(provider_send_slots.next_slot, EXCLUDED.next_slot)`
+	if _, err := db.Exec(ctx, query, provider, d.Seconds()); err != nil {
+		return fmt.Errorf("pause provider send slots: %w", err)
+	}
+
+	return nil
+}
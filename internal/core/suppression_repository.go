@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SuppressionRepository handles database operations for the email suppression list.
+type SuppressionRepository struct{}
+
+// NewSuppressionRepository creates a new SuppressionRepository.
+func NewSuppressionRepository() *SuppressionRepository {
+	return &SuppressionRepository{}
+}
+
+const suppressionColumns = `address, reason, source, detail, notification_id, expires_at, created_at, updated_at`
+
+// Upsert suppresses an address for ttl (0 = permanently). A permanent suppression is
+// never downgraded by a later temporary one, and a temporary one is only ever extended.
+func (r *SuppressionRepository) Upsert(ctx context.Context, suppression *domain.Suppression, ttl time.Duration) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	// Only IDs this service could have issued are kept
+	var notificationID *string
+	if _, err := uuid.Parse(suppression.NotificationID); err == nil {
+		notificationID = &suppression.NotificationID
+	} else if _, err := strconv.ParseInt(suppression.NotificationID, 10, 64); err == nil {
+		notificationID = &suppression.NotificationID
+	}
+
+	query := `INSERT INTO suppressions (address, reason, source, detail, notification_id, expires_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5,
+			CASE WHEN $6::float8 > 0 THEN CURRENT_TIMESTAMP + make_interval(secs => $6::float8) END)
+		ON CONFLICT (address) DO UPDATE SET
+			reason = CASE WHEN suppressions.expires_at IS NULL THEN suppressions.reason ELSE EXCLUDED.reason END,
+			source = CASE WHEN suppressions.expires_at IS NULL THEN suppressions.source ELSE EXCLUDED.source END,
+			detail = CASE WHEN suppressions.expires_at IS NULL THEN suppressions.detail ELSE EXCLUDED.detail END,
+			notification_id = CASE WHEN suppressions.expires_at IS NULL
+				THEN suppressions.notification_id ELSE EXCLUDED.notification_id END,
+			expires_at = CASE WHEN suppressions.expires_at IS NULL OR EXCLUDED.expires_at IS NULL THEN NULL
+				ELSE GREATEST(suppressions.expires_at, EXCLUDED.expires_at) END,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING ` + suppressionColumns
+
+	row := db.QueryRow(ctx, query, suppression.Address, suppression.Reason, suppression.Source,
+		suppression.Detail, notificationID, ttl.Seconds())
+	if err := scanSuppression(row, suppression); err != nil {
+		return fmt.Errorf("upsert suppression: %w", err)
+	}
+
+	return nil
+}
+
+// FindActive returns the address's suppression if it has not expired, or nil.
+func (r *SuppressionRepository) FindActive(ctx context.Context, address string) (*domain.Suppression, error) {
+	db := GetPool()
+	if db == nil {
+		return nil, errors.New("database connection not available")
+	}
+
+	query := `SELECT ` + suppressionColumns + ` FROM suppressions
+		WHERE address = $1 AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)`
+
+	var suppression domain.Suppression
+	if err := scanSuppression(db.QueryRow(ctx, query, address), &suppression); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query suppression: %w", err)
+	}
+
+	return &suppression, nil
+}
+
+// Delete removes an address from the suppression list. Returns false if it was not suppressed.
+func (r *SuppressionRepository) Delete(ctx context.Context, address string) (bool, error) {
+	db := GetPool()
+	if db == nil {
+		return false, errors.New("database connection not available")
+	}
+
+	result, err := db.Exec(ctx, `DELETE FROM suppressions WHERE address = $1`, address)
+	if err != nil {
+		return false, fmt.Errorf("delete suppression: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+func scanSuppression(row pgx.Row, suppression *domain.Suppression) error {
+	var detail *string
+	var notificationID *string
+
+	err := row.Scan(&suppression.Address, &suppression.Reason, &suppression.Source, &detail,
+		&notificationID, &suppression.ExpiresAt, &suppression.CreatedAt, &suppression.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	suppression.Detail = ""
+	if detail != nil {
+		suppression.Detail = *detail
+	}
+	suppression.NotificationID = ""
+	if notificationID != nil {
+		suppression.NotificationID = *notificationID
+	}
+	if suppression.ExpiresAt != nil {
+		*suppression.ExpiresAt = suppression.ExpiresAt.UTC()
+	}
+	suppression.CreatedAt = suppression.CreatedAt.UTC()
+	suppression.UpdatedAt = suppression.UpdatedAt.UTC()
+	return nil
+}
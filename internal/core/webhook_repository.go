@@ -0,0 +1,322 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// WebhookEndpointRepository handles database operations for partner webhook endpoints.
+type WebhookEndpointRepository struct{}
+
+// NewWebhookEndpointRepository creates a new WebhookEndpointRepository.
+func NewWebhookEndpointRepository() *WebhookEndpointRepository {
+	return &WebhookEndpointRepository{}
+}
+
+const webhookEndpointColumns = `id, user_id, tenant_id, url, secret, description, active,
+	consecutive_failures, disabled_at, created_at`
+
+// Create inserts a new webhook endpoint.
+func (r *WebhookEndpointRepository) Create(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	query := `INSERT INTO webhook_endpoints (user_id, tenant_id, url, secret, description)
+		VALUES (NULLIF($1, 0), NULLIF($2, ''), $3, $4, NULLIF($5, ''))
+		RETURNING ` + webhookEndpointColumns
+
+	row := db.QueryRow(ctx, query, endpoint.UserID, endpoint.TenantID, endpoint.URL, endpoint.Secret, endpoint.Description)
+	if err := scanWebhookEndpoint(row, endpoint); err != nil {
+		return fmt.Errorf("insert webhook endpoint: %w", err)
+	}
+
+	return nil
+}
+
+// ListByOwner returns the endpoints of a user or tenant.
+func (r *WebhookEndpointRepository) ListByOwner(
+	ctx context.Context,
+	owner domain.WebhookOwner,
+	activeOnly bool,
+) ([]domain.WebhookEndpoint, error) {
+	db := GetPool()
+	if db == nil {
+		return nil, errors.New("database connection not available")
+	}
+
+	query := `SELECT ` + webhookEndpointColumns + ` FROM webhook_endpoints
+		WHERE (user_id = NULLIF($1, 0) OR tenant_id = NULLIF($2, ''))
+		AND (active = true OR NOT $3)
+		ORDER BY id`
+	rows, err := db.Query(ctx, query, owner.UserID, owner.TenantID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("query webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []domain.WebhookEndpoint
+	for rows.Next() {
+		var endpoint domain.WebhookEndpoint
+		if err := scanWebhookEndpoint(rows, &endpoint); err != nil {
+			return nil, fmt.Errorf("scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate webhook endpoints: %w", err)
+	}
+
+	return endpoints, nil
+}
+
+// Delete removes a webhook endpoint. Returns false if it does not exist.
+func (r *WebhookEndpointRepository) Delete(ctx context.Context, id int64) (bool, error) {
+	db := GetPool()
+	if db == nil {
+		return false, errors.New("database connection not available")
+	}
+
+	result, err := db.Exec(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("delete webhook endpoint: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// RecordSuccess resets the consecutive failure counter after a successful delivery.
+func (r *WebhookEndpointRepository) RecordSuccess(ctx context.Context, id int64) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	query := `UPDATE webhook_endpoints SET consecutive_failures = 0 WHERE id = $1 AND consecutive_failures <> 0`
+	if _, err := db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("record webhook success: %w", err)
+	}
+
+	return nil
+}
+
+// RecordFailure increments the consecutive failure counter and disables the endpoint once it
+// reaches disableAfter. Returns true if this failure disabled the endpoint.
+func (r *WebhookEndpointRepository) RecordFailure(ctx context.Context, id int64, disableAfter int) (bool, error) {
+	db := GetPool()
+	if db == nil {
+		return false, errors.New("database connection not available")
+	}
+
+	query := `UPDATE webhook_endpoints SET
+			consecutive_failures = consecutive_failures + 1,
+			active = CASE WHEN consecutive_failures + 1 >= $2 THEN false ELSE active END,
+			disabled_at = CASE WHEN consecutive_failures + 1 >= $2 AND active THEN CURRENT_TIMESTAMP ELSE disabled_at END
+		WHERE id = $1
+		RETURNING active`
+
+	var active bool
+	if err := db.QueryRow(ctx, query, id, disableAfter).Scan(&active); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("record webhook failure: %w", err)
+	}
+
+	return !active, nil
+}
+
+// WebhookDeliveryRepository handles database operations for the webhook outbox.
+type WebhookDeliveryRepository struct{}
+
+// NewWebhookDeliveryRepository creates a new WebhookDeliveryRepository.
+func NewWebhookDeliveryRepository() *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{}
+}
+
+// Enqueue stores an event for immediate delivery to each of the endpoints.
+func (r *WebhookDeliveryRepository) Enqueue(ctx context.Context, eventID string, payload []byte, endpointIDs []int64) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	query := `INSERT INTO webhook_deliveries (endpoint_id, event_id, payload)
+		SELECT endpoint_id, $2, $3 FROM unnest($1::bigint[]) AS endpoint_id`
+	if _, err := db.Exec(ctx, query, endpointIDs, eventID, payload); err != nil {
+		return fmt.Errorf("insert webhook deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimDue leases up to limit pending deliveries whose next attempt is due, like
+// CallbackEventRepository.ClaimDue. Each is returned with its endpoint's current URL,
+// secret and state.
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	db := GetPool()
+	if db == nil {
+		return nil, errors.New("database connection not available")
+	}
+
+	query := `WITH claimed AS (
+			UPDATE webhook_deliveries SET
+				attempts = attempts + 1,
+				next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, endpoint_id, event_id, payload, attempts
+		)
+		SELECT c.id, c.endpoint_id, c.event_id, c.payload, c.attempts,
+			e.url, e.secret, e.active, e.consecutive_failures
+		FROM claimed c JOIN webhook_endpoints e ON e.id = c.endpoint_id
+		ORDER BY c.id`
+
+	rows, err := db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []domain.WebhookDelivery
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		err := rows.Scan(&d.ID, &d.EndpointID, &d.EventID, &d.Payload, &d.Attempts,
+			&d.URL, &d.Secret, &d.Active, &d.ConsecutiveFailures)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// MarkDelivered records a successful delivery.
+func (r *WebhookDeliveryRepository) MarkDelivered(ctx context.Context, id int64) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	query := `UPDATE webhook_deliveries SET status = 'delivered', delivered_at = CURRENT_TIMESTAMP, last_error = NULL WHERE id = $1`
+	if _, err := db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("mark webhook delivery delivered: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed attempt and schedules the next one after retryAfter.
+func (r *WebhookDeliveryRepository) MarkFailed(ctx context.Context, id int64, retryAfter time.Duration, lastError string) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	query := `UPDATE webhook_deliveries SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2), last_error = $3
+		WHERE id = $1`
+	if _, err := db.Exec(ctx, query, id, retryAfter.Seconds(), lastError); err != nil {
+		return fmt.Errorf("mark webhook delivery failed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkDead stops retrying a delivery after its last failed attempt.
+func (r *WebhookDeliveryRepository) MarkDead(ctx context.Context, id int64, lastError string) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	query := `UPDATE webhook_deliveries SET status = 'dead', last_error = $2 WHERE id = $1`
+	if _, err := db.Exec(ctx, query, id, lastError); err != nil {
+		return fmt.Errorf("mark webhook delivery dead: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFinished permanently deletes up to limit delivered or dead deliveries created more
+// than olderThan ago. Returns the number deleted.
+func (r *WebhookDeliveryRepository) DeleteFinished(ctx context.Context, olderThan time.Duration, limit int) (int, error) {
+	db := GetPool()
+	if db == nil {
+		return 0, errors.New("database connection not available")
+	}
+
+	query := `DELETE FROM webhook_deliveries WHERE id IN (
+		SELECT id FROM webhook_deliveries
+		WHERE status <> 'pending' AND created_at < CURRENT_TIMESTAMP - make_interval(secs => $1::float8)
+		ORDER BY created_at LIMIT $2 FOR UPDATE SKIP LOCKED)`
+	result, err := db.Exec(ctx, query, olderThan.Seconds(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("purge webhook deliveries: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// CountFinished returns the number of delivered or dead deliveries created more than
+// olderThan ago.
+func (r *WebhookDeliveryRepository) CountFinished(ctx context.Context, olderThan time.Duration) (int, error) {
+	db := GetPool()
+	if db == nil {
+		return 0, errors.New("database connection not available")
+	}
+
+	var count int
+	query := `SELECT COUNT(*) FROM webhook_deliveries
+		WHERE status <> 'pending' AND created_at < CURRENT_TIMESTAMP - make_interval(secs => $1::float8)`
+	if err := db.QueryRow(ctx, query, olderThan.Seconds()).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count webhook deliveries to purge: %w", err)
+	}
+
+	return count, nil
+}
+
+func scanWebhookEndpoint(row pgx.Row, endpoint *domain.WebhookEndpoint) error {
+	var userID *int
+	var tenantID, description *string
+
+	err := row.Scan(&endpoint.ID, &userID, &tenantID, &endpoint.URL, &endpoint.Secret, &description,
+		&endpoint.Active, &endpoint.ConsecutiveFailures, &endpoint.DisabledAt, &endpoint.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	endpoint.UserID = 0
+	if userID != nil {
+		endpoint.UserID = *userID
+	}
+	endpoint.TenantID = ""
+	if tenantID != nil {
+		endpoint.TenantID = *tenantID
+	}
+	endpoint.Description = ""
+	if description != nil {
+		endpoint.Description = *description
+	}
+	if endpoint.DisabledAt != nil {
+		*endpoint.DisabledAt = endpoint.DisabledAt.UTC()
+	}
+	endpoint.CreatedAt = endpoint.CreatedAt.UTC()
+	return nil
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// WebPushSubscriptionRepository handles database operations for browser push subscriptions.
+type WebPushSubscriptionRepository struct{}
+
+// NewWebPushSubscriptionRepository creates a new WebPushSubscriptionRepository.
+func NewWebPushSubscriptionRepository() *WebPushSubscriptionRepository {
+	return &WebPushSubscriptionRepository{}
+}
+
+const webPushSubscriptionColumns = `id, user_id, endpoint, p256dh, auth, user_agent, expiration_time, created_at`
+
+// Upsert stores a subscription. Browsers re-subscribe with the same endpoint after
+// key rotation, so an existing endpoint is updated in place.
+func (r *WebPushSubscriptionRepository) Upsert(ctx context.Context, sub *domain.WebPushSubscription) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	var expiration *time.Time
+	if sub.ExpirationTime != nil {
+		t := time.UnixMilli(*sub.ExpirationTime).UTC()
+		expiration = &t
+	}
+
+	query := `INSERT INTO web_push_subscriptions (user_id, endpoint, p256dh, auth, user_agent, expiration_time)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+		ON CONFLICT (endpoint) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			p256dh = EXCLUDED.p256dh,
+			auth = EXCLUDED.auth,
+			user_agent = EXCLUDED.user_agent,
+			expiration_time = EXCLUDED.expiration_time
+		RETURNING ` + webPushSubscriptionColumns
+
+	row := db.QueryRow(ctx, query, sub.UserID, sub.Endpoint, sub.P256dh, sub.Auth, sub.UserAgent, expiration)
+	if err := scanWebPushSubscription(row, sub); err != nil {
+		return fmt.Errorf("upsert web push subscription: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a user's subscription. Returns false if it does not belong to the user.
+func (r *WebPushSubscriptionRepository) Delete(ctx context.Context, userID int, endpoint string) (bool, error) {
+	db := GetPool()
+	if db == nil {
+		return false, errors.New("database connection not available")
+	}
+
+	result, err := db.Exec(ctx, `DELETE FROM web_push_subscriptions WHERE user_id = $1 AND endpoint = $2`, userID, endpoint)
+	if err != nil {
+		return false, fmt.Errorf("delete web push subscription: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// DeleteByEndpoint removes a subscription the push service reported as expired.
+func (r *WebPushSubscriptionRepository) DeleteByEndpoint(ctx context.Context, endpoint string) error {
+	db := GetPool()
+	if db == nil {
+		return errors.New("database connection not available")
+	}
+
+	if _, err := db.Exec(ctx, `DELETE FROM web_push_subscriptions WHERE endpoint = $1`, endpoint); err != nil {
+		return fmt.Errorf("delete expired web push subscription: %w", err)
+	}
+
+	return nil
+}
+
+// ListActiveByUserID returns the user's subscriptions that have not passed their expiration time.
+func (r *WebPushSubscriptionRepository) ListActiveByUserID(ctx context.Context, userID int) ([]domain.WebPushSubscription, error) {
+	db := GetPool()
+	if db == nil {
+		return nil, errors.New("database connection not available")
+	}
+
+	query := `SELECT ` + webPushSubscriptionColumns + ` FROM web_push_subscriptions
+		WHERE user_id = $1 AND (expiration_time IS NULL OR expiration_time > CURRENT_TIMESTAMP)
+		ORDER BY created_at DESC`
+	rows, err := db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query web push subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []domain.WebPushSubscription
+	for rows.Next() {
+		var sub domain.WebPushSubscription
+		if err := scanWebPushSubscription(rows, &sub); err != nil {
+			return nil, fmt.Errorf("scan web push subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate web push subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+func scanWebPushSubscription(row pgx.Row, sub *domain.WebPushSubscription) error {
+	var userAgent *string
+	var expiration *time.Time
+
+	err := row.Scan(&sub.ID, &sub.UserID, &sub.Endpoint, &sub.P256dh, &sub.Auth, &userAgent, &expiration, &sub.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	sub.UserAgent = ""
+	if userAgent != nil {
+		sub.UserAgent = *userAgent
+	}
+	sub.ExpirationTime = nil
+	if expiration != nil {
+		ms := expiration.UnixMilli()
+		sub.ExpirationTime = &ms
+	}
+	sub.CreatedAt = sub.CreatedAt.UTC()
+	return nil
+}
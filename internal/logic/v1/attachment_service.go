@@ -0,0 +1,157 @@
+package v1
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/internal/logic/v1/email"
+	"github.com/duynhne/notification-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AttachmentService resolves email attachments from send requests: it decodes inline
+// content, fetches URL references and enforces size limits.
+type AttachmentService struct {
+	httpClient *http.Client
+	maxSize    int64 // Per attachment, decoded
+	maxTotal   int64 // All attachments of a message, decoded
+	allowHTTP  bool
+}
+
+// NewAttachmentService creates an AttachmentService. fetchTimeout bounds each URL fetch.
+func NewAttachmentService(maxSize, maxTotal int64, fetchTimeout time.Duration, allowHTTP bool) *AttachmentService {
+	return &AttachmentService{
+		httpClient: &http.Client{
+			Timeout: fetchTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 5 {
+					return errors.New("too many redirects")
+				}
+				return validateEndpointURL(req.URL.String(), allowHTTP)
+			},
+		},
+		maxSize:   maxSize,
+		maxTotal:  maxTotal,
+		allowHTTP: allowHTTP,
+	}
+}
+
+// Resolve returns the attachments ready to render. Inline images (ContentID set) must
+// be images.
+func (s *AttachmentService) Resolve(ctx context.Context, reqs []domain.EmailAttachment) ([]email.Attachment, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+
+	ctx, span := middleware.StartSpan(ctx, "email.attachments.resolve", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.Int("attachments", len(reqs)),
+	))
+	defer span.End()
+
+	attachments := make([]email.Attachment, 0, len(reqs))
+	var total int64
+	for _, req := range reqs {
+		attachment, err := s.resolve(ctx, req)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+
+		total += int64(len(attachment.Data))
+		if total > s.maxTotal {
+			return nil, fmt.Errorf("attachments exceed %d bytes: %w", s.maxTotal, ErrAttachmentTooLarge)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	span.SetAttributes(attribute.Int64("attachments.bytes", total))
+	return attachments, nil
+}
+
+func (s *AttachmentService) resolve(ctx context.Context, req domain.EmailAttachment) (email.Attachment, error) {
+	attachment := email.Attachment{Filename: req.Filename, ContentID: req.ContentID}
+
+	var fetchedType string
+	if req.URL != "" {
+		data, contentType, err := s.fetch(ctx, req.URL)
+		if err != nil {
+			return attachment, fmt.Errorf("attachment %s: %w", req.Filename, err)
+		}
+		attachment.Data = data
+		fetchedType = contentType
+	} else {
+		if int64(base64.StdEncoding.DecodedLen(len(req.Content))) > s.maxSize+2 {
+			return attachment, fmt.Errorf("attachment %s exceeds %d bytes: %w", req.Filename, s.maxSize, ErrAttachmentTooLarge)
+		}
+		data, err := base64.StdEncoding.DecodeString(req.Content)
+		if err != nil {
+			return attachment, fmt.Errorf("attachment %s: content is not base64: %w", req.Filename, ErrInvalidAttachment)
+		}
+		attachment.Data = data
+	}
+	if int64(len(attachment.Data)) > s.maxSize {
+		return attachment, fmt.Errorf("attachment %s exceeds %d bytes: %w", req.Filename, s.maxSize, ErrAttachmentTooLarge)
+	}
+
+	// Declared type first, then a specific type from the fetched response, then sniffing
+	switch {
+	case req.ContentType != "":
+		attachment.ContentType = req.ContentType
+	case fetchedType != "" && !strings.HasPrefix(fetchedType, "application/octet-stream"):
+		attachment.ContentType = fetchedType
+	default:
+		attachment.ContentType = email.DetectContentType(req.Filename, attachment.Data)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(attachment.ContentType)
+	if err != nil {
+		return attachment, fmt.Errorf("attachment %s: content type %q: %w", req.Filename, attachment.ContentType, ErrInvalidAttachment)
+	}
+	if req.ContentID != "" && !strings.HasPrefix(mediaType, "image/") {
+		return attachment, fmt.Errorf("inline attachment %s is %s, not an image: %w", req.Filename, mediaType, ErrInvalidAttachment)
+	}
+	return attachment, nil
+}
+
+// fetch downloads an attachment, returning its body and Content-Type header.
+func (s *AttachmentService) fetch(ctx context.Context, url string) ([]byte, string, error) {
+	if err := validateEndpointURL(url, s.allowHTTP); err != nil {
+		return nil, "", fmt.Errorf("%w: %w", err, ErrInvalidAttachment)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("build request: %w: %w", err, ErrInvalidAttachment)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetch: %w: %w", err, ErrInvalidAttachment)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, "", fmt.Errorf("fetch: status %d: %w", resp.StatusCode, ErrInvalidAttachment)
+	}
+	if resp.ContentLength > s.maxSize {
+		return nil, "", fmt.Errorf("exceeds %d bytes: %w", s.maxSize, ErrAttachmentTooLarge)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, s.maxSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("fetch: %w: %w", err, ErrInvalidAttachment)
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, "", fmt.Errorf("exceeds %d bytes: %w", s.maxSize, ErrAttachmentTooLarge)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
@@ -0,0 +1,387 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/internal/logic/v1/retry"
+	"github.com/duynhne/notification-service/internal/logic/v1/webhook"
+	"github.com/duynhne/notification-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Webhook event types of callbacks to calling services.
+const (
+	StatusChangedEventType = "notification.status_changed" // Delivery status changed
+	ActionTakenEventType   = "notification.action_taken"   // User chose an action of the notification
+)
+
+// callbackLease is how long a claimed callback event is hidden from other workers.
+// It must exceed the per-attempt request timeout.
+const callbackLease = time.Minute
+
+// statusTransitions lists the statuses each delivery status may move to.
+var statusTransitions = map[string][]string{
+	domain.StatusQueued:    {domain.StatusSent, domain.StatusFailed, domain.StatusExpired},
+	domain.StatusSent:      {domain.StatusDelivered, domain.StatusFailed, domain.StatusBounced},
+	domain.StatusDelivered: {domain.StatusBounced},
+}
+
+// CallbackService tracks notification delivery status and reports every change to the
+// calling service's callback URL. Events are written to an outbox and delivered by
+// DispatchDue, so each one is delivered at least once even across restarts.
+type CallbackService struct {
+	notifications domain.NotificationRepository
+	ids           *NotificationIDs
+	clients       domain.APIClientRepository
+	events        domain.CallbackEventRepository
+	client        *webhook.Client
+	policy        retry.Policy
+	allowHTTP     bool
+}
+
+// NewCallbackService creates a CallbackService. client should not retry on its own;
+// policy schedules redelivery through the outbox instead (MaxAttempts bounds the total).
+func NewCallbackService(
+	notifications domain.NotificationRepository,
+	ids *NotificationIDs,
+	clients domain.APIClientRepository,
+	events domain.CallbackEventRepository,
+	client *webhook.Client,
+	policy retry.Policy,
+	allowHTTP bool,
+) *CallbackService {
+	return &CallbackService{
+		notifications: notifications,
+		ids:           ids,
+		clients:       clients,
+		events:        events,
+		client:        client,
+		policy:        policy,
+		allowHTTP:     allowHTTP,
+	}
+}
+
+// RegisterClient creates or updates an API client. A signing secret is generated for new
+// clients and when rotation is requested; it is only returned in those cases.
+func (s *CallbackService) RegisterClient(
+	ctx context.Context,
+	clientID string,
+	req domain.RegisterClientRequest,
+) (*domain.APIClient, error) {
+	ctx, span := middleware.StartSpan(ctx, "callback.register_client", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("client_id", clientID),
+	))
+	defer span.End()
+
+	if clientID == "" || len(clientID) > 64 {
+		return nil, fmt.Errorf("client_id must be 1-64 characters: %w", ErrInvalidCallback)
+	}
+	if req.CallbackURL != "" {
+		if err := validateEndpointURL(req.CallbackURL, s.allowHTTP); err != nil {
+			return nil, fmt.Errorf("callback %w: %w", err, ErrInvalidCallback)
+		}
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	client := &domain.APIClient{ClientID: clientID, CallbackURL: req.CallbackURL, Secret: secret}
+	created, err := s.clients.Upsert(ctx, client, req.RotateSecret)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("register client: %w", err)
+	}
+
+	if !created && !req.RotateSecret {
+		client.Secret = ""
+	}
+	span.SetAttributes(attribute.Bool("client.created", created))
+	return client, nil
+}
+
+// GetClient returns a registered client without its secret.
+func (s *CallbackService) GetClient(ctx context.Context, clientID string) (*domain.APIClient, error) {
+	ctx, span := middleware.StartSpan(ctx, "callback.get_client", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("client_id", clientID),
+	))
+	defer span.End()
+
+	client, err := s.clients.FindByID(ctx, clientID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if client == nil {
+		return nil, fmt.Errorf("get client %q: %w", clientID, ErrClientNotFound)
+	}
+
+	client.Secret = ""
+	return client, nil
+}
+
+// DeleteClient removes a client. Pending callbacks for it are dropped on their next attempt.
+func (s *CallbackService) DeleteClient(ctx context.Context, clientID string) error {
+	ctx, span := middleware.StartSpan(ctx, "callback.delete_client", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("client_id", clientID),
+	))
+	defer span.End()
+
+	deleted, err := s.clients.Delete(ctx, clientID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("delete client %q: %w", clientID, ErrClientNotFound)
+	}
+
+	return nil
+}
+
+// ValidateCallback checks a per-request callback URL. Callbacks are signed with the
+// client's secret, so a URL is only accepted from a registered client.
+func (s *CallbackService) ValidateCallback(ctx context.Context, clientID, callbackURL string) error {
+	if callbackURL == "" {
+		return nil
+	}
+	if clientID == "" {
+		return fmt.Errorf("callback_url requires the X-Client-ID header: %w", ErrInvalidCallback)
+	}
+	if err := validateEndpointURL(callbackURL, s.allowHTTP); err != nil {
+		return fmt.Errorf("callback %w: %w", err, ErrInvalidCallback)
+	}
+
+	client, err := s.clients.FindByID(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		return fmt.Errorf("client %q is not registered: %w", clientID, ErrInvalidCallback)
+	}
+	return nil
+}
+
+// Queued reports a newly created notification in the queued status.
+func (s *CallbackService) Queued(ctx context.Context, notification *domain.Notification) error {
+	return s.enqueue(ctx, notification, "")
+}
+
+// Transition moves a notification to status to and queues a callback for the change.
+// The notification is updated in place.
+func (s *CallbackService) Transition(ctx context.Context, notification *domain.Notification, to, reason string) error {
+	ctx, span := middleware.StartSpan(ctx, "callback.transition", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("notification.id", notification.PublicID.String()),
+		attribute.String("status.from", notification.Status),
+		attribute.String("status.to", to),
+	))
+	defer span.End()
+
+	from := notification.Status
+	if !slices.Contains(statusTransitions[from], to) {
+		return fmt.Errorf("notification %s from %q to %q: %w", notification.PublicID, from, to, ErrInvalidStatusTransition)
+	}
+
+	updated, err := s.notifications.UpdateStatus(ctx, notification.Key(), from, to, reason)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if !updated {
+		// Another report changed the status since it was read.
+		return fmt.Errorf("notification %s is no longer %q: %w", notification.PublicID, from, ErrInvalidStatusTransition)
+	}
+
+	notification.Status = to
+	notification.StatusReason = reason
+
+	// The status change is already committed; a lost callback must not turn it into an error.
+	if err := s.enqueue(ctx, notification, from); err != nil {
+		span.RecordError(err)
+	}
+	return nil
+}
+
+// UpdateStatus applies a status report (e.g. from a provider or operator) to a notification.
+func (s *CallbackService) UpdateStatus(ctx context.Context, id string, req domain.UpdateStatusRequest) (*domain.Notification, error) {
+	ctx, span := middleware.StartSpan(ctx, "callback.update_status", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("notification.id", id),
+		attribute.String("status", req.Status),
+	))
+	defer span.End()
+
+	key, err := s.ids.Resolve(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	notification, err := s.notifications.FindByID(ctx, key)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if notification == nil {
+		return nil, fmt.Errorf("get notification by id %q: %w", id, ErrNotificationNotFound)
+	}
+
+	if err := s.Transition(ctx, notification, req.Status, req.Reason); err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
+
+// ActionTaken forwards the action the user chose (notification.ActionTaken) to the
+// service that sent the notification.
+func (s *CallbackService) ActionTaken(ctx context.Context, notification *domain.Notification) error {
+	return s.enqueueEvent(ctx, notification, ActionTakenEventType, domain.ActionTakenEvent{
+		NotificationID: notification.PublicID,
+		Type:           notification.Type,
+		UserID:         notification.UserID,
+		Action:         notification.ActionTaken,
+		EntityType:     notification.EntityType,
+		EntityID:       notification.EntityID,
+		OccurredAt:     time.Now().UTC(),
+	})
+}
+
+// enqueue writes a status-change event to the outbox.
+func (s *CallbackService) enqueue(ctx context.Context, notification *domain.Notification, previous string) error {
+	return s.enqueueEvent(ctx, notification, StatusChangedEventType, domain.StatusChangedEvent{
+		NotificationID: notification.PublicID,
+		Channel:        notification.Type,
+		Recipient:      notification.Recipient,
+		Status:         notification.Status,
+		PreviousStatus: previous,
+		Reason:         notification.StatusReason,
+		OccurredAt:     time.Now().UTC(),
+	})
+}
+
+// enqueueEvent writes a callback event about notification to the outbox. Notifications
+// without a client, or whose client has no callback URL, are not reported.
+func (s *CallbackService) enqueueEvent(ctx context.Context, notification *domain.Notification, eventType string, eventData any) error {
+	if notification.ClientID == "" {
+		return nil
+	}
+
+	url := notification.CallbackURL
+	if url == "" {
+		client, err := s.clients.FindByID(ctx, notification.ClientID)
+		if err != nil {
+			return err
+		}
+		if client == nil || client.CallbackURL == "" {
+			return nil
+		}
+		url = client.CallbackURL
+	}
+
+	data, err := json.Marshal(eventData)
+	if err != nil {
+		return fmt.Errorf("marshal %s event: %w", eventType, err)
+	}
+
+	event, err := webhook.NewEvent(eventType, data)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal callback event: %w", err)
+	}
+
+	return s.events.Enqueue(ctx, &domain.CallbackEvent{
+		NotificationID: notification.ID,
+		ClientID:       notification.ClientID,
+		URL:            url,
+		EventID:        event.ID,
+		Payload:        payload,
+	})
+}
+
+// DispatchDue delivers up to limit due callback events concurrently. Failed events are
+// rescheduled with the policy's backoff until MaxAttempts is reached, then marked dead.
+// Returns the number of events delivered.
+func (s *CallbackService) DispatchDue(ctx context.Context, limit int) (int, error) {
+	ctx, span := middleware.StartSpan(ctx, "callback.dispatch", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	events, err := s.events.ClaimDue(ctx, limit, callbackLease)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	delivered := make([]bool, len(events))
+	errs := make([]error, len(events))
+	var wg sync.WaitGroup
+	for i, event := range events {
+		wg.Go(func() {
+			delivered[i], errs[i] = s.dispatch(ctx, event)
+		})
+	}
+	wg.Wait()
+
+	count := 0
+	for _, ok := range delivered {
+		if ok {
+			count++
+		}
+	}
+	span.SetAttributes(
+		attribute.Int("callback.claimed", len(events)),
+		attribute.Int("callback.delivered", count),
+	)
+
+	for _, err := range errs {
+		if err != nil {
+			span.RecordError(err)
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// dispatch makes one delivery attempt and records its outcome. The returned error only
+// reports failures to record the outcome; delivery failures are stored on the event.
+func (s *CallbackService) dispatch(ctx context.Context, event domain.CallbackEvent) (bool, error) {
+	var sendErr error
+	if event.Secret == "" {
+		sendErr = retry.Permanent(fmt.Errorf("client %q is no longer registered", event.ClientID))
+	} else {
+		var payload webhook.Event
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			sendErr = retry.Permanent(fmt.Errorf("decode callback event: %w", err))
+		} else {
+			sendErr = s.client.Send(ctx, event.URL, event.Secret, &payload)
+		}
+	}
+
+	if sendErr == nil {
+		return true, s.events.MarkDelivered(ctx, event.ID)
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the lease expires and another run retries the event.
+		return false, nil
+	}
+	if retry.IsPermanent(sendErr) || event.Attempts >= s.policy.MaxAttempts {
+		return false, s.events.MarkDead(ctx, event.ID, sendErr.Error())
+	}
+	return false, s.events.MarkFailed(ctx, event.ID, s.policy.Backoff(event.Attempts), sendErr.Error())
+}
@@ -0,0 +1,272 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/internal/logic/v1/health"
+	"github.com/duynhne/notification-service/internal/logic/v1/retry"
+	"github.com/duynhne/notification-service/internal/logic/v1/throttle"
+	"github.com/duynhne/notification-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Channels of queued sends.
+const (
+	channelEmail = "email"
+	channelSMS   = "sms"
+	channelPush  = "push"
+)
+
+// deliveryLease is how long a claimed send is hidden from other workers. It must exceed
+// deliverySendTimeout.
+const deliveryLease = 5 * time.Minute
+
+// deliverySendTimeout bounds one attempt of a send, including the wait for its
+// provider's rate limit; a send that runs out of time is retried later.
+const deliverySendTimeout = 2 * time.Minute
+
+// expiredReason is the status reason of notifications that expired before they were sent.
+const expiredReason = "expired before it was sent"
+
+// channelSender hands a claimed notification to its channel's providers. payload holds
+// the details queued with it.
+type channelSender func(ctx context.Context, notification *domain.Notification, payload []byte) error
+
+// DeliveryService sends queued email, SMS and push notifications. The API records each
+// notification and queues its send; the worker claims due sends with DispatchDue, so
+// provider rate limits and outages delay sends rather than API requests.
+type DeliveryService struct {
+	notifications domain.NotificationRepository
+	deliveries    domain.NotificationDeliveryRepository
+	callbacks     *CallbackService
+	senders       map[string]channelSender
+	policy        retry.Policy
+}
+
+// NewDeliveryService creates a DeliveryService. Sends a provider refuses for now (rate
+// limit, open circuit, timeout) are retried with the policy's backoff until MaxAttempts
+// is reached; other failures fail the notification at once.
+func NewDeliveryService(
+	notifications domain.NotificationRepository,
+	deliveries domain.NotificationDeliveryRepository,
+	callbacks *CallbackService,
+	service *NotificationService,
+	pushService *PushService,
+	policy retry.Policy,
+) *DeliveryService {
+	return &DeliveryService{
+		notifications: notifications,
+		deliveries:    deliveries,
+		callbacks:     callbacks,
+		senders: map[string]channelSender{
+			channelEmail: service.sendQueuedEmail,
+			channelSMS:   service.sendQueuedSMS,
+			channelPush:  pushService.sendQueued,
+		},
+		policy: policy,
+	}
+}
+
+// queueDelivery reports a recorded notification as queued and queues its send over
+// channel with payload. A notification that is already expired is marked expired
+// instead, and one whose send cannot be queued is marked failed.
+func queueDelivery(
+	ctx context.Context,
+	callbacks *CallbackService,
+	deliveries domain.NotificationDeliveryRepository,
+	notification *domain.Notification,
+	channel string,
+	payload any,
+) error {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("notification.priority", notification.Priority))
+
+	if err := callbacks.Queued(ctx, notification); err != nil {
+		// The notification exists; a lost callback must not fail the send.
+		trace.SpanFromContext(ctx).RecordError(err)
+	}
+
+	if expiresAt := notification.ExpiresAt; expiresAt != nil && !time.Now().Before(*expiresAt) {
+		observeDelivery(channel, notification, time.Now(), throttle.ErrExpired)
+		return expire(ctx, callbacks, notification)
+	}
+
+	var data []byte
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("marshal %s delivery: %w", channel, err)
+		}
+	}
+	err := deliveries.Enqueue(ctx, &domain.NotificationDelivery{
+		Notification: notification.Key(),
+		Channel:      channel,
+		Priority:     notification.Priority,
+		Payload:      data,
+	})
+	if err != nil {
+		// Nothing would ever send it, so it must not stay queued
+		if terr := callbacks.Transition(ctx, notification, domain.StatusFailed, "could not be queued"); terr != nil {
+			err = errors.Join(err, terr)
+		}
+		return fmt.Errorf("queue %s notification %s: %w", channel, notification.PublicID, err)
+	}
+	return nil
+}
+
+// DispatchDue sends up to limit due notifications concurrently, each waiting for its
+// provider's rate limit in its priority lane. Returns the number of sends claimed.
+func (s *DeliveryService) DispatchDue(ctx context.Context, limit int) (int, error) {
+	ctx, span := middleware.StartSpan(ctx, "notification.dispatch", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	deliveries, err := s.deliveries.ClaimDue(ctx, limit, deliveryLease)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	sent := make([]bool, len(deliveries))
+	errs := make([]error, len(deliveries))
+	var wg sync.WaitGroup
+	for i, delivery := range deliveries {
+		wg.Go(func() {
+			sent[i], errs[i] = s.dispatch(ctx, delivery)
+		})
+	}
+	wg.Wait()
+
+	count := 0
+	for _, ok := range sent {
+		if ok {
+			count++
+		}
+	}
+	span.SetAttributes(
+		attribute.Int("notification.claimed", len(deliveries)),
+		attribute.Int("notification.sent", count),
+	)
+
+	for _, err := range errs {
+		if err != nil {
+			span.RecordError(err)
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// dispatch makes one attempt of a send and records its outcome on the notification. The
+// returned error only reports failures to record the outcome.
+func (s *DeliveryService) dispatch(ctx context.Context, delivery domain.NotificationDelivery) (bool, error) {
+	ctx, span := middleware.StartSpan(ctx, "notification.deliver", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("notification.channel", delivery.Channel),
+		attribute.String("notification.priority", delivery.Priority),
+		attribute.Int("delivery.attempt", delivery.Attempts),
+	))
+	defer span.End()
+
+	notification, err := s.notifications.FindByID(ctx, delivery.Notification)
+	if err != nil {
+		// The lease expires and another run retries the send.
+		span.RecordError(err)
+		return false, err
+	}
+	if notification == nil || notification.Status != domain.StatusQueued {
+		// Deleted or replaced before it was sent, or already sent by an earlier attempt
+		return false, s.deliveries.Delete(ctx, delivery.ID)
+	}
+	span.SetAttributes(attribute.String("notification.id", notification.PublicID.String()))
+
+	send, ok := s.senders[delivery.Channel]
+	if !ok {
+		return false, s.finish(ctx, delivery, notification, domain.StatusFailed,
+			fmt.Sprintf("unknown channel %q", delivery.Channel))
+	}
+
+	ctx = throttle.WithPriority(ctx, notification.Priority)
+	start := time.Now()
+	if expiresAt := notification.ExpiresAt; expiresAt != nil {
+		if !time.Now().Before(*expiresAt) {
+			observeDelivery(delivery.Channel, notification, start, throttle.ErrExpired)
+			return false, s.finish(ctx, delivery, notification, domain.StatusExpired, expiredReason)
+		}
+		ctx = throttle.WithExpiry(ctx, *expiresAt)
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, deliverySendTimeout)
+	sendErr := send(sendCtx, notification, delivery.Payload)
+	cancel()
+
+	switch {
+	case sendErr == nil:
+		observeDelivery(delivery.Channel, notification, start, nil)
+		return true, s.finish(ctx, delivery, notification, domain.StatusSent, "")
+	case errors.Is(sendErr, throttle.ErrExpired):
+		observeDelivery(delivery.Channel, notification, start, sendErr)
+		return false, s.finish(ctx, delivery, notification, domain.StatusExpired, expiredReason)
+	case ctx.Err() != nil:
+		// Shutting down; the lease expires and another run retries the send.
+		return false, nil
+	}
+
+	span.RecordError(sendErr)
+	if retryableDelivery(sendErr) && delivery.Attempts < s.policy.MaxAttempts {
+		return false, s.deliveries.Reschedule(ctx, delivery.ID, s.policy.Backoff(delivery.Attempts), sendErr.Error())
+	}
+	observeDelivery(delivery.Channel, notification, start, sendErr)
+	return false, s.finish(ctx, delivery, notification, domain.StatusFailed, sendErr.Error())
+}
+
+// finish moves the notification to its final status and removes its send from the queue.
+// The send is removed even when the status cannot be recorded, so that it is not repeated.
+func (s *DeliveryService) finish(
+	ctx context.Context,
+	delivery domain.NotificationDelivery,
+	notification *domain.Notification,
+	status, reason string,
+) error {
+	err := s.callbacks.Transition(ctx, notification, status, reason)
+	return errors.Join(err, s.deliveries.Delete(ctx, delivery.ID))
+}
+
+// retryableDelivery reports whether a send failed because its provider cannot take it
+// right now, rather than because of the message.
+func retryableDelivery(err error) bool {
+	return errors.Is(err, throttle.ErrRateLimited) ||
+		errors.Is(err, health.ErrCircuitOpen) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// expire marks a notification that expired before it was sent and returns
+// ErrNotificationExpired, joined with any error recording it.
+func expire(ctx context.Context, callbacks *CallbackService, notification *domain.Notification) error {
+	expiredErr := fmt.Errorf("notification %s expired at %s: %w",
+		notification.PublicID, notification.ExpiresAt.Format(time.RFC3339), ErrNotificationExpired)
+	if err := callbacks.Transition(ctx, notification, domain.StatusExpired, expiredReason); err != nil {
+		return errors.Join(expiredErr, err)
+	}
+	return expiredErr
+}
+
+// observeDelivery records the outcome and duration of a provider send over channel.
+func observeDelivery(channel string, notification *domain.Notification, start time.Time, err error) {
+	result := "sent"
+	switch {
+	case errors.Is(err, throttle.ErrExpired):
+		result = "expired"
+	case err != nil:
+		result = "failed"
+	}
+	notificationsDelivered.WithLabelValues(channel, notification.Priority, result).Inc()
+	notificationDeliveryDuration.WithLabelValues(channel, notification.Priority).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,377 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/internal/logic/v1/health"
+	"github.com/duynhne/notification-service/internal/logic/v1/retry"
+	"github.com/duynhne/notification-service/internal/logic/v1/throttle"
+)
+
+// fakeNotifications keeps notifications in memory. Only the methods used by
+// DeliveryService and CallbackService.Transition are implemented.
+type fakeNotifications struct {
+	domain.NotificationRepository
+
+	mu            sync.Mutex
+	notifications map[int64]*domain.Notification
+	findErr       error
+}
+
+func (r *fakeNotifications) FindByID(_ context.Context, key domain.NotificationKey) (*domain.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.findErr != nil {
+		return nil, r.findErr
+	}
+	n, ok := r.notifications[key.ID]
+	if !ok || !n.CreatedAt.Equal(key.CreatedAt) {
+		return nil, nil
+	}
+	found := *n
+	return &found, nil
+}
+
+func (r *fakeNotifications) UpdateStatus(_ context.Context, key domain.NotificationKey, from, to, reason string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, ok := r.notifications[key.ID]
+	if !ok || n.Status != from {
+		return false, nil
+	}
+	n.Status, n.StatusReason = to, reason
+	return true, nil
+}
+
+func (r *fakeNotifications) status(id int64) (string, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.notifications[id].Status, r.notifications[id].StatusReason
+}
+
+// queuedSend is a row of the fake queue.
+type queuedSend struct {
+	delivery  domain.NotificationDelivery
+	due       time.Time
+	lastError string
+}
+
+// fakeQueue is an in-memory notification_deliveries table that leases claimed sends
+// like NotificationDeliveryRepository, on a manually advanced clock.
+type fakeQueue struct {
+	mu     sync.Mutex
+	now    time.Time
+	rows   map[int64]*queuedSend
+	nextID int64
+}
+
+func newFakeQueue() *fakeQueue {
+	return &fakeQueue{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), rows: map[int64]*queuedSend{}}
+}
+
+func (q *fakeQueue) Enqueue(_ context.Context, delivery *domain.NotificationDelivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.nextID++
+	delivery.ID = q.nextID
+	q.rows[delivery.ID] = &queuedSend{delivery: *delivery, due: q.now}
+	return nil
+}
+
+func (q *fakeQueue) ClaimDue(_ context.Context, limit int, lease time.Duration) ([]domain.NotificationDelivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var due []*queuedSend
+	for _, row := range q.rows {
+		if !row.due.After(q.now) {
+			due = append(due, row)
+		}
+	}
+	slices.SortFunc(due, func(a, b *queuedSend) int { return a.due.Compare(b.due) })
+
+	var claimed []domain.NotificationDelivery
+	for _, row := range due[:min(limit, len(due))] {
+		row.delivery.Attempts++
+		row.due = q.now.Add(lease)
+		claimed = append(claimed, row.delivery)
+	}
+	return claimed, nil
+}
+
+func (q *fakeQueue) Reschedule(_ context.Context, id int64, retryAfter time.Duration, lastError string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if row, ok := q.rows[id]; ok {
+		row.due = q.now.Add(retryAfter)
+		row.lastError = lastError
+	}
+	return nil
+}
+
+func (q *fakeQueue) Delete(_ context.Context, id int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.rows, id)
+	return nil
+}
+
+func (q *fakeQueue) advance(d time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.now = q.now.Add(d)
+}
+
+func (q *fakeQueue) row(id int64) (queuedSend, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	row, ok := q.rows[id]
+	if !ok {
+		return queuedSend{}, false
+	}
+	return *row, true
+}
+
+var testDeliveryPolicy = retry.Policy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Second,
+	MaxBackoff:     4 * time.Second,
+	Multiplier:     2,
+}
+
+// deliveryFixture is a DeliveryService with one queued email notification.
+type deliveryFixture struct {
+	service       *DeliveryService
+	notifications *fakeNotifications
+	queue         *fakeQueue
+	notification  *domain.Notification
+	delivery      domain.NotificationDelivery
+	sends         int
+}
+
+func newDeliveryFixture(t *testing.T, send func(attempt int) error) *deliveryFixture {
+	t.Helper()
+	f := &deliveryFixture{queue: newFakeQueue()}
+	f.notification = &domain.Notification{
+		ID:        7,
+		CreatedAt: f.queue.now,
+		Type:      "email",
+		Status:    domain.StatusQueued,
+		Priority:  domain.PriorityNormal,
+	}
+	f.notifications = &fakeNotifications{notifications: map[int64]*domain.Notification{7: f.notification}}
+	f.service = &DeliveryService{
+		notifications: f.notifications,
+		deliveries:    f.queue,
+		callbacks:     &CallbackService{notifications: f.notifications},
+		senders: map[string]channelSender{
+			channelEmail: func(context.Context, *domain.Notification, []byte) error {
+				f.sends++
+				return send(f.sends)
+			},
+		},
+		policy: testDeliveryPolicy,
+	}
+	f.delivery = domain.NotificationDelivery{Notification: f.notification.Key(), Channel: channelEmail, Priority: domain.PriorityNormal}
+	if err := f.queue.Enqueue(context.Background(), &f.delivery); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f *deliveryFixture) dispatch(t *testing.T) int {
+	t.Helper()
+	claimed, err := f.service.DispatchDue(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("DispatchDue: %v", err)
+	}
+	return claimed
+}
+
+func TestDeliveryDispatch(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name     string
+		setup    func(f *deliveryFixture)
+		sendErr  error
+		attempts int // Attempts already made before this one
+
+		wantErr    bool // The outcome could not be recorded
+		wantSends  int
+		wantStatus string
+		wantReason string
+		wantQueued bool // Still in the queue, due again later
+		wantRetry  bool // Rescheduled with backoff rather than leased
+	}{
+		{name: "sent", wantSends: 1, wantStatus: domain.StatusSent},
+		{
+			name: "rejected", sendErr: errors.New("550 mailbox unavailable"),
+			wantSends: 1, wantStatus: domain.StatusFailed, wantReason: "550 mailbox unavailable",
+		},
+		{
+			name: "rate limited", sendErr: throttle.RateLimited(errors.New("429"), time.Second),
+			wantSends: 1, wantStatus: domain.StatusQueued, wantQueued: true, wantRetry: true,
+		},
+		{
+			name: "circuit open", sendErr: fmt.Errorf("smtp: %w", health.ErrCircuitOpen),
+			wantSends: 1, wantStatus: domain.StatusQueued, wantQueued: true, wantRetry: true,
+		},
+		{
+			name: "timed out", sendErr: fmt.Errorf("dial: %w", context.DeadlineExceeded),
+			wantSends: 1, wantStatus: domain.StatusQueued, wantQueued: true, wantRetry: true,
+		},
+		{
+			name: "rate limited on the last attempt", sendErr: throttle.RateLimited(errors.New("429"), 0), attempts: 2,
+			wantSends: 1, wantStatus: domain.StatusFailed, wantReason: "429",
+		},
+		{
+			name: "expired while waiting", sendErr: throttle.ErrExpired,
+			wantSends: 1, wantStatus: domain.StatusExpired, wantReason: expiredReason,
+		},
+		{
+			name:      "expired before the attempt",
+			setup:     func(f *deliveryFixture) { f.notification.ExpiresAt = &past },
+			wantSends: 0, wantStatus: domain.StatusExpired, wantReason: expiredReason,
+		},
+		{
+			name:      "already sent",
+			setup:     func(f *deliveryFixture) { f.notification.Status = domain.StatusSent },
+			wantSends: 0, wantStatus: domain.StatusSent,
+		},
+		{
+			name:      "notification gone",
+			setup:     func(f *deliveryFixture) { delete(f.notifications.notifications, 7) },
+			wantSends: 0,
+		},
+		{
+			name: "unknown channel",
+			setup: func(f *deliveryFixture) {
+				f.queue.rows[f.delivery.ID].delivery.Channel = "fax"
+			},
+			wantSends: 0, wantStatus: domain.StatusFailed, wantReason: `unknown channel "fax"`,
+		},
+		{
+			// The lease runs out and a later run tries again
+			name:    "notification unreadable",
+			setup:   func(f *deliveryFixture) { f.notifications.findErr = errors.New("connection reset") },
+			wantErr: true, wantSends: 0, wantStatus: domain.StatusQueued, wantQueued: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newDeliveryFixture(t, func(int) error { return tt.sendErr })
+			f.queue.rows[f.delivery.ID].delivery.Attempts = tt.attempts
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+
+			claimed, err := f.service.DispatchDue(context.Background(), 10)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DispatchDue error = %v, want error %v", err, tt.wantErr)
+			}
+			if claimed != 1 {
+				t.Errorf("claimed = %d, want 1", claimed)
+			}
+			if f.sends != tt.wantSends {
+				t.Errorf("sends = %d, want %d", f.sends, tt.wantSends)
+			}
+			if tt.wantStatus != "" {
+				status, reason := f.notifications.status(7)
+				if status != tt.wantStatus || reason != tt.wantReason {
+					t.Errorf("status = %q (%q), want %q (%q)", status, reason, tt.wantStatus, tt.wantReason)
+				}
+			}
+
+			row, queued := f.queue.row(f.delivery.ID)
+			if queued != tt.wantQueued {
+				t.Fatalf("queued = %v, want %v", queued, tt.wantQueued)
+			}
+			if !queued {
+				return
+			}
+			if row.delivery.Attempts != tt.attempts+1 {
+				t.Errorf("attempts = %d, want %d", row.delivery.Attempts, tt.attempts+1)
+			}
+			wait := row.due.Sub(f.queue.now)
+			if tt.wantRetry {
+				// Half of the first backoff is jitter
+				if backoff := testDeliveryPolicy.InitialBackoff; wait < backoff/2 || wait > backoff {
+					t.Errorf("retry after %v, want within [%v, %v]", wait, backoff/2, backoff)
+				}
+				if row.lastError == "" {
+					t.Error("last error not recorded")
+				}
+			} else if wait != deliveryLease {
+				t.Errorf("due after %v, want the lease %v", wait, deliveryLease)
+			}
+		})
+	}
+}
+
+func TestDeliveryRetriesUntilSent(t *testing.T) {
+	f := newDeliveryFixture(t, func(attempt int) error {
+		if attempt < 3 {
+			return throttle.RateLimited(errors.New("429"), 0)
+		}
+		return nil
+	})
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		if claimed := f.dispatch(t); claimed != 1 {
+			t.Fatalf("attempt %d: claimed = %d, want 1", attempt, claimed)
+		}
+		if attempt == 3 {
+			break
+		}
+		// Not due again until its backoff has passed
+		if claimed := f.dispatch(t); claimed != 0 {
+			t.Fatalf("attempt %d: claimed again before the backoff, %d", attempt, claimed)
+		}
+		f.queue.advance(testDeliveryPolicy.Backoff(attempt) * 2)
+	}
+
+	if f.sends != 3 {
+		t.Errorf("sends = %d, want 3", f.sends)
+	}
+	if status, _ := f.notifications.status(7); status != domain.StatusSent {
+		t.Errorf("status = %q, want %q", status, domain.StatusSent)
+	}
+	if _, queued := f.queue.row(f.delivery.ID); queued {
+		t.Error("sent notification still queued")
+	}
+	if claimed := f.dispatch(t); claimed != 0 {
+		t.Errorf("claimed = %d after the send, want 0", claimed)
+	}
+}
+
+func TestDeliveryLeaseExpires(t *testing.T) {
+	f := newDeliveryFixture(t, func(int) error { return nil })
+	f.notifications.findErr = errors.New("connection reset")
+	if _, err := f.service.DispatchDue(context.Background(), 10); err == nil {
+		t.Fatal("DispatchDue error = nil, want the read error")
+	}
+	f.notifications.findErr = nil
+
+	// Hidden from other runs while leased
+	f.queue.advance(deliveryLease - time.Second)
+	if claimed := f.dispatch(t); claimed != 0 {
+		t.Fatalf("claimed = %d during the lease, want 0", claimed)
+	}
+
+	f.queue.advance(time.Second)
+	if claimed := f.dispatch(t); claimed != 1 {
+		t.Fatalf("claimed = %d after the lease, want 1", claimed)
+	}
+	if f.sends != 1 {
+		t.Errorf("sends = %d, want 1", f.sends)
+	}
+	if status, _ := f.notifications.status(7); status != domain.StatusSent {
+		t.Errorf("status = %q, want %q", status, domain.StatusSent)
+	}
+}
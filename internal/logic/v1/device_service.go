@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DeviceService manages the push device token registry of authenticated users.
+type DeviceService struct {
+	repo       domain.DeviceTokenRepository
+	staleAfter time.Duration
+}
+
+// NewDeviceService creates a DeviceService. Tokens not seen for staleAfter are pruned by PruneStale.
+func NewDeviceService(repo domain.DeviceTokenRepository, staleAfter time.Duration) *DeviceService {
+	return &DeviceService{
+		repo:       repo,
+		staleAfter: staleAfter,
+	}
+}
+
+// Register adds a device token for the user, or refreshes it if already registered.
+// A token still in use by another user is refused; one that is inactive or stale
+// (e.g. the device changed account without unregistering) moves to this user.
+func (s *DeviceService) Register(ctx context.Context, userID string, req domain.RegisterDeviceRequest) (*domain.DeviceToken, error) {
+	ctx, span := middleware.StartSpan(ctx, "device.register", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("api.version", "v1"),
+		attribute.String("user_id", userID),
+		attribute.String("device.platform", req.Platform),
+	))
+	defer span.End()
+
+	uid, err := parseUserID(userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	device := &domain.DeviceToken{
+		UserID:     uid,
+		Platform:   req.Platform,
+		Token:      req.Token,
+		AppVersion: req.AppVersion,
+		Locale:     req.Locale,
+	}
+	err = s.repo.Upsert(ctx, device, time.Now().Add(-s.staleAfter))
+	if errors.Is(err, domain.ErrDeviceTokenInUse) {
+		span.RecordError(err)
+		return nil, fmt.Errorf("register device for user %q: %w", userID, ErrDeviceTokenInUse)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("register device: %w", err)
+	}
+
+	return device, nil
+}
+
+// Refresh updates last-seen and metadata of a registered token, rotating it to
+// req.NewToken when the push provider issued a new one.
+func (s *DeviceService) Refresh(ctx context.Context, userID string, req domain.RefreshDeviceRequest) (*domain.DeviceToken, error) {
+	ctx, span := middleware.StartSpan(ctx, "device.refresh", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("api.version", "v1"),
+		attribute.String("user_id", userID),
+		attribute.Bool("device.rotated", req.NewToken != "" && req.NewToken != req.Token),
+	))
+	defer span.End()
+
+	uid, err := parseUserID(userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	device := &domain.DeviceToken{
+		Token:      req.NewToken,
+		AppVersion: req.AppVersion,
+		Locale:     req.Locale,
+	}
+	found, err := s.repo.Rotate(ctx, uid, req.Token, device, time.Now().Add(-s.staleAfter))
+	if errors.Is(err, domain.ErrDeviceTokenInUse) {
+		span.RecordError(err)
+		return nil, fmt.Errorf("refresh device for user %q: %w", userID, ErrDeviceTokenInUse)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("refresh device: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("refresh device for user %q: %w", userID, ErrDeviceNotFound)
+	}
+
+	return device, nil
+}
+
+// Unregister removes a device token of the user (e.g. on logout).
+func (s *DeviceService) Unregister(ctx context.Context, userID, token string) error {
+	ctx, span := middleware.StartSpan(ctx, "device.unregister", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("api.version", "v1"),
+		attribute.String("user_id", userID),
+	))
+	defer span.End()
+
+	uid, err := parseUserID(userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	deleted, err := s.repo.Delete(ctx, uid, token)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("unregister device: %w", err)
+	}
+	if !deleted {
+		return fmt.Errorf("unregister device for user %q: %w", userID, ErrDeviceNotFound)
+	}
+
+	return nil
+}
+
+// PruneStale deletes deactivated tokens and tokens not seen within the stale window.
+func (s *DeviceService) PruneStale(ctx context.Context) (int64, error) {
+	ctx, span := middleware.StartSpan(ctx, "device.prune_stale", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("device.stale_after", s.staleAfter.String()),
+	))
+	defer span.End()
+
+	pruned, err := s.repo.DeleteStale(ctx, time.Now().Add(-s.staleAfter))
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	span.SetAttributes(attribute.Int64("devices.pruned", pruned))
+	return pruned, nil
+}
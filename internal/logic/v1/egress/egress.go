@@ -0,0 +1,102 @@
+// Package egress guards outbound requests to URLs chosen by API callers, such as Web Push
+// endpoints and webhook targets, against server-side request forgery.
+//
+// A URL is checked twice: when it is registered (CheckURL), so that callers get an
+// immediate error, and when it is dialed (Client), after DNS resolution, so that a host
+// re-pointed at an internal address later is still refused.
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress indicates a host is, or resolves to, an address that outbound
+// requests must not reach: loopback, private, link-local and other non-public ranges.
+var ErrForbiddenAddress = errors.New("address not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), often used inside clusters.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Allowed reports whether addr is a public unicast address.
+func Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	switch {
+	case !addr.IsValid(),
+		addr.IsUnspecified(),
+		addr.IsLoopback(),
+		addr.IsPrivate(),
+		addr.IsLinkLocalUnicast(),
+		addr.IsLinkLocalMulticast(),
+		addr.IsInterfaceLocalMulticast(),
+		addr.IsMulticast(),
+		sharedAddressSpace.Contains(addr):
+		return false
+	}
+	return true
+}
+
+// CheckURL resolves the host of rawURL and returns ErrForbiddenAddress if any of its
+// addresses is not allowed.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("parse url: %w", err)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return errors.New("url has no host")
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !Allowed(addr) {
+			return fmt.Errorf("host %s: %w", host, ErrForbiddenAddress)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("resolve host %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !Allowed(addr) {
+			return fmt.Errorf("host %s resolves to %s: %w", host, addr, ErrForbiddenAddress)
+		}
+	}
+	return nil
+}
+
+// Dialer returns a dialer that refuses connections to addresses that are not allowed.
+// The check runs on the resolved address, so DNS cannot be used to reach internal hosts.
+func Dialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("dial %s: %w", address, err)
+			}
+			if !Allowed(addrPort.Addr()) {
+				return fmt.Errorf("dial %s: %w", address, ErrForbiddenAddress)
+			}
+			return nil
+		},
+	}
+}
+
+// Client returns an HTTP client whose connections go through Dialer. Requests are not
+// sent through an environment proxy, which would hide the target address from the check.
+func Client(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = Dialer(timeout).DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
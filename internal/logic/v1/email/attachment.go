@@ -0,0 +1,100 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"path"
+	"strings"
+)
+
+// Attachment is a file attached to a message, or an inline image when ContentID is set.
+type Attachment struct {
+	Filename    string // Shown to the recipient; directories are stripped
+	ContentType string // Media type; detected from Data and Filename when empty
+	ContentID   string // Inline image referenced from HTML as cid:<ContentID>
+	Data        []byte
+}
+
+// DetectContentType sniffs the media type of data, falling back to the filename
+// extension when the content alone is not conclusive (plain text, ZIP-based
+// office documents, unknown binaries).
+func DetectContentType(filename string, data []byte) string {
+	sniffed := http.DetectContentType(data)
+	mediaType, _, _ := mime.ParseMediaType(sniffed)
+	switch mediaType {
+	case "application/octet-stream", "text/plain", "application/zip":
+		if byExt := mime.TypeByExtension(strings.ToLower(path.Ext(filename))); byExt != "" {
+			return byExt
+		}
+	}
+	return sniffed
+}
+
+// part renders the attachment as a base64 leaf with an attachment or inline disposition.
+func (a *Attachment) part(inline bool) (*mimePart, error) {
+	filename := path.Base(strings.ReplaceAll(a.Filename, `\`, "/"))
+	if filename == "." || filename == "/" || strings.ContainsAny(filename, "\r\n") {
+		return nil, fmt.Errorf("attachment filename %q: %w", a.Filename, ErrInvalidMessage)
+	}
+
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = DetectContentType(filename, a.Data)
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("attachment %s content type %q: %w", filename, contentType, ErrInvalidMessage)
+	}
+	params["name"] = filename
+
+	disposition := "attachment"
+	header := make(textproto.MIMEHeader, 4)
+	if inline {
+		if !validContentID(a.ContentID) {
+			return nil, fmt.Errorf("attachment %s content id %q: %w", filename, a.ContentID, ErrInvalidMessage)
+		}
+		disposition = "inline"
+		header.Set("Content-Id", "<"+a.ContentID+">")
+	}
+	header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": filename}))
+	header.Set("Content-Transfer-Encoding", "base64")
+	if header.Get("Content-Type") == "" || header.Get("Content-Disposition") == "" {
+		return nil, fmt.Errorf("attachment %s: %w", filename, ErrInvalidMessage)
+	}
+
+	return &mimePart{header: header, body: base64Lines(a.Data)}, nil
+}
+
+// validContentID accepts printable ASCII Content-IDs without whitespace or angle brackets.
+func validContentID(id string) bool {
+	if id == "" || len(id) > 255 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if c := id[i]; c <= ' ' || c >= 0x7f || c == '<' || c == '>' {
+			return false
+		}
+	}
+	return true
+}
+
+// base64Lines encodes data as base64 in 76-column CRLF lines (RFC 2045).
+func base64Lines(data []byte) []byte {
+	const width = 76
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var buf bytes.Buffer
+	buf.Grow(len(encoded) + len(encoded)/width*2 + 2)
+	for len(encoded) > width {
+		buf.WriteString(encoded[:width])
+		buf.WriteString("\r\n")
+		encoded = encoded[width:]
+	}
+	buf.WriteString(encoded)
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,336 @@
+package email
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DKIM signing (RFC 6376) with rsa-sha256 and ed25519-sha256 (RFC 8463), using
+// relaxed/relaxed canonicalization.
+
+// dkimSignedHeaders are signed when present. RFC 8058 requires List-Unsubscribe and
+// List-Unsubscribe-Post to be covered for one-click unsubscribe to be honored.
+var dkimSignedHeaders = []string{
+	"From", "Reply-To", "To", "Cc", "Subject", "Date", "Message-Id",
+	"Mime-Version", "Content-Type", "Content-Transfer-Encoding",
+	"List-Unsubscribe", "List-Unsubscribe-Post", HeaderNotificationID,
+}
+
+// DKIMKey is a private key published under selector._domainkey.domain.
+type DKIMKey struct {
+	Domain   string
+	Selector string
+	Signer   crypto.Signer // *rsa.PrivateKey or ed25519.PrivateKey
+}
+
+// ParseDKIMKey parses a PEM private key (PKCS#8, or PKCS#1 for RSA) for domain and selector.
+func ParseDKIMKey(domain, selector string, pemData []byte) (DKIMKey, error) {
+	key := DKIMKey{Domain: strings.ToLower(strings.TrimSuffix(domain, ".")), Selector: selector}
+	if key.Domain == "" || selector == "" {
+		return key, errors.New("dkim domain and selector are required")
+	}
+
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return key, errors.New("dkim key: no PEM block found")
+	}
+
+	var parsed any
+	var err error
+	if block.Type == "RSA PRIVATE KEY" {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return key, fmt.Errorf("dkim key: %w", err)
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 1024 {
+			return key, fmt.Errorf("dkim key: rsa key has %d bits, at least 1024 required", k.N.BitLen())
+		}
+		key.Signer = k
+	case ed25519.PrivateKey:
+		key.Signer = k
+	default:
+		return key, fmt.Errorf("dkim key: unsupported key type %T", parsed)
+	}
+	return key, nil
+}
+
+// algorithm returns the DKIM a= tag and key type (k=) for the key.
+func (k DKIMKey) algorithm() (string, string) {
+	if _, ok := k.Signer.(ed25519.PrivateKey); ok {
+		return "ed25519-sha256", "ed25519"
+	}
+	return "rsa-sha256", "rsa"
+}
+
+// DNSName returns the name of the TXT record holding the public key.
+func (k DKIMKey) DNSName() string {
+	return k.Selector + "._domainkey." + k.Domain
+}
+
+// DNSRecord returns the TXT record value to publish at DNSName: "v=DKIM1; k=rsa; p=<key>".
+// RSA keys are published as base64 SubjectPublicKeyInfo, Ed25519 keys as the raw 32-byte key.
+func (k DKIMKey) DNSRecord() (string, error) {
+	var pub []byte
+	switch signer := k.Signer.(type) {
+	case ed25519.PrivateKey:
+		pub = signer.Public().(ed25519.PublicKey)
+	default:
+		der, err := x509.MarshalPKIXPublicKey(signer.Public())
+		if err != nil {
+			return "", fmt.Errorf("marshal dkim public key: %w", err)
+		}
+		pub = der
+	}
+	_, keyType := k.algorithm()
+	return "v=DKIM1; k=" + keyType + "; p=" + base64.StdEncoding.EncodeToString(pub), nil
+}
+
+// DKIMSigner signs outgoing messages with the key of the From domain.
+type DKIMSigner struct {
+	keys map[string]DKIMKey // By domain
+}
+
+// NewDKIMSigner creates a signer from one key per domain.
+func NewDKIMSigner(keys []DKIMKey) (*DKIMSigner, error) {
+	s := &DKIMSigner{keys: make(map[string]DKIMKey, len(keys))}
+	for _, key := range keys {
+		if key.Signer == nil {
+			return nil, fmt.Errorf("dkim key for %s has no private key", key.Domain)
+		}
+		if _, dup := s.keys[key.Domain]; dup {
+			return nil, fmt.Errorf("duplicate dkim key for %s", key.Domain)
+		}
+		s.keys[key.Domain] = key
+	}
+	return s, nil
+}
+
+// Keys returns the configured keys.
+func (s *DKIMSigner) Keys() []DKIMKey {
+	keys := make([]DKIMKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// keyFor returns the key for the From domain or its closest parent domain.
+func (s *DKIMSigner) keyFor(domain string) (DKIMKey, bool) {
+	for d := strings.ToLower(domain); d != ""; {
+		if key, ok := s.keys[d]; ok {
+			return key, true
+		}
+		_, parent, found := strings.Cut(d, ".")
+		if !found || !strings.Contains(parent, ".") {
+			break
+		}
+		d = parent
+	}
+	return DKIMKey{}, false
+}
+
+// Sign prepends a DKIM-Signature header to a rendered message. Messages from a domain
+// without a key are returned unchanged.
+func (s *DKIMSigner) Sign(message []byte) ([]byte, error) {
+	headers, body, err := splitMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := mail.ParseAddress(unfold(lastHeader(headers, "From")))
+	if err != nil {
+		return nil, fmt.Errorf("dkim: from header: %w", ErrInvalidMessage)
+	}
+	_, domain, _ := strings.Cut(from.Address, "@")
+	key, ok := s.keyFor(domain)
+	if !ok {
+		return message, nil
+	}
+
+	bodyHash := sha256.Sum256(canonicalBodyRelaxed(body))
+
+	var names []string
+	for _, name := range dkimSignedHeaders {
+		for range headerValues(headers, name) {
+			names = append(names, strings.ToLower(name))
+		}
+	}
+
+	algorithm, _ := key.algorithm()
+	value := "v=1; a=" + algorithm + "; c=relaxed/relaxed; d=" + key.Domain + "; s=" + key.Selector +
+		";\r\n\tt=" + strconv.FormatInt(time.Now().Unix(), 10) +
+		"; h=" + strings.Join(names, ":") +
+		";\r\n\tbh=" + base64.StdEncoding.EncodeToString(bodyHash[:]) +
+		";\r\n\tb="
+
+	signature, err := signDKIM(key.Signer, dkimHeaderHash(headers, names, "DKIM-Signature: "+value))
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(message) + 512)
+	out.WriteString("DKIM-Signature: ")
+	out.WriteString(value)
+	out.WriteString(foldBase64(base64.StdEncoding.EncodeToString(signature)))
+	out.WriteString("\r\n")
+	out.Write(message)
+	return out.Bytes(), nil
+}
+
+// dkimHeaderHash hashes the signed headers and the DKIM-Signature header (with an empty
+// b= value and no trailing CRLF). Repeated headers are taken from the bottom up.
+func dkimHeaderHash(headers []string, names []string, signatureHeader string) []byte {
+	h := sha256.New()
+	used := make(map[string]int)
+	for _, name := range names {
+		values := headerValues(headers, name)
+		n := used[name]
+		used[name]++
+		if n >= len(values) {
+			continue // Signing an absent header instance is allowed and hashes nothing
+		}
+		h.Write([]byte(canonicalHeaderRelaxed(values[len(values)-1-n])))
+		h.Write([]byte("\r\n"))
+	}
+	h.Write([]byte(canonicalHeaderRelaxed(signatureHeader)))
+	return h.Sum(nil)
+}
+
+// signDKIM signs a header hash. Ed25519-SHA256 signs the SHA-256 hash with PureEdDSA (RFC 8463).
+func signDKIM(signer crypto.Signer, digest []byte) ([]byte, error) {
+	var sig []byte
+	var err error
+	if _, ok := signer.(ed25519.PrivateKey); ok {
+		sig, err = signer.Sign(rand.Reader, digest, crypto.Hash(0))
+	} else {
+		sig, err = signer.Sign(rand.Reader, digest, crypto.SHA256)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dkim sign: %w", err)
+	}
+	return sig, nil
+}
+
+// splitMessage splits a CRLF message into raw header fields (folded lines joined with
+// CRLF, no trailing CRLF) and the body.
+func splitMessage(message []byte) ([]string, []byte, error) {
+	end := bytes.Index(message, []byte("\r\n\r\n"))
+	if end < 0 {
+		return nil, nil, fmt.Errorf("dkim: message has no header/body separator: %w", ErrInvalidMessage)
+	}
+
+	var fields []string
+	for _, line := range strings.Split(string(message[:end]), "\r\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(fields) > 0 {
+			fields[len(fields)-1] += "\r\n" + line
+			continue
+		}
+		fields = append(fields, line)
+	}
+	return fields, message[end+4:], nil
+}
+
+// headerValues returns the raw fields named name, in message order.
+func headerValues(fields []string, name string) []string {
+	var values []string
+	for _, field := range fields {
+		if k, _, ok := strings.Cut(field, ":"); ok && strings.EqualFold(strings.TrimRight(k, " \t"), name) {
+			values = append(values, field)
+		}
+	}
+	return values
+}
+
+// lastHeader returns the value of the last field named name.
+func lastHeader(fields []string, name string) string {
+	values := headerValues(fields, name)
+	if len(values) == 0 {
+		return ""
+	}
+	_, v, _ := strings.Cut(values[len(values)-1], ":")
+	return v
+}
+
+// canonicalHeaderRelaxed applies relaxed header canonicalization (RFC 6376 3.4.2):
+// lower-case name, unfold, collapse whitespace, trim around the colon and the end.
+func canonicalHeaderRelaxed(field string) string {
+	name, value, _ := strings.Cut(field, ":")
+	return strings.ToLower(strings.TrimRight(name, " \t")) + ":" + collapseWSP(unfold(value))
+}
+
+// canonicalBodyRelaxed applies relaxed body canonicalization (RFC 6376 3.4.4).
+func canonicalBodyRelaxed(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(collapseWSPKeepLeading(line), " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+func unfold(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "")
+}
+
+// collapseWSP reduces whitespace runs to one space and trims both ends.
+func collapseWSP(s string) string {
+	return strings.TrimSpace(collapseWSPKeepLeading(s))
+}
+
+// collapseWSPKeepLeading reduces runs of spaces and tabs to a single space.
+func collapseWSPKeepLeading(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	inWSP := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == ' ' || c == '\t' {
+			if !inWSP {
+				b.WriteByte(' ')
+			}
+			inWSP = true
+			continue
+		}
+		inWSP = false
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// foldBase64 folds a long base64 value so header lines stay under the 78-column limit.
+func foldBase64(s string) string {
+	const width = 72
+	var b strings.Builder
+	for len(s) > width {
+		b.WriteString(s[:width])
+		b.WriteString("\r\n\t ")
+		s = s[width:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
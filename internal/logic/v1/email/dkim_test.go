@@ -0,0 +1,213 @@
+package email
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const dkimTestMessage = "From: Shop <orders@example.com>\r\n" +
+	"To: someone@example.net\r\n" +
+	"Subject: Your order   has shipped\r\n" +
+	"Date: Mon, 05 Oct 2026 10:00:00 +0000\r\n" +
+	"Message-Id: <1@example.com>\r\n" +
+	"\r\n" +
+	"Hello,  \r\n" +
+	"\r\n" +
+	"your order\t is on its way.\r\n" +
+	"\r\n" +
+	"\r\n"
+
+var (
+	wspRun       = regexp.MustCompile(`[ \t]+`)
+	signatureTag = regexp.MustCompile(`(^|;)(\s*b\s*=)[^;]*`)
+)
+
+// verifyDKIM verifies the first DKIM-Signature of message against a DNS TXT record, as a
+// receiver would (RFC 6376 §6), for relaxed/relaxed signatures.
+func verifyDKIM(message []byte, txtRecord string) error {
+	headerEnd := bytes.Index(message, []byte("\r\n\r\n"))
+	if headerEnd < 0 {
+		return errors.New("no header/body separator")
+	}
+	var fields []string
+	for line := range strings.SplitSeq(string(message[:headerEnd]), "\r\n") {
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			fields[len(fields)-1] += "\r\n" + line
+			continue
+		}
+		fields = append(fields, line)
+	}
+	body := string(message[headerEnd+4:])
+
+	if len(fields) == 0 || !strings.HasPrefix(strings.ToLower(fields[0]), "dkim-signature:") {
+		return errors.New("message does not start with a DKIM-Signature")
+	}
+	signatureField := fields[0]
+	tags := parseTags(signatureField[len("DKIM-Signature:"):])
+	if tags["c"] != "relaxed/relaxed" {
+		return fmt.Errorf("c=%s, want relaxed/relaxed", tags["c"])
+	}
+
+	// Body hash
+	lines := strings.Split(body, "\r\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(wspRun.ReplaceAllString(line, " "), " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	canonicalBody := ""
+	if len(lines) > 0 {
+		canonicalBody = strings.Join(lines, "\r\n") + "\r\n"
+	}
+	bodyHash := sha256.Sum256([]byte(canonicalBody))
+	if got := base64.StdEncoding.EncodeToString(bodyHash[:]); got != tags["bh"] {
+		return fmt.Errorf("body hash %s does not match bh=%s", got, tags["bh"])
+	}
+
+	// Header hash: signed fields bottom-up, then the signature field with an empty b=
+	canonical := func(field string) string {
+		name, value, _ := strings.Cut(field, ":")
+		value = strings.TrimSpace(wspRun.ReplaceAllString(strings.ReplaceAll(value, "\r\n", ""), " "))
+		return strings.ToLower(strings.TrimSpace(name)) + ":" + value
+	}
+	h := sha256.New()
+	used := map[string]int{}
+	for name := range strings.SplitSeq(tags["h"], ":") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		var matches []string
+		for _, field := range fields[1:] {
+			if k, _, _ := strings.Cut(field, ":"); strings.EqualFold(strings.TrimSpace(k), name) {
+				matches = append(matches, field)
+			}
+		}
+		if n := used[name]; n < len(matches) {
+			h.Write([]byte(canonical(matches[len(matches)-1-n]) + "\r\n"))
+		}
+		used[name]++
+	}
+	h.Write([]byte(canonical(signatureTag.ReplaceAllString(signatureField, "$1$2"))))
+	headerHash := h.Sum(nil)
+
+	signature, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		return fmt.Errorf("decode b=: %w", err)
+	}
+	record := parseTags(txtRecord)
+	publicKey, err := base64.StdEncoding.DecodeString(record["p"])
+	if err != nil {
+		return fmt.Errorf("decode p=: %w", err)
+	}
+
+	switch tags["a"] {
+	case "rsa-sha256":
+		if record["k"] != "rsa" {
+			return fmt.Errorf("k=%s for a=rsa-sha256", record["k"])
+		}
+		pub, err := x509.ParsePKIXPublicKey(publicKey)
+		if err != nil {
+			return fmt.Errorf("parse p=: %w", err)
+		}
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("p= is a %T, want an RSA key", pub)
+		}
+		return rsa.VerifyPKCS1v15(rsaPub, crypto.SHA256, headerHash, signature)
+	case "ed25519-sha256":
+		if record["k"] != "ed25519" || len(publicKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("k=%s with a %d-byte p= for a=ed25519-sha256", record["k"], len(publicKey))
+		}
+		if !ed25519.Verify(publicKey, headerHash, signature) {
+			return errors.New("ed25519 signature does not verify")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported a=%s", tags["a"])
+	}
+}
+
+// parseTags parses a tag=value list (RFC 6376 §3.2), removing folding whitespace.
+func parseTags(list string) map[string]string {
+	tags := map[string]string{}
+	for tag := range strings.SplitSeq(list, ";") {
+		name, value, ok := strings.Cut(tag, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Map(func(r rune) rune {
+			if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+				return -1
+			}
+			return r
+		}, value)
+		tags[strings.TrimSpace(name)] = value
+	}
+	return tags
+}
+
+func TestDKIMSignerVerifiesAgainstDNSRecord(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		signer    crypto.Signer
+		algorithm string
+	}{
+		{name: "rsa-sha256", signer: rsaKey, algorithm: "rsa-sha256"},
+		{name: "ed25519-sha256", signer: edKey, algorithm: "ed25519-sha256"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := DKIMKey{Domain: "example.com", Selector: "s1", Signer: tt.signer}
+			signer, err := NewDKIMSigner([]DKIMKey{key})
+			if err != nil {
+				t.Fatal(err)
+			}
+			record, err := key.DNSRecord()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			signed, err := signer.Sign([]byte(dkimTestMessage))
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			signatureField, _, _ := bytes.Cut(signed, []byte("\r\nFrom:"))
+			if tags := parseTags(strings.TrimPrefix(string(signatureField), "DKIM-Signature:")); tags["a"] != tt.algorithm {
+				t.Errorf("a=%s, want %s", tags["a"], tt.algorithm)
+			}
+			if err := verifyDKIM(signed, record); err != nil {
+				t.Fatalf("signature does not verify against %q: %v", record, err)
+			}
+
+			// Relaxed canonicalization tolerates whitespace changes in transit
+			refolded := bytes.Replace(signed, []byte("Subject: Your order   has shipped"), []byte("Subject:  Your order\r\n\thas shipped"), 1)
+			if err := verifyDKIM(refolded, record); err != nil {
+				t.Errorf("refolded header no longer verifies: %v", err)
+			}
+
+			tampered := bytes.Replace(signed, []byte("Subject: Your order   has shipped"), []byte("Subject: Your order was cancelled"), 1)
+			if err := verifyDKIM(tampered, record); err == nil {
+				t.Error("signature still verifies after the Subject was changed")
+			}
+		})
+	}
+}
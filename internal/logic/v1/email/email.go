@@ -0,0 +1,34 @@
+// Package email provides email channel helpers for API version 1.
+//
+// It covers message rendering, delivery through SMTP relays with failover to backup
+// relays, one-click unsubscribe tokens and List-Unsubscribe headers (RFC 8058), address
+// normalization, and parsing of the bounce and complaint reports that receiving servers
+// return (RFC 3464 delivery status notifications and RFC 5965 abuse feedback reports).
+package email
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// HeaderNotificationID carries the notification ID on outgoing messages so bounce
+// reports that include the original headers can be matched back to the notification.
+const HeaderNotificationID = "X-Notification-Id"
+
+// NormalizeAddress returns the bare, lower-cased address of an email address, accepting
+// both "user@example.com" and "Name <user@example.com>". It returns "" if addr is not
+// a valid address.
+//
+// The local part is lower-cased too: it is case-sensitive per RFC 5321, but no
+// mainstream provider treats it that way and suppression must not be bypassed by case.
+func NormalizeAddress(addr string) string {
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return ""
+	}
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Address)
+}
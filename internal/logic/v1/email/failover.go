@@ -0,0 +1,73 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/health"
+	"github.com/duynhne/notification-service/internal/logic/v1/throttle"
+)
+
+// Relay is a named sender in a failover chain.
+type Relay struct {
+	Name   string // Provider name in metrics and the status endpoint
+	Sender Sender
+}
+
+// FailoverSender sends each message through the first relay, in order, whose circuit
+// breaker admits it and moves on to the next relay when a send fails. A message the
+// relay rejected as invalid or undeliverable is not retried elsewhere. Sends are
+// paced per relay by a throttle.Limiter.
+type FailoverSender struct {
+	relays   []Relay
+	breakers []*health.Breaker
+	limiter  *throttle.Limiter
+}
+
+// NewFailoverSender creates a sender over relays, primary first. Breakers come from
+// registry under the "email" channel; registry and limiter may be nil to fail over
+// without breakers or pacing.
+func NewFailoverSender(registry *health.Registry, limiter *throttle.Limiter, relays []Relay) (*FailoverSender, error) {
+	if len(relays) == 0 {
+		return nil, errors.New("at least one email relay is required")
+	}
+	breakers := make([]*health.Breaker, len(relays))
+	for i, relay := range relays {
+		breakers[i] = registry.Breaker("email", relay.Name)
+	}
+	return &FailoverSender{relays: relays, breakers: breakers, limiter: limiter}, nil
+}
+
+// Send implements Sender.
+func (f *FailoverSender) Send(ctx context.Context, msg *Message) error {
+	var errs []error
+	for i, relay := range f.relays {
+		err := f.limiter.Do(ctx, "email", relay.Name, func() error {
+			return f.breakers[i].Call(func() error {
+				return relay.Sender.Send(ctx, msg)
+			}, isRelayFault)
+		})
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, throttle.ErrExpired) {
+			return fmt.Errorf("%s: %w", relay.Name, err)
+		}
+		// A relay that stays rate limited hands over like a failing one
+		if !errors.Is(err, health.ErrCircuitOpen) && !errors.Is(err, throttle.ErrRateLimited) && !isRelayFault(err) {
+			return err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", relay.Name, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return fmt.Errorf("all email relays failed: %w", errors.Join(errs...))
+}
+
+// isRelayFault reports whether a send error is the relay's fault rather than the
+// message's. Rate limits are not faults: the relay is up and the Limiter backs off.
+func isRelayFault(err error) bool {
+	return !errors.Is(err, ErrInvalidMessage) && !errors.Is(err, ErrRejected) && !errors.Is(err, throttle.ErrRateLimited)
+}
@@ -0,0 +1,263 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidMessage indicates a message cannot be rendered (bad address, header injection).
+var ErrInvalidMessage = errors.New("invalid email message")
+
+// Sender delivers a rendered email message.
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Message is an email with a plain-text and/or HTML body and optional attachments.
+//
+// The body is rendered as the smallest MIME tree that holds it:
+//
+//	multipart/mixed                 (when there are attachments)
+//	  multipart/related             (when inline images are referenced from HTML)
+//	    multipart/alternative       (when there are both Text and HTML)
+//	      text/plain
+//	      text/html
+//	    inline images
+//	  attachments
+type Message struct {
+	From        string               // RFC 5322 address; empty uses the sender's default
+	ReplyTo     string               // Reply-To address (optional)
+	To          []string             // Recipient addresses
+	Cc          []string             // Carbon copy addresses
+	Bcc         []string             // Blind carbon copy addresses, never rendered
+	Subject     string               // UTF-8 subject, Q-encoded when rendered
+	Text        string               // UTF-8 plain-text body
+	HTML        string               // UTF-8 HTML body; inline images are referenced as cid:<ContentID>
+	Attachments []Attachment         // Files and inline images
+	Header      textproto.MIMEHeader // Additional headers (e.g. List-Unsubscribe)
+}
+
+// Recipients returns the bare envelope addresses of all recipients (To, Cc and Bcc),
+// without duplicates.
+func (m *Message) Recipients() ([]string, error) {
+	rcpts := make([]string, 0, len(m.To)+len(m.Cc)+len(m.Bcc))
+	seen := make(map[string]bool, cap(rcpts))
+	for _, list := range [][]string{m.To, m.Cc, m.Bcc} {
+		for _, rcpt := range list {
+			addr, err := mail.ParseAddress(rcpt)
+			if err != nil {
+				return nil, fmt.Errorf("recipient %q: %w", rcpt, ErrInvalidMessage)
+			}
+			if key := NormalizeAddress(addr.Address); !seen[key] {
+				seen[key] = true
+				rcpts = append(rcpts, addr.Address)
+			}
+		}
+	}
+	if len(rcpts) == 0 {
+		return nil, fmt.Errorf("no recipients: %w", ErrInvalidMessage)
+	}
+	return rcpts, nil
+}
+
+// Bytes renders the message in RFC 5322 format with CRLF line endings. Date and
+// Message-Id are generated unless set in Header.
+func (m *Message) Bytes() ([]byte, error) {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return nil, fmt.Errorf("from %q: %w", m.From, ErrInvalidMessage)
+	}
+	if _, err := m.Recipients(); err != nil {
+		return nil, err
+	}
+
+	body, err := m.body()
+	if err != nil {
+		return nil, err
+	}
+
+	header := make(textproto.MIMEHeader, len(m.Header)+12)
+	for k, v := range m.Header {
+		header[textproto.CanonicalMIMEHeaderKey(k)] = v
+	}
+	header.Set("From", from.String())
+	if len(m.To) > 0 {
+		header.Set("To", strings.Join(m.To, ", "))
+	} else {
+		header.Set("To", "undisclosed-recipients:;")
+	}
+	if len(m.Cc) > 0 {
+		header.Set("Cc", strings.Join(m.Cc, ", "))
+	}
+	if m.ReplyTo != "" {
+		replyTo, err := mail.ParseAddress(m.ReplyTo)
+		if err != nil {
+			return nil, fmt.Errorf("reply-to %q: %w", m.ReplyTo, ErrInvalidMessage)
+		}
+		header.Set("Reply-To", replyTo.String())
+	}
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header.Set("Mime-Version", "1.0")
+	for k, v := range body.header {
+		header[k] = v
+	}
+	if header.Get("Date") == "" {
+		header.Set("Date", time.Now().Format(time.RFC1123Z))
+	}
+	if header.Get("Message-Id") == "" {
+		id, err := messageID(from.Address)
+		if err != nil {
+			return nil, err
+		}
+		header.Set("Message-Id", id)
+	}
+
+	var buf bytes.Buffer
+	if err := writeHeader(&buf, header); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\r\n")
+	if err := body.writeBody(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// body builds the MIME tree for the message content.
+func (m *Message) body() (*mimePart, error) {
+	var content *mimePart
+	switch {
+	case m.HTML == "":
+		content = textPart("text/plain", m.Text)
+	case m.Text == "":
+		content = textPart("text/html", m.HTML)
+	default:
+		content = multipartOf("alternative", textPart("text/plain", m.Text), textPart("text/html", m.HTML))
+	}
+
+	var inline, attached []*mimePart
+	for i := range m.Attachments {
+		a := &m.Attachments[i]
+		// Inline images are only reachable from an HTML body; otherwise attach them.
+		asInline := a.ContentID != "" && m.HTML != ""
+		part, err := a.part(asInline)
+		if err != nil {
+			return nil, err
+		}
+		if asInline {
+			inline = append(inline, part)
+		} else {
+			attached = append(attached, part)
+		}
+	}
+
+	if len(inline) > 0 {
+		content = multipartOf("related", append([]*mimePart{content}, inline...)...)
+	}
+	if len(attached) > 0 {
+		content = multipartOf("mixed", append([]*mimePart{content}, attached...)...)
+	}
+	return content, nil
+}
+
+// mimePart is a node of a MIME tree: a leaf with an encoded body or a multipart
+// container.
+type mimePart struct {
+	header   textproto.MIMEHeader
+	body     []byte // Encoded leaf body
+	boundary string
+	children []*mimePart
+}
+
+// textPart creates a quoted-printable UTF-8 text leaf.
+func textPart(mediaType, text string) *mimePart {
+	var buf bytes.Buffer
+	qp := quotedprintable.NewWriter(&buf)
+	_, _ = qp.Write([]byte(text)) // Writes to a bytes.Buffer cannot fail
+	_ = qp.Close()
+
+	header := make(textproto.MIMEHeader, 2)
+	header.Set("Content-Type", mediaType+"; charset=utf-8")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	return &mimePart{header: header, body: buf.Bytes()}
+}
+
+// multipartOf creates a multipart/<subtype> container.
+func multipartOf(subtype string, children ...*mimePart) *mimePart {
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	header := make(textproto.MIMEHeader, 1)
+	header.Set("Content-Type", mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": boundary}))
+	return &mimePart{header: header, boundary: boundary, children: children}
+}
+
+// writeBody writes the part body, recursing into multipart children.
+func (p *mimePart) writeBody(w io.Writer) error {
+	if p.children == nil {
+		_, err := w.Write(p.body)
+		return err
+	}
+
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(p.boundary); err != nil {
+		return fmt.Errorf("mime boundary: %w", err)
+	}
+	for _, child := range p.children {
+		pw, err := mw.CreatePart(child.header)
+		if err != nil {
+			return fmt.Errorf("mime part: %w", err)
+		}
+		if err := child.writeBody(pw); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// writeHeader writes header fields in a stable order, rejecting values that would
+// inject additional header lines.
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) error {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		for _, v := range header[k] {
+			if strings.ContainsAny(v, "\r\n") {
+				return fmt.Errorf("header %s contains a line break: %w", k, ErrInvalidMessage)
+			}
+			buf.WriteString(k)
+			buf.WriteString(": ")
+			buf.WriteString(v)
+			buf.WriteString("\r\n")
+		}
+	}
+	return nil
+}
+
+// messageID generates a unique Message-Id in the sender's domain.
+func messageID(from string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate message id: %w", err)
+	}
+	_, domain, _ := strings.Cut(from, "@")
+	if domain == "" {
+		domain = "localhost"
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">", nil
+}
@@ -0,0 +1,198 @@
+package email
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// ErrNotReport indicates a message is not a multipart/report delivery status
+// notification or feedback report.
+var ErrNotReport = errors.New("not a delivery status or feedback report")
+
+// ReportType identifies the kind of machine-readable report in a multipart/report message.
+type ReportType string
+
+const (
+	ReportDeliveryStatus ReportType = "delivery-status" // RFC 3464 bounce or delay notification
+	ReportFeedback       ReportType = "feedback-report" // RFC 5965 abuse (spam) complaint
+)
+
+// Report is a parsed multipart/report message.
+type Report struct {
+	Type       ReportType
+	Recipients []ReportRecipient
+	// OriginalHeaders are the headers of the message the report refers to, when the
+	// reporting server returned them (text/rfc822-headers or message/rfc822 part).
+	OriginalHeaders textproto.MIMEHeader
+}
+
+// ReportRecipient is the per-recipient part of a report.
+type ReportRecipient struct {
+	Address    string // Normalized recipient address
+	Action     string // DSN action: failed, delayed, delivered, relayed or expanded (empty for feedback reports)
+	Status     string // Enhanced status code, e.g. "5.1.1" (empty for feedback reports)
+	Diagnostic string // Remote server diagnostic, or the feedback type for complaints
+}
+
+// IsHardBounce reports whether delivery failed permanently (5.x.x status).
+func (r ReportRecipient) IsHardBounce() bool {
+	return r.Action == "failed" && !strings.HasPrefix(r.Status, "4.")
+}
+
+// IsSoftBounce reports whether delivery failed or was delayed for a transient reason.
+func (r ReportRecipient) IsSoftBounce() bool {
+	return r.Action == "delayed" || (r.Action == "failed" && strings.HasPrefix(r.Status, "4."))
+}
+
+// ParseReport parses a raw RFC 5322 message containing a delivery status notification
+// or an abuse feedback report.
+func ParseReport(r io.Reader) (*Report, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("read report message: %w", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" || params["boundary"] == "" {
+		return nil, ErrNotReport
+	}
+
+	report := &Report{}
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read report part: %w", err)
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/delivery-status", "message/global-delivery-status":
+			report.Type = ReportDeliveryStatus
+			if err := parseDeliveryStatus(part, report); err != nil {
+				return nil, err
+			}
+		case "message/feedback-report":
+			report.Type = ReportFeedback
+			if err := parseFeedbackReport(part, report); err != nil {
+				return nil, err
+			}
+		case "text/rfc822-headers", "message/rfc822", "message/global", "message/global-headers":
+			header, err := readFieldGroup(textproto.NewReader(bufio.NewReader(part)))
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("read original headers: %w", err)
+			}
+			report.OriginalHeaders = header
+		}
+	}
+
+	if report.Type == "" {
+		return nil, ErrNotReport
+	}
+
+	// Feedback reports often omit Original-Rcpt-To; fall back to the original To header.
+	if report.Type == ReportFeedback && len(report.Recipients) == 0 && report.OriginalHeaders != nil {
+		if addr := NormalizeAddress(report.OriginalHeaders.Get("To")); addr != "" {
+			report.Recipients = append(report.Recipients, ReportRecipient{Address: addr, Diagnostic: "abuse"})
+		}
+	}
+
+	return report, nil
+}
+
+// parseDeliveryStatus reads the message/delivery-status body: a per-message field group
+// followed by one field group per recipient, separated by blank lines.
+func parseDeliveryStatus(r io.Reader, report *Report) error {
+	groups, err := readFieldGroups(r)
+	if err != nil {
+		return fmt.Errorf("read delivery status: %w", err)
+	}
+
+	for _, group := range groups {
+		recipient := typedValue(group.Get("Final-Recipient"))
+		if recipient == "" {
+			recipient = typedValue(group.Get("Original-Recipient"))
+		}
+		if recipient == "" {
+			continue // Per-message fields
+		}
+
+		status, _, _ := strings.Cut(strings.TrimSpace(group.Get("Status")), " ")
+		report.Recipients = append(report.Recipients, ReportRecipient{
+			Address:    NormalizeAddress(recipient),
+			Action:     strings.ToLower(strings.TrimSpace(group.Get("Action"))),
+			Status:     status,
+			Diagnostic: typedValue(group.Get("Diagnostic-Code")),
+		})
+	}
+	return nil
+}
+
+// parseFeedbackReport reads the message/feedback-report body (a single field group).
+func parseFeedbackReport(r io.Reader, report *Report) error {
+	groups, err := readFieldGroups(r)
+	if err != nil {
+		return fmt.Errorf("read feedback report: %w", err)
+	}
+
+	for _, group := range groups {
+		feedbackType := strings.ToLower(strings.TrimSpace(group.Get("Feedback-Type")))
+		if feedbackType == "" {
+			feedbackType = "abuse"
+		}
+		for _, rcpt := range group.Values("Original-Rcpt-To") {
+			if addr := NormalizeAddress(rcpt); addr != "" {
+				report.Recipients = append(report.Recipients, ReportRecipient{Address: addr, Diagnostic: feedbackType})
+			}
+		}
+	}
+	return nil
+}
+
+// readFieldGroups reads blank-line separated groups of header fields until EOF.
+func readFieldGroups(r io.Reader) ([]textproto.MIMEHeader, error) {
+	tp := textproto.NewReader(bufio.NewReader(r))
+	var groups []textproto.MIMEHeader
+	for {
+		group, err := readFieldGroup(tp)
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+		if errors.Is(err, io.EOF) {
+			return groups, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readFieldGroup reads one group of header fields. A group cut short by EOF (no
+// trailing blank line) is returned together with io.EOF.
+func readFieldGroup(tp *textproto.Reader) (textproto.MIMEHeader, error) {
+	header, err := tp.ReadMIMEHeader()
+	if err != nil && len(header) == 0 {
+		if _, peekErr := tp.R.Peek(1); errors.Is(peekErr, io.EOF) {
+			return nil, io.EOF
+		}
+	}
+	return header, err
+}
+
+// typedValue returns the value of a "type; value" field such as "rfc822; user@example.com".
+func typedValue(field string) string {
+	if _, value, ok := strings.Cut(field, ";"); ok {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(field)
+}
@@ -0,0 +1,284 @@
+package email
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Samples of what mail servers send back, trimmed to the parts the parser reads.
+const (
+	hardBounceReport = `From: MAILER-DAEMON@mx.example.com (Mail Delivery System)
+To: bounces@notify.example.com
+Subject: Undelivered Mail Returned to Sender
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=delivery-status;
+	boundary="B1.1700000000/mx.example.com"
+
+--B1.1700000000/mx.example.com
+Content-Type: text/plain; charset=us-ascii
+
+This is the mail system at host mx.example.com.
+I'm sorry to have to inform you that your message could not
+be delivered to one or more recipients.
+
+--B1.1700000000/mx.example.com
+Content-Type: message/delivery-status
+
+Reporting-MTA: dns; mx.example.com
+X-Postfix-Queue-ID: 4F2A81C0042
+Arrival-Date: Mon,  5 Oct 2026 10:00:00 +0000 (UTC)
+
+Final-Recipient: rfc822; Alice@Example.org
+Original-Recipient: rfc822;alice@example.org
+Action: failed
+Status: 5.1.1
+Remote-MTA: dns; mail.example.org
+Diagnostic-Code: smtp; 550 5.1.1 <alice@example.org>: Recipient address rejected:
+    User unknown in virtual mailbox table
+
+--B1.1700000000/mx.example.com
+Content-Type: text/rfc822-headers
+
+From: Shop <orders@notify.example.com>
+To: alice@example.org
+Subject: Your order has shipped
+X-Notification-ID: 0192f0c4-7a1e-7cc3-9b2e-3c1f6a2d8e10
+
+--B1.1700000000/mx.example.com--
+`
+
+	softBounceReport = `From: postmaster@mx.example.com
+To: bounces@notify.example.com
+Subject: Delivery Status Notification (Delay)
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=delivery-status; boundary="delay"
+
+--delay
+Content-Type: text/plain
+
+Delivery to the following recipients has been delayed: bob@example.net
+
+--delay
+Content-Type: message/delivery-status
+
+Reporting-MTA: dns; mx.example.com
+
+Final-Recipient: rfc822; bob@example.net
+Action: delayed
+Status: 4.4.7
+Diagnostic-Code: smtp; 421 4.4.7 Message delayed, will retry
+
+Final-Recipient: rfc822; carol@example.net
+Action: failed
+Status: 4.2.2 (mailbox full)
+Diagnostic-Code: smtp; 452 4.2.2 Mailbox full
+
+--delay--
+`
+
+	abuseReport = `From: <staff@hotmail.example>
+To: <abuse@notify.example.com>
+Subject: FW: Your order has shipped
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=feedback-report;
+	boundary="part1_13d.2e68ed54_boundary"
+
+--part1_13d.2e68ed54_boundary
+Content-Type: text/plain; charset="US-ASCII"
+Content-Transfer-Encoding: 7bit
+
+This is an email abuse report for an email message received from IP
+192.0.2.1 on Thu, 8 Mar 2026 14:00:00 EDT.
+
+--part1_13d.2e68ed54_boundary
+Content-Type: message/feedback-report
+
+Feedback-Type: abuse
+User-Agent: SomeGenerator/1.0
+Version: 1
+Original-Mail-From: <bounces@notify.example.com>
+Original-Rcpt-To: <Dave@Example.com>
+Arrival-Date: Thu, 8 Mar 2026 14:00:00 EDT
+Source-IP: 192.0.2.1
+
+--part1_13d.2e68ed54_boundary
+Content-Type: message/rfc822
+Content-Disposition: inline
+
+From: Shop <orders@notify.example.com>
+To: dave@example.com
+Subject: Your order has shipped
+X-Notification-ID: 0192f0c4-7a1e-7cc3-9b2e-3c1f6a2d8e11
+
+Your order is on its way.
+
+--part1_13d.2e68ed54_boundary--
+`
+)
+
+// crlf converts a sample to the CRLF line endings used on the wire.
+func crlf(s string) string {
+	return strings.ReplaceAll(s, "\n", "\r\n")
+}
+
+func TestParseReport(t *testing.T) {
+	tests := []struct {
+		name             string
+		message          string
+		wantErr          error // Sentinel the error must wrap
+		wantAnyErr       bool  // Any error will do
+		wantType         ReportType
+		wantRecipients   []ReportRecipient
+		wantNotification string // X-Notification-ID of the original headers
+	}{
+		{
+			name:     "hard bounce",
+			message:  crlf(hardBounceReport),
+			wantType: ReportDeliveryStatus,
+			wantRecipients: []ReportRecipient{{
+				Address: "alice@example.org",
+				Action:  "failed",
+				Status:  "5.1.1",
+				Diagnostic: "550 5.1.1 <alice@example.org>: Recipient address rejected: " +
+					"User unknown in virtual mailbox table",
+			}},
+			wantNotification: "0192f0c4-7a1e-7cc3-9b2e-3c1f6a2d8e10",
+		},
+		{
+			name:     "soft bounce",
+			message:  crlf(softBounceReport),
+			wantType: ReportDeliveryStatus,
+			wantRecipients: []ReportRecipient{
+				{Address: "bob@example.net", Action: "delayed", Status: "4.4.7", Diagnostic: "421 4.4.7 Message delayed, will retry"},
+				{Address: "carol@example.net", Action: "failed", Status: "4.2.2", Diagnostic: "452 4.2.2 Mailbox full"},
+			},
+		},
+		{
+			name:             "abuse report",
+			message:          crlf(abuseReport),
+			wantType:         ReportFeedback,
+			wantRecipients:   []ReportRecipient{{Address: "dave@example.com", Diagnostic: "abuse"}},
+			wantNotification: "0192f0c4-7a1e-7cc3-9b2e-3c1f6a2d8e11",
+		},
+		{
+			name:             "abuse report without Original-Rcpt-To",
+			message:          crlf(strings.Replace(abuseReport, "Original-Rcpt-To: <Dave@Example.com>\n", "", 1)),
+			wantType:         ReportFeedback,
+			wantRecipients:   []ReportRecipient{{Address: "dave@example.com", Diagnostic: "abuse"}},
+			wantNotification: "0192f0c4-7a1e-7cc3-9b2e-3c1f6a2d8e11",
+		},
+		{
+			name:             "LF line endings",
+			message:          hardBounceReport,
+			wantType:         ReportDeliveryStatus,
+			wantRecipients:   []ReportRecipient{{Address: "alice@example.org", Action: "failed", Status: "5.1.1"}},
+			wantNotification: "0192f0c4-7a1e-7cc3-9b2e-3c1f6a2d8e10",
+		},
+		{
+			name:     "missing Final-Recipient",
+			message:  crlf(strings.Replace(hardBounceReport, "Final-Recipient: rfc822; Alice@Example.org\n", "", 1)),
+			wantType: ReportDeliveryStatus,
+			// Original-Recipient stands in for it
+			wantRecipients:   []ReportRecipient{{Address: "alice@example.org", Action: "failed", Status: "5.1.1"}},
+			wantNotification: "0192f0c4-7a1e-7cc3-9b2e-3c1f6a2d8e10",
+		},
+		{
+			name: "no recipient fields",
+			message: crlf(strings.NewReplacer(
+				"Final-Recipient: rfc822; Alice@Example.org\n", "",
+				"Original-Recipient: rfc822;alice@example.org\n", "",
+			).Replace(hardBounceReport)),
+			wantType:         ReportDeliveryStatus,
+			wantNotification: "0192f0c4-7a1e-7cc3-9b2e-3c1f6a2d8e10",
+		},
+		{
+			name:    "not a report",
+			message: crlf("From: alice@example.org\nContent-Type: text/plain\n\nOut of office until Monday.\n"),
+			wantErr: ErrNotReport,
+		},
+		{
+			name:    "report without a status part",
+			message: crlf(strings.Replace(softBounceReport, "message/delivery-status", "text/plain", 1)),
+			wantErr: ErrNotReport,
+		},
+		{
+			name:    "report without a boundary",
+			message: crlf("Content-Type: multipart/report; report-type=delivery-status\n\nbody\n"),
+			wantErr: ErrNotReport,
+		},
+		{
+			name:       "truncated multipart body",
+			message:    crlf(strings.TrimSuffix(softBounceReport, "--delay--\n")),
+			wantAnyErr: true,
+		},
+		{
+			name:       "no header",
+			message:    "\x00\x01 not a message",
+			wantAnyErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := ParseReport(strings.NewReader(tt.message))
+			if tt.wantErr != nil || tt.wantAnyErr {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("ParseReport error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseReport: %v", err)
+			}
+			if report.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", report.Type, tt.wantType)
+			}
+			if len(report.Recipients) != len(tt.wantRecipients) {
+				t.Fatalf("Recipients = %+v, want %+v", report.Recipients, tt.wantRecipients)
+			}
+			for i, want := range tt.wantRecipients {
+				got := report.Recipients[i]
+				if want.Diagnostic == "" && report.Type == ReportDeliveryStatus {
+					got.Diagnostic = "" // Not what the case is about
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("Recipients[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+			if got := report.OriginalHeaders.Get("X-Notification-ID"); got != tt.wantNotification {
+				t.Errorf("original X-Notification-ID = %q, want %q", got, tt.wantNotification)
+			}
+		})
+	}
+}
+
+func TestReportRecipientBounce(t *testing.T) {
+	tests := []struct {
+		action, status string
+		wantHard       bool
+		wantSoft       bool
+	}{
+		{"failed", "5.1.1", true, false},  // Unknown user
+		{"failed", "5.7.1", true, false},  // Rejected by policy
+		{"failed", "", true, false},       // No status: a failure is permanent unless it says otherwise
+		{"failed", "4.2.2", false, true},  // Mailbox full, given up on by the reporting server
+		{"delayed", "4.4.7", false, true}, // Still retrying
+		{"delayed", "", false, true},
+		{"delivered", "2.0.0", false, false},
+		{"relayed", "2.0.0", false, false},
+		{"expanded", "2.0.0", false, false},
+		{"", "", false, false}, // Feedback report recipient
+	}
+	for _, tt := range tests {
+		t.Run(tt.action+" "+tt.status, func(t *testing.T) {
+			rcpt := ReportRecipient{Address: "alice@example.org", Action: tt.action, Status: tt.status}
+			if got := rcpt.IsHardBounce(); got != tt.wantHard {
+				t.Errorf("IsHardBounce = %v, want %v", got, tt.wantHard)
+			}
+			if got := rcpt.IsSoftBounce(); got != tt.wantSoft {
+				t.Errorf("IsSoftBounce = %v, want %v", got, tt.wantSoft)
+			}
+		})
+	}
+}
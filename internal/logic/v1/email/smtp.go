@@ -0,0 +1,183 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/throttle"
+)
+
+// ErrRejected indicates the relay refused a recipient or the message content with a
+// permanent (5xx) reply. Sending the same message through another relay will not help.
+var ErrRejected = errors.New("rejected by smtp relay")
+
+// SMTPConfig configures the SMTP relay client.
+type SMTPConfig struct {
+	Host     string        // Relay host name (also used for TLS verification)
+	Port     int           // Relay port; 465 uses implicit TLS, others STARTTLS when offered
+	Username string        // PLAIN auth user (empty disables auth)
+	Password string        // PLAIN auth password
+	From     string        // Default From address for messages without one
+	Timeout  time.Duration // Bounds the whole SMTP transaction (0 = 30s)
+	DKIM     *DKIMSigner   // Signs rendered messages (nil disables signing)
+}
+
+// SMTPClient sends messages through an SMTP relay.
+type SMTPClient struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPClient creates an SMTP client.
+func NewSMTPClient(cfg SMTPConfig) (*SMTPClient, error) {
+	if cfg.Host == "" || cfg.Port <= 0 {
+		return nil, errors.New("smtp host and port are required")
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("smtp from address %q: %w", cfg.From, err)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &SMTPClient{cfg: cfg}, nil
+}
+
+// Send renders msg and delivers it to all To, Cc and Bcc recipients in a single
+// SMTP transaction.
+func (c *SMTPClient) Send(ctx context.Context, msg *Message) error {
+	if msg.From == "" {
+		withFrom := *msg
+		withFrom.From = c.cfg.From
+		msg = &withFrom
+	}
+
+	rcpts, err := msg.Recipients()
+	if err != nil {
+		return err
+	}
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	if c.cfg.DKIM != nil {
+		if data, err = c.cfg.DKIM.Sign(data); err != nil {
+			return err
+		}
+	}
+	return c.SendRaw(ctx, msg.From, rcpts, data)
+}
+
+// SendRaw delivers an already rendered message to the given recipients.
+func (c *SMTPClient) SendRaw(ctx context.Context, from string, to []string, data []byte) error {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("from %q: %w", from, ErrInvalidMessage)
+	}
+	rcpts, err := (&Message{To: to}).Recipients()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	client, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Mail(sender.Address); err != nil {
+		return fmt.Errorf("smtp MAIL FROM: %w", throttled(err))
+	}
+	for _, rcpt := range rcpts {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s: %w", rcpt, rejected(err))
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("smtp write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp end of data: %w", rejected(err))
+	}
+
+	return client.Quit()
+}
+
+// rejected marks permanent (5xx) replies with ErrRejected and rate-limit replies as
+// throttle rate limits.
+func rejected(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return fmt.Errorf("%w: %w", err, ErrRejected)
+	}
+	return throttled(err)
+}
+
+// throttled marks rate-limit replies: 421 (the usual answer to too many connections
+// or messages) and 454 (throttling failure).
+func throttled(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && (reply.Code == 421 || reply.Code == 454) {
+		return throttle.RateLimited(err, 0)
+	}
+	return err
+}
+
+// dial connects to the relay, upgrades to TLS and authenticates.
+func (c *SMTPClient) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: c.cfg.Host, MinVersion: tls.VersionTLS12}
+
+	var conn net.Conn
+	var err error
+	if c.cfg.Port == 465 {
+		dialer := &tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("smtp connect %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, c.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("smtp greeting: %w", throttled(err))
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); ok && c.cfg.Port != 465 {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			_ = client.Close()
+			return nil, fmt.Errorf("smtp STARTTLS: %w", err)
+		}
+	}
+
+	if c.cfg.Username != "" {
+		auth := smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.Host)
+		if err := client.Auth(auth); err != nil {
+			_ = client.Close()
+			return nil, fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	return client, nil
+}
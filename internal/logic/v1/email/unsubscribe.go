@@ -0,0 +1,91 @@
+package email
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Sentinel errors for unsubscribe tokens.
+var (
+	ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+	ErrUnsubscribeTokenExpired = errors.New("unsubscribe token expired")
+)
+
+// UnsubscribeClaims identify what an unsubscribe token opts out of.
+type UnsubscribeClaims struct {
+	UserID    int    `json:"uid"`
+	Channel   string `json:"ch"`
+	Category  string `json:"cat"`
+	ExpiresAt int64  `json:"exp"` // Unix seconds
+}
+
+// SignUnsubscribeToken creates a token for claims in the form
+// "<base64url(claims JSON)>.<base64url(HMAC-SHA256(secret, claims JSON))>".
+//
+// The token opts a user out without login, so it is signed and expires. Mail clients
+// call the URL long after the message was sent; lifetimes should be weeks, not hours.
+func SignUnsubscribeToken(secret []byte, claims UnsubscribeClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("marshal unsubscribe claims: %w", err)
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(unsubscribeMAC(secret, payload)), nil
+}
+
+// VerifyUnsubscribeToken checks a token's signature and expiry and returns its claims.
+func VerifyUnsubscribeToken(secret []byte, token string, now time.Time) (*UnsubscribeClaims, error) {
+	enc := base64.RawURLEncoding
+	encodedPayload, encodedMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	payload, err := enc.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	mac, err := enc.DecodeString(encodedMAC)
+	if err != nil || !hmac.Equal(mac, unsubscribeMAC(secret, payload)) {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+
+	var claims UnsubscribeClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.UserID <= 0 || claims.Category == "" {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, ErrUnsubscribeTokenExpired
+	}
+	return &claims, nil
+}
+
+// SetListUnsubscribe adds one-click unsubscribe headers pointing at baseURL with the
+// token as a query parameter. Mail clients POST "List-Unsubscribe=One-Click" to the URL.
+func SetListUnsubscribe(header textproto.MIMEHeader, baseURL, token string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme != "https" {
+		return fmt.Errorf("unsubscribe url %q must be an https URL: %w", baseURL, ErrInvalidMessage)
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+
+	header.Set("List-Unsubscribe", "<"+u.String()+">")
+	header.Set("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	return nil
+}
+
+func unsubscribeMAC(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("unsubscribe:"))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
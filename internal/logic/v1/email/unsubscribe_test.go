@@ -0,0 +1,79 @@
+package email
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnsubscribeToken(t *testing.T) {
+	secret := []byte("unsubscribe-test-secret")
+	now := time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC)
+	claims := UnsubscribeClaims{UserID: 42, Channel: "email", Category: "marketing", ExpiresAt: now.Add(30 * 24 * time.Hour).Unix()}
+
+	sign := func(t *testing.T, secret []byte, claims UnsubscribeClaims) string {
+		t.Helper()
+		token, err := SignUnsubscribeToken(secret, claims)
+		if err != nil {
+			t.Fatalf("SignUnsubscribeToken: %v", err)
+		}
+		return token
+	}
+
+	token := sign(t, secret, claims)
+	payload, mac, _ := strings.Cut(token, ".")
+	// tamper edits the claims JSON of the token, keeping its signature.
+	tamper := func(from, to string) string {
+		decoded, err := base64.RawURLEncoding.DecodeString(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		edited := strings.Replace(string(decoded), from, to, 1)
+		if edited == string(decoded) {
+			t.Fatalf("claims %s do not contain %s", decoded, from)
+		}
+		return base64.RawURLEncoding.EncodeToString([]byte(edited)) + "." + mac
+	}
+	flipped := "A"
+	if mac[0] == 'A' {
+		flipped = "B"
+	}
+	tests := []struct {
+		name    string
+		token   string
+		secret  []byte
+		now     time.Time
+		wantErr error
+	}{
+		{"round trip", token, secret, now, nil},
+		{"just before expiry", token, secret, time.Unix(claims.ExpiresAt-1, 0), nil},
+		{"expired", token, secret, time.Unix(claims.ExpiresAt, 0), ErrUnsubscribeTokenExpired},
+		{"tampered user", tamper(`"uid":42`, `"uid":43`), secret, now, ErrInvalidUnsubscribeToken},
+		{"tampered category", tamper(`"cat":"marketing"`, `"cat":"security"`), secret, now, ErrInvalidUnsubscribeToken},
+		{"tampered expiry", tamper(`"exp":`, `"exp":9`), secret, now, ErrInvalidUnsubscribeToken},
+		{"tampered signature", payload + "." + flipped + mac[1:], secret, now, ErrInvalidUnsubscribeToken},
+		{"wrong secret", token, []byte("another-secret"), now, ErrInvalidUnsubscribeToken},
+		{"signed with another secret", sign(t, []byte("another-secret"), claims), secret, now, ErrInvalidUnsubscribeToken},
+		{"truncated signature", token[:len(token)-4], secret, now, ErrInvalidUnsubscribeToken},
+		{"signature cut off", payload + ".", secret, now, ErrInvalidUnsubscribeToken},
+		{"payload only", payload, secret, now, ErrInvalidUnsubscribeToken},
+		{"truncated payload", payload[:len(payload)-3] + "." + mac, secret, now, ErrInvalidUnsubscribeToken},
+		{"not base64", "!!!." + mac, secret, now, ErrInvalidUnsubscribeToken},
+		{"empty", "", secret, now, ErrInvalidUnsubscribeToken},
+		{"signed without a user", sign(t, secret, UnsubscribeClaims{Category: "marketing", ExpiresAt: claims.ExpiresAt}), secret, now, ErrInvalidUnsubscribeToken},
+		{"signed without a category", sign(t, secret, UnsubscribeClaims{UserID: 42, ExpiresAt: claims.ExpiresAt}), secret, now, ErrInvalidUnsubscribeToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyUnsubscribeToken(tt.secret, tt.token, tt.now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyUnsubscribeToken error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && *got != claims {
+				t.Errorf("claims = %+v, want %+v", *got, claims)
+			}
+		})
+	}
+}
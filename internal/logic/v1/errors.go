@@ -34,4 +34,103 @@ var (
 	// ErrUnauthorized indicates the user is not authorized to perform the operation.
 	// HTTP Status: 403 Forbidden
 	ErrUnauthorized = errors.New("unauthorized access")
+
+	// ErrDeviceNotFound indicates the device token is not registered to the user.
+	// HTTP Status: 404 Not Found
+	ErrDeviceNotFound = errors.New("device not found")
+
+	// ErrDeviceTokenInUse indicates a registered or rotated-to device token is active for another user.
+	// HTTP Status: 409 Conflict
+	ErrDeviceTokenInUse = errors.New("device token in use")
+
+	// ErrInvalidSubscription indicates a Web Push subscription has malformed keys.
+	// HTTP Status: 400 Bad Request
+	ErrInvalidSubscription = errors.New("invalid push subscription")
+
+	// ErrWebPushDisabled indicates Web Push is not configured on this deployment.
+	// HTTP Status: 503 Service Unavailable
+	ErrWebPushDisabled = errors.New("web push disabled")
+
+	// ErrInvalidWebhook indicates a webhook endpoint or request is malformed
+	// (e.g. non-HTTPS URL, missing or ambiguous owner).
+	// HTTP Status: 400 Bad Request
+	ErrInvalidWebhook = errors.New("invalid webhook")
+
+	// ErrWebhookNotFound indicates the webhook endpoint does not exist.
+	// HTTP Status: 404 Not Found
+	ErrWebhookNotFound = errors.New("webhook endpoint not found")
+
+	// ErrInvalidCallback indicates a status callback URL is malformed or was given
+	// without a registered client (X-Client-ID) to sign it.
+	// HTTP Status: 400 Bad Request
+	ErrInvalidCallback = errors.New("invalid callback")
+
+	// ErrClientNotFound indicates the API client is not registered.
+	// HTTP Status: 404 Not Found
+	ErrClientNotFound = errors.New("client not found")
+
+	// ErrInvalidStatusTransition indicates a delivery status change that is not allowed
+	// from the notification's current status (e.g. delivered -> sent).
+	// HTTP Status: 409 Conflict
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+
+	// ErrRecipientSuppressed indicates the recipient is on the suppression list after a
+	// bounce or spam complaint and must not be mailed.
+	// HTTP Status: 422 Unprocessable Entity
+	ErrRecipientSuppressed = errors.New("recipient suppressed")
+
+	// ErrSuppressionNotFound indicates the address is not suppressed.
+	// HTTP Status: 404 Not Found
+	ErrSuppressionNotFound = errors.New("suppression not found")
+
+	// ErrInvalidReport indicates a bounce message is not a parsable DSN or feedback report.
+	// HTTP Status: 400 Bad Request
+	ErrInvalidReport = errors.New("invalid delivery report")
+
+	// ErrRecipientUnsubscribed indicates the user opted out of the email category.
+	// HTTP Status: 422 Unprocessable Entity
+	ErrRecipientUnsubscribed = errors.New("recipient unsubscribed")
+
+	// ErrInvalidUnsubscribeToken indicates an unsubscribe link is forged, malformed or expired.
+	// HTTP Status: 400 Bad Request
+	ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe link")
+
+	// ErrInvalidAttachment indicates an email attachment is malformed, not fetchable, or
+	// an inline image that is not an image.
+	// HTTP Status: 400 Bad Request
+	ErrInvalidAttachment = errors.New("invalid attachment")
+
+	// ErrAttachmentTooLarge indicates an attachment or all attachments of a message
+	// exceed the configured size limit.
+	// HTTP Status: 413 Payload Too Large
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+
+	// ErrMessageTooLong indicates an SMS needs more segments than allowed and
+	// truncation is disabled.
+	// HTTP Status: 400 Bad Request
+	ErrMessageTooLong = errors.New("message too long")
+
+	// ErrNotificationExpired indicates a notification reached its expires_at before it
+	// could be sent; it was cancelled.
+	// HTTP Status: 410 Gone
+	ErrNotificationExpired = errors.New("notification expired")
+
+	// ErrInvalidMetadata indicates a notification's metadata, links or entity reference
+	// do not match the schema of its type, or a link uses an unsafe scheme.
+	// HTTP Status: 400 Bad Request
+	ErrInvalidMetadata = errors.New("invalid notification metadata")
+
+	// ErrActionNotFound indicates the notification has no action with the given id.
+	// HTTP Status: 404 Not Found
+	ErrActionNotFound = errors.New("notification action not found")
+
+	// ErrActionAlreadyTaken indicates the user already chose a different action of the
+	// notification.
+	// HTTP Status: 409 Conflict
+	ErrActionAlreadyTaken = errors.New("notification action already taken")
+
+	// ErrNoDevices indicates a queued push reached no device or browser of the user, as none
+	// is registered or all were deactivated; the notification fails with it.
+	// HTTP Status: none (recorded as the failure reason by the worker)
+	ErrNoDevices = errors.New("no active devices")
 )
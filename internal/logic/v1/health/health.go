@@ -0,0 +1,272 @@
+// Package health tracks the health of channel providers (SMTP relays, SMS
+// aggregators, push services) and protects them with circuit breakers.
+//
+// Every provider call goes through a Breaker that keeps a rolling window of
+// outcomes and latencies:
+//   - closed: calls pass; when the window holds at least MinRequests calls and the
+//     share of failures reaches FailureRate, the breaker opens
+//   - open: calls fail fast with ErrCircuitOpen so callers move on to a backup
+//     provider; after OpenFor the breaker turns half-open
+//   - half_open: a single probe call is let through; success closes the breaker,
+//     failure opens it again
+//
+// Breakers are created through a Registry, which keeps them in priority order per
+// channel, exports their state as Prometheus metrics and reports it for the
+// provider status endpoint.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen indicates a call was not made because the provider's breaker is open.
+// Callers add the provider name when they report it.
+var ErrCircuitOpen = errors.New("provider circuit open")
+
+// State is the state of a circuit breaker.
+type State string
+
+const (
+	StateClosed   State = "closed"    // Calls pass
+	StateOpen     State = "open"      // Calls fail fast
+	StateHalfOpen State = "half_open" // One probe call passes
+)
+
+// windowBuckets is the number of buckets the rolling window is divided into.
+const windowBuckets = 10
+
+// Config configures the breakers of a Registry.
+type Config struct {
+	Window      time.Duration // Length of the rolling window (0 = 1m)
+	MinRequests int           // Calls in the window before the error rate is acted on (0 = 10)
+	FailureRate float64       // Share of failed calls that opens the breaker (0 = 0.5)
+	OpenFor     time.Duration // Time an open breaker rejects calls before probing (0 = 30s)
+}
+
+func (c Config) withDefaults() Config {
+	if c.Window <= 0 {
+		c.Window = time.Minute
+	}
+	if c.MinRequests <= 0 {
+		c.MinRequests = 10
+	}
+	if c.FailureRate <= 0 {
+		c.FailureRate = 0.5
+	}
+	if c.OpenFor <= 0 {
+		c.OpenFor = 30 * time.Second
+	}
+	return c
+}
+
+// Status is a snapshot of one provider's health.
+type Status struct {
+	Channel    string
+	Provider   string
+	State      State
+	Since      time.Time     // When the breaker entered State
+	Requests   int           // Calls in the rolling window
+	Failures   int           // Failed calls in the rolling window
+	AvgLatency time.Duration // Mean call duration in the rolling window
+}
+
+// ErrorRate returns the share of failed calls in the rolling window.
+func (s Status) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Requests)
+}
+
+type bucket struct {
+	period   int64 // Index of the bucket-sized period the counts belong to
+	requests int
+	failures int
+	latency  time.Duration
+}
+
+// Breaker is the circuit breaker and rolling health window of one provider.
+// A nil Breaker passes every call.
+type Breaker struct {
+	channel  string
+	provider string
+	cfg      Config
+	onChange func()
+	now      func() time.Time // Clock of the breaker and its window
+
+	mu      sync.Mutex
+	state   State
+	since   time.Time
+	probing bool
+	buckets [windowBuckets]bucket
+}
+
+// Call runs fn unless the breaker is open and records its outcome. isFault reports
+// whether an error is the provider's fault; errors about the recipient or the
+// message (an invalid token, a rejected address) show the provider is working and
+// must not open the breaker. A nil isFault counts every error. Calls cancelled by
+// the caller are not recorded.
+func (b *Breaker) Call(fn func() error, isFault func(error) bool) error {
+	if b == nil {
+		return fn()
+	}
+	probe, ok := b.allow()
+	if !ok {
+		providerRequests.WithLabelValues(b.channel, b.provider, "short_circuit").Inc()
+		return ErrCircuitOpen
+	}
+
+	start := b.now()
+	err := fn()
+	latency := b.now().Sub(start)
+
+	if errors.Is(err, context.Canceled) {
+		b.release(probe)
+		return err
+	}
+	fault := err != nil && (isFault == nil || isFault(err))
+	result := "success"
+	if fault {
+		result = "failure"
+	}
+	providerRequests.WithLabelValues(b.channel, b.provider, result).Inc()
+	providerDuration.WithLabelValues(b.channel, b.provider).Observe(latency.Seconds())
+	b.record(fault, latency, probe)
+	return err
+}
+
+// Status returns a snapshot of the breaker and its rolling window.
+func (b *Breaker) Status() Status {
+	now := b.now()
+	b.mu.Lock()
+	changed := b.advance(now)
+	status := Status{
+		Channel:  b.channel,
+		Provider: b.provider,
+		State:    b.state,
+		Since:    b.since,
+	}
+	var latency time.Duration
+	status.Requests, status.Failures, latency = b.totals(now)
+	b.mu.Unlock()
+
+	if status.Requests > 0 {
+		status.AvgLatency = latency / time.Duration(status.Requests)
+	}
+	if changed {
+		b.onChange()
+	}
+	return status
+}
+
+// allow reports whether a call may proceed and whether it is the half-open probe.
+func (b *Breaker) allow() (probe, ok bool) {
+	b.mu.Lock()
+	changed := b.advance(b.now())
+	switch b.state {
+	case StateClosed:
+		ok = true
+	case StateHalfOpen:
+		if !b.probing {
+			b.probing = true
+			probe, ok = true, true
+		}
+	}
+	b.mu.Unlock()
+
+	if changed {
+		b.onChange()
+	}
+	return probe, ok
+}
+
+// release gives up the probe slot of a call that was not recorded.
+func (b *Breaker) release(probe bool) {
+	if !probe {
+		return
+	}
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+// record adds a call outcome to the window and moves the breaker on.
+func (b *Breaker) record(fault bool, latency time.Duration, probe bool) {
+	now := b.now()
+	b.mu.Lock()
+	changed := false
+	if probe {
+		b.probing = false
+		if fault {
+			changed = b.setState(StateOpen, now)
+		} else {
+			b.buckets = [windowBuckets]bucket{}
+			changed = b.setState(StateClosed, now)
+		}
+	}
+
+	bk := b.bucket(now)
+	bk.requests++
+	bk.latency += latency
+	if fault {
+		bk.failures++
+	}
+
+	requests, failures, _ := b.totals(now)
+	rate := float64(failures) / float64(requests)
+	if b.state == StateClosed && fault && requests >= b.cfg.MinRequests && rate >= b.cfg.FailureRate {
+		changed = b.setState(StateOpen, now)
+	}
+	b.mu.Unlock()
+
+	providerErrorRate.WithLabelValues(b.channel, b.provider).Set(rate)
+	if changed {
+		b.onChange()
+	}
+}
+
+// advance turns an open breaker half-open once OpenFor has passed. The caller holds mu.
+func (b *Breaker) advance(now time.Time) bool {
+	if b.state == StateOpen && now.Sub(b.since) >= b.cfg.OpenFor {
+		return b.setState(StateHalfOpen, now)
+	}
+	return false
+}
+
+// setState moves the breaker to state. The caller holds mu and calls onChange after
+// releasing it when setState reports a change.
+func (b *Breaker) setState(state State, now time.Time) bool {
+	if b.state == state {
+		return false
+	}
+	b.state = state
+	b.since = now
+	providerState.WithLabelValues(b.channel, b.provider).Set(stateValue(state))
+	return true
+}
+
+// bucket returns the bucket for now, resetting it if it holds an older period.
+func (b *Breaker) bucket(now time.Time) *bucket {
+	period := now.UnixNano() / int64(b.cfg.Window/windowBuckets)
+	bk := &b.buckets[period%windowBuckets]
+	if bk.period != period {
+		*bk = bucket{period: period}
+	}
+	return bk
+}
+
+// totals sums the buckets still inside the window.
+func (b *Breaker) totals(now time.Time) (requests, failures int, latency time.Duration) {
+	current := now.UnixNano() / int64(b.cfg.Window/windowBuckets)
+	for _, bk := range b.buckets {
+		if current-bk.period < windowBuckets {
+			requests += bk.requests
+			failures += bk.failures
+			latency += bk.latency
+		}
+	}
+	return requests, failures, latency
+}
@@ -0,0 +1,230 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+var (
+	errProviderDown     = errors.New("provider down")
+	errInvalidRecipient = errors.New("invalid recipient")
+)
+
+// isFault counts every error but errInvalidRecipient, like a sender's fault check.
+func isFault(err error) bool {
+	return !errors.Is(err, errInvalidRecipient)
+}
+
+var testConfig = Config{Window: 10 * time.Second, MinRequests: 4, FailureRate: 0.5, OpenFor: 30 * time.Second}
+
+// newTestBreaker returns a breaker of testConfig on a fake clock.
+func newTestBreaker(t *testing.T) (*Breaker, *fakeClock) {
+	t.Helper()
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	registry := NewRegistry(testConfig)
+	registry.now = clock.Now
+	return registry.Breaker("email", t.Name()), clock
+}
+
+// call makes one call through b with the outcome of c: 's' succeeds, 'f' fails with a
+// provider fault, 'r' fails with an error that is not the provider's fault. It reports
+// whether fn ran.
+func call(b *Breaker, c byte) (ran bool, err error) {
+	err = b.Call(func() error {
+		ran = true
+		switch c {
+		case 'f':
+			return errProviderDown
+		case 'r':
+			return errInvalidRecipient
+		}
+		return nil
+	}, isFault)
+	return ran, err
+}
+
+func TestBreakerFailureThreshold(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes string
+		want     State
+	}{
+		{"no calls", "", StateClosed},
+		{"successes", "ssssssss", StateClosed},
+		{"failures below minimum requests", "fff", StateClosed},
+		{"failures at minimum requests", "ffff", StateOpen},
+		{"rate reached", "ssff", StateOpen},
+		{"rate reached later", "ssfsff", StateOpen},
+		{"rate below", "sssf", StateClosed},
+		{"rate below with more calls", "sssfssf", StateClosed},
+		{"success does not open", "fffs", StateClosed},
+		{"recipient errors are not faults", "rrrrrrrr", StateClosed},
+		{"recipient errors count as calls", "rrff", StateOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := newTestBreaker(t)
+			for i := range len(tt.outcomes) {
+				if _, err := call(b, tt.outcomes[i]); errors.Is(err, ErrCircuitOpen) {
+					t.Fatalf("call %d short-circuited", i+1)
+				}
+			}
+			if got := b.Status().State; got != tt.want {
+				t.Errorf("state = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakerWindow(t *testing.T) {
+	b, clock := newTestBreaker(t)
+	for range 3 {
+		call(b, 'f')
+	}
+	clock.Advance(testConfig.Window)
+
+	// The earlier failures left the window, so a fourth does not reach MinRequests
+	call(b, 'f')
+	status := b.Status()
+	if status.State != StateClosed || status.Requests != 1 || status.Failures != 1 {
+		t.Errorf("status = %s with %d/%d failed, want closed with 1/1", status.State, status.Failures, status.Requests)
+	}
+}
+
+func TestBreakerTransitions(t *testing.T) {
+	b, clock := newTestBreaker(t)
+	start := clock.Now()
+	step := func(name string, want State, wantSince time.Time) {
+		t.Helper()
+		status := b.Status()
+		if status.State != want || !status.Since.Equal(wantSince) {
+			t.Fatalf("%s: state = %s since %s, want %s since %s", name, status.State, status.Since, want, wantSince)
+		}
+	}
+	step("new", StateClosed, start)
+
+	// closed -> open
+	for range 4 {
+		call(b, 'f')
+	}
+	opened := clock.Now()
+	step("failing", StateOpen, opened)
+
+	clock.Advance(testConfig.OpenFor - time.Second)
+	if ran, err := call(b, 's'); ran || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("open: call ran = %v, err = %v; want short-circuited", ran, err)
+	}
+	step("open", StateOpen, opened)
+
+	// open -> half-open -> open: a failed probe opens the breaker again
+	clock.Advance(time.Second)
+	halfOpened := clock.Now()
+	step("open for OpenFor", StateHalfOpen, halfOpened)
+	if ran, err := call(b, 'f'); !ran || !errors.Is(err, errProviderDown) {
+		t.Fatalf("probe: ran = %v, err = %v", ran, err)
+	}
+	reopened := clock.Now()
+	step("failed probe", StateOpen, reopened)
+
+	// open -> half-open -> closed: a probe at a time, then a successful one closes it
+	clock.Advance(testConfig.OpenFor)
+	var concurrentRan bool
+	var concurrentErr error
+	err := b.Call(func() error {
+		concurrentRan, concurrentErr = call(b, 's')
+		return nil
+	}, isFault)
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if concurrentRan || !errors.Is(concurrentErr, ErrCircuitOpen) {
+		t.Errorf("call during probe: ran = %v, err = %v; want short-circuited", concurrentRan, concurrentErr)
+	}
+	step("successful probe", StateClosed, clock.Now())
+
+	// The failures before the breaker opened are forgotten
+	if status := b.Status(); status.Requests != 1 || status.Failures != 0 {
+		t.Errorf("window after closing = %d/%d failed, want 0/1", status.Failures, status.Requests)
+	}
+	call(b, 'f')
+	step("failure after closing", StateClosed, clock.Now())
+}
+
+func TestBreakerCancelledProbe(t *testing.T) {
+	b, clock := newTestBreaker(t)
+	for range 4 {
+		call(b, 'f')
+	}
+	clock.Advance(testConfig.OpenFor)
+
+	// A probe cancelled by the caller proves nothing and gives the slot back
+	err := b.Call(func() error { return context.Canceled }, isFault)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled probe: %v", err)
+	}
+	if state := b.Status().State; state != StateHalfOpen {
+		t.Fatalf("state after cancelled probe = %s, want half_open", state)
+	}
+	if ran, err := call(b, 's'); !ran || err != nil {
+		t.Fatalf("next probe: ran = %v, err = %v", ran, err)
+	}
+	if state := b.Status().State; state != StateClosed {
+		t.Errorf("state = %s, want closed", state)
+	}
+}
+
+func TestRegistryActiveProvider(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	registry := NewRegistry(testConfig)
+	registry.now = clock.Now
+	primary := registry.Breaker("sms", "primary")
+	backup := registry.Breaker("sms", "backup")
+	if registry.Breaker("sms", "primary") != primary {
+		t.Fatal("Breaker returned a new breaker for a registered provider")
+	}
+
+	active := func() string {
+		t.Helper()
+		status := registry.Status()
+		if len(status) != 1 || len(status[0].Providers) != 2 {
+			t.Fatalf("status = %+v, want one channel of two providers", status)
+		}
+		return status[0].Active
+	}
+	if got := active(); got != "primary" {
+		t.Errorf("active = %q, want primary", got)
+	}
+	for range 4 {
+		call(primary, 'f')
+	}
+	if got := active(); got != "backup" {
+		t.Errorf("active with primary open = %q, want backup", got)
+	}
+	for range 4 {
+		call(backup, 'f')
+	}
+	if got := active(); got != "" {
+		t.Errorf("active with all open = %q, want none", got)
+	}
+	clock.Advance(testConfig.OpenFor)
+	if got := active(); got != "primary" {
+		t.Errorf("active with primary half-open = %q, want primary", got)
+	}
+}
+
+func TestNilBreaker(t *testing.T) {
+	var registry *Registry
+	b := registry.Breaker("email", "smtp")
+	for range 10 {
+		if ran, err := call(b, 'f'); !ran || !errors.Is(err, errProviderDown) {
+			t.Fatalf("nil breaker: ran = %v, err = %v", ran, err)
+		}
+	}
+}
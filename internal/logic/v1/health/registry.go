@@ -0,0 +1,176 @@
+package health
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	providerRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "provider_requests_total",
+			Help: "Channel provider calls by result (success, failure, short_circuit)",
+		},
+		[]string{"channel", "provider", "result"},
+	)
+
+	providerDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "provider_request_duration_seconds",
+			Help:    "Duration of channel provider calls in seconds",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30},
+		},
+		[]string{"channel", "provider"},
+	)
+
+	providerErrorRate = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "provider_error_rate",
+			Help: "Share of failed calls in the provider's rolling health window",
+		},
+		[]string{"channel", "provider"},
+	)
+
+	providerState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "provider_circuit_state",
+			Help: "Circuit breaker state of a channel provider (0 = closed, 1 = half-open, 2 = open)",
+		},
+		[]string{"channel", "provider"},
+	)
+
+	providerActive = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "provider_active",
+			Help: "1 for the provider currently taking a channel's traffic: the first one, in priority order, whose breaker is not open",
+		},
+		[]string{"channel", "provider"},
+	)
+)
+
+func stateValue(state State) float64 {
+	switch state {
+	case StateHalfOpen:
+		return 1
+	case StateOpen:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// ChannelStatus is the health of a channel's providers in priority order.
+type ChannelStatus struct {
+	Channel   string
+	Active    string // First provider whose breaker is not open ("" when all are open)
+	Providers []Status
+}
+
+// Registry creates the breakers of all channel providers. A nil Registry hands out
+// nil breakers, which pass every call.
+type Registry struct {
+	cfg Config
+	now func() time.Time // Clock of the breakers
+
+	mu       sync.Mutex
+	channels []string              // Registration order
+	breakers map[string][]*Breaker // By channel, in priority order
+}
+
+// NewRegistry creates a registry whose breakers use cfg.
+func NewRegistry(cfg Config) *Registry {
+	return &Registry{
+		cfg:      cfg.withDefaults(),
+		now:      time.Now,
+		breakers: make(map[string][]*Breaker),
+	}
+}
+
+// Breaker returns the breaker of a channel provider, creating it on first use.
+// Providers of a channel are ranked in the order they are first registered.
+func (r *Registry) Breaker(channel, provider string) *Breaker {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, b := range r.breakers[channel] {
+		if b.provider == provider {
+			return b
+		}
+	}
+	if _, ok := r.breakers[channel]; !ok {
+		r.channels = append(r.channels, channel)
+	}
+	b := &Breaker{
+		channel:  channel,
+		provider: provider,
+		cfg:      r.cfg,
+		onChange: func() { r.refresh(channel) },
+		now:      r.now,
+		state:    StateClosed,
+		since:    r.now(),
+	}
+	r.breakers[channel] = append(r.breakers[channel], b)
+	providerState.WithLabelValues(channel, provider).Set(stateValue(StateClosed))
+	providerErrorRate.WithLabelValues(channel, provider).Set(0)
+	r.setActive(channel, r.breakers[channel])
+	return b
+}
+
+// Status returns the health of every registered provider, grouped by channel.
+func (r *Registry) Status() []ChannelStatus {
+	if r == nil {
+		return []ChannelStatus{}
+	}
+	r.mu.Lock()
+	channels := make([]string, len(r.channels))
+	copy(channels, r.channels)
+	breakers := make(map[string][]*Breaker, len(r.breakers))
+	for channel, list := range r.breakers {
+		breakers[channel] = append([]*Breaker(nil), list...)
+	}
+	r.mu.Unlock()
+
+	result := make([]ChannelStatus, 0, len(channels))
+	for _, channel := range channels {
+		status := ChannelStatus{Channel: channel, Providers: make([]Status, 0, len(breakers[channel]))}
+		for _, b := range breakers[channel] {
+			s := b.Status()
+			if status.Active == "" && s.State != StateOpen {
+				status.Active = s.Provider
+			}
+			status.Providers = append(status.Providers, s)
+		}
+		result = append(result, status)
+	}
+	return result
+}
+
+// refresh updates the active provider gauge of a channel after a state change.
+func (r *Registry) refresh(channel string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.setActive(channel, r.breakers[channel])
+}
+
+// setActive marks the first provider whose breaker is not open. The caller holds mu.
+func (r *Registry) setActive(channel string, breakers []*Breaker) {
+	active := false
+	for _, b := range breakers {
+		b.mu.Lock()
+		open := b.state == StateOpen
+		b.mu.Unlock()
+
+		value := 0.0
+		if !active && !open {
+			active = true
+			value = 1
+		}
+		providerActive.WithLabelValues(channel, b.provider).Set(value)
+	}
+}
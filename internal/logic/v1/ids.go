@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+// NotificationIDs resolves the notification IDs of API paths and requests to the
+// keys of the repository. Clients use public IDs (UUIDs); while legacy IDs are
+// accepted, the numeric IDs issued before public IDs existed are resolved as well.
+type NotificationIDs struct {
+	repo   domain.NotificationRepository
+	legacy bool
+}
+
+// NewNotificationIDs creates a NotificationIDs. With legacy, numeric IDs are accepted.
+func NewNotificationIDs(repo domain.NotificationRepository, legacy bool) *NotificationIDs {
+	return &NotificationIDs{
+		repo:   repo,
+		legacy: legacy,
+	}
+}
+
+// Resolve returns the key of a notification. IDs that cannot name a notification are
+// reported as not found.
+func (r *NotificationIDs) Resolve(ctx context.Context, id string) (domain.NotificationKey, error) {
+	keys, err := r.ResolveAll(ctx, []string{id})
+	if err != nil {
+		return domain.NotificationKey{}, err
+	}
+	if len(keys) == 0 {
+		return domain.NotificationKey{}, fmt.Errorf("notification id %q: %w", id, ErrNotificationNotFound)
+	}
+	return keys[0], nil
+}
+
+// ResolveAll returns the keys of the notifications that exist, skipping the others.
+// Malformed IDs are reported as not found.
+func (r *NotificationIDs) ResolveAll(ctx context.Context, ids []string) ([]domain.NotificationKey, error) {
+	publicIDs := make([]uuid.UUID, 0, len(ids))
+	var legacyIDs []int64
+	for _, id := range ids {
+		if publicID, err := uuid.Parse(id); err == nil {
+			publicIDs = append(publicIDs, publicID)
+			continue
+		}
+		legacyID, err := strconv.ParseInt(id, 10, 64)
+		if !r.legacy || err != nil || legacyID <= 0 {
+			return nil, fmt.Errorf("invalid notification id %q: %w", id, ErrNotificationNotFound)
+		}
+		legacyIDLookups.Inc()
+		legacyIDs = append(legacyIDs, legacyID)
+	}
+
+	var keys []domain.NotificationKey
+	if len(publicIDs) > 0 {
+		found, err := r.repo.FindKeys(ctx, publicIDs)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, found...)
+	}
+	if len(legacyIDs) > 0 {
+		found, err := r.repo.FindLegacyKeys(ctx, legacyIDs)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, found...)
+	}
+	return keys, nil
+}
@@ -0,0 +1,147 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+)
+
+// maxMetadataBytes bounds the JSON size of a notification's metadata.
+const maxMetadataBytes = 16 << 10
+
+// unsafeURLSchemes cannot be rendered as links by clients without risking script injection.
+var unsafeURLSchemes = []string{"javascript", "vbscript", "data", "file"}
+
+// actionIDPattern matches action ids, which appear in the action endpoint's path.
+var actionIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// metadataType is the JSON type of a metadata value.
+type metadataType string
+
+const (
+	metadataString  metadataType = "string"
+	metadataNumber  metadataType = "number"
+	metadataBoolean metadataType = "boolean"
+	metadataObject  metadataType = "object"
+	metadataArray   metadataType = "array"
+)
+
+// metadataSchema describes the context of one notification type.
+type metadataSchema struct {
+	EntityType string                  // entity_type the notification must reference ("" = optional, any)
+	Fields     map[string]metadataType // Allowed metadata keys; other keys are rejected
+	Required   []string                // Metadata keys that must be present
+}
+
+var orderFields = map[string]metadataType{
+	"order_number": metadataString,
+	"total":        metadataNumber,
+	"currency":     metadataString,
+	"item_count":   metadataNumber,
+}
+
+// metadataSchemas holds the schemas by notification type. Types without a schema
+// (including the channel types email, sms and push) accept any metadata object.
+var metadataSchemas = map[string]metadataSchema{
+	"order_placed":     {EntityType: "order", Fields: orderFields},
+	"order_processing": {EntityType: "order", Fields: orderFields},
+	"order_completed":  {EntityType: "order", Fields: orderFields},
+	"order_shipped": {EntityType: "order", Fields: map[string]metadataType{
+		"order_number":    metadataString,
+		"carrier":         metadataString,
+		"tracking_number": metadataString,
+		"tracking_url":    metadataString,
+	}},
+	"review_reminder": {EntityType: "product", Fields: map[string]metadataType{
+		"product_name": metadataString,
+		"order_id":     metadataString,
+	}},
+	"cart_reminder": {EntityType: "cart", Fields: map[string]metadataType{
+		"item_count": metadataNumber,
+	}, Required: []string{"item_count"}},
+	"promotion": {Fields: map[string]metadataType{
+		"promo_code":       metadataString,
+		"discount_percent": metadataNumber,
+		"ends_at":          metadataString,
+	}},
+}
+
+// validateContext checks a notification's context against the schema of its type.
+func validateContext(notificationType string, c domain.NotificationContext) error {
+	if !safeURL(c.ActionURL) {
+		return fmt.Errorf("action_url scheme not allowed: %w", ErrInvalidMetadata)
+	}
+	seen := make(map[string]bool, len(c.Actions))
+	for _, action := range c.Actions {
+		if !actionIDPattern.MatchString(action.ID) || seen[action.ID] {
+			return fmt.Errorf("action id %q must be unique and use letters, digits, - and _: %w", action.ID, ErrInvalidMetadata)
+		}
+		seen[action.ID] = true
+		if !safeURL(action.URL) {
+			return fmt.Errorf("action %q url scheme not allowed: %w", action.ID, ErrInvalidMetadata)
+		}
+	}
+	if len(c.Metadata) > 0 {
+		data, err := json.Marshal(c.Metadata)
+		if err != nil {
+			return fmt.Errorf("encode metadata: %w: %w", err, ErrInvalidMetadata)
+		}
+		if len(data) > maxMetadataBytes {
+			return fmt.Errorf("metadata is %d bytes, at most %d allowed: %w", len(data), maxMetadataBytes, ErrInvalidMetadata)
+		}
+	}
+
+	schema, ok := metadataSchemas[notificationType]
+	if !ok {
+		return nil
+	}
+	if schema.EntityType != "" && (c.EntityType != schema.EntityType || c.EntityID == "") {
+		return fmt.Errorf("%s notifications must reference an entity of type %q: %w", notificationType, schema.EntityType, ErrInvalidMetadata)
+	}
+	for _, key := range schema.Required {
+		if c.Metadata[key] == nil {
+			return fmt.Errorf("%s metadata requires %q: %w", notificationType, key, ErrInvalidMetadata)
+		}
+	}
+	for key, value := range c.Metadata {
+		want, ok := schema.Fields[key]
+		if !ok {
+			return fmt.Errorf("%s metadata does not allow %q: %w", notificationType, key, ErrInvalidMetadata)
+		}
+		if value != nil && jsonType(value) != want {
+			return fmt.Errorf("%s metadata %q must be a %s: %w", notificationType, key, want, ErrInvalidMetadata)
+		}
+	}
+	return nil
+}
+
+// safeURL reports whether a link may be rendered by clients. Empty links are safe.
+func safeURL(link string) bool {
+	if link == "" {
+		return true
+	}
+	u, err := url.Parse(link)
+	return err == nil && !slices.Contains(unsafeURLSchemes, strings.ToLower(u.Scheme))
+}
+
+// jsonType returns the JSON type of a value decoded by encoding/json.
+func jsonType(value any) metadataType {
+	switch value.(type) {
+	case string:
+		return metadataString
+	case float64, json.Number:
+		return metadataNumber
+	case bool:
+		return metadataBoolean
+	case map[string]any:
+		return metadataObject
+	case []any:
+		return metadataArray
+	}
+	return ""
+}
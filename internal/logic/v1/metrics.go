@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	notificationsDelivered = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notifications_delivered_total",
+			Help: "Notifications processed for delivery, by outcome (sent, failed, expired)",
+		},
+		[]string{"type", "priority", "result"},
+	)
+
+	notificationDeliveryDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "notification_delivery_duration_seconds",
+			Help:    "Time from queueing a notification to its provider accepting or failing it in seconds",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		},
+		[]string{"type", "priority"},
+	)
+
+	notificationsPurged = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notifications_purged_total",
+			Help: "Notifications permanently deleted by retention, by rule (type[:read], * or trash)",
+		},
+		[]string{"rule"},
+	)
+
+	webhookDeliveriesPurged = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "webhook_deliveries_purged_total",
+			Help: "Delivered and dead webhook deliveries permanently deleted by retention",
+		},
+	)
+
+	callbackEventsPurged = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "callback_events_purged_total",
+			Help: "Delivered and dead status callback events permanently deleted by retention for their age (not with their notification)",
+		},
+	)
+
+	legacyIDLookups = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "notification_legacy_id_lookups_total",
+			Help: "Notifications looked up by their numeric ID instead of their public ID",
+		},
+	)
+)
@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PartitionService keeps the monthly partitions of the notifications table: future
+// months are created ahead of time so inserts never lack a partition, and old months
+// are detached once they leave the retention horizon.
+type PartitionService struct {
+	repo        domain.NotificationPartitionRepository
+	monthsAhead int
+	detachAfter int
+}
+
+// NewPartitionService creates a PartitionService. Partitions are detached detachAfter
+// months after they end (0 = never).
+func NewPartitionService(repo domain.NotificationPartitionRepository, monthsAhead, detachAfter int) *PartitionService {
+	return &PartitionService{
+		repo:        repo,
+		monthsAhead: monthsAhead,
+		detachAfter: detachAfter,
+	}
+}
+
+// Maintain creates the missing future partitions and detaches expired ones. It returns
+// the number of partitions created and the names of those detached.
+func (s *PartitionService) Maintain(ctx context.Context) (int, []string, error) {
+	ctx, span := middleware.StartSpan(ctx, "notification.partitions", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.Int("partitions.months_ahead", s.monthsAhead),
+		attribute.Int("partitions.detach_after", s.detachAfter),
+	))
+	defer span.End()
+
+	created, err := s.repo.CreateAhead(ctx, s.monthsAhead)
+	if err != nil {
+		span.RecordError(err)
+		return 0, nil, err
+	}
+	span.SetAttributes(attribute.Int("partitions.created", created))
+	if s.detachAfter == 0 {
+		return created, nil, nil
+	}
+
+	detached, err := s.repo.DetachOlderThan(ctx, s.detachAfter)
+	if err != nil {
+		span.RecordError(err)
+		return created, nil, err
+	}
+
+	span.SetAttributes(attribute.Int("partitions.detached", len(detached)))
+	return created, detached, nil
+}
@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/internal/logic/v1/health"
+	"github.com/duynhne/notification-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ProviderService reports the health of the channel providers.
+type ProviderService struct {
+	registry *health.Registry
+}
+
+// NewProviderService creates a ProviderService over the breakers in registry.
+func NewProviderService(registry *health.Registry) *ProviderService {
+	return &ProviderService{registry: registry}
+}
+
+// Status returns every channel with its providers in priority order.
+func (s *ProviderService) Status(ctx context.Context) []domain.ChannelHealth {
+	_, span := middleware.StartSpan(ctx, "provider.status", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("api.version", "v1"),
+	))
+	defer span.End()
+
+	channels := s.registry.Status()
+	result := make([]domain.ChannelHealth, 0, len(channels))
+	for _, channel := range channels {
+		ch := domain.ChannelHealth{
+			Channel:   channel.Channel,
+			Active:    channel.Active,
+			Providers: make([]domain.ProviderHealth, 0, len(channel.Providers)),
+		}
+		for _, p := range channel.Providers {
+			ch.Providers = append(ch.Providers, domain.ProviderHealth{
+				Provider:     p.Provider,
+				State:        string(p.State),
+				StateSince:   p.Since.UTC().Format(time.RFC3339),
+				Requests:     p.Requests,
+				Failures:     p.Failures,
+				ErrorRate:    p.ErrorRate(),
+				AvgLatencyMs: float64(p.AvgLatency.Microseconds()) / 1000,
+			})
+		}
+		result = append(result, ch)
+	}
+	span.SetAttributes(attribute.Int("channels", len(result)))
+	return result
+}
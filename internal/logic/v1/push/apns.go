@@ -0,0 +1,214 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/throttle"
+)
+
+const (
+	// DefaultAPNsBaseURL is the production APNs endpoint.
+	DefaultAPNsBaseURL = "https://api.push.apple.com"
+	// SandboxAPNsBaseURL is the development APNs endpoint.
+	SandboxAPNsBaseURL = "https://api.sandbox.push.apple.com"
+
+	// Apple rejects provider tokens older than one hour and throttles tokens
+	// refreshed more often than every 20 minutes.
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+// APNsConfig configures the token-based APNs client.
+type APNsConfig struct {
+	KeyPEM     []byte       // Contents of the .p8 auth key downloaded from Apple
+	KeyID      string       // 10-character key ID of the .p8 key
+	TeamID     string       // Apple developer team ID
+	Topic      string       // App bundle ID (apns-topic)
+	BaseURL    string       // Overrides DefaultAPNsBaseURL (e.g. SandboxAPNsBaseURL or an httptest server)
+	HTTPClient *http.Client // Optional; defaults to an HTTP/2 client with a 10s timeout
+}
+
+// APNsClient sends notifications through the APNs HTTP/2 provider API.
+type APNsClient struct {
+	keyID      string
+	teamID     string
+	topic      string
+	baseURL    string
+	key        *ecdsa.PrivateKey
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	jwt      string
+	issuedAt time.Time
+}
+
+// NewAPNsClient creates an APNs client from a .p8 signing key.
+func NewAPNsClient(cfg APNsConfig) (*APNsClient, error) {
+	if cfg.KeyID == "" || cfg.TeamID == "" || cfg.Topic == "" {
+		return nil, errors.New("apns key id, team id and topic are required")
+	}
+	signer, err := parsePrivateKeyPEM(cfg.KeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("apns key: %w", err)
+	}
+	key, ok := signer.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("apns key must be an ECDSA P-256 key, got %T", signer)
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultAPNsBaseURL
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		// APNs only speaks HTTP/2; the default transport negotiates it via ALPN over TLS.
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &APNsClient{
+		keyID:      cfg.KeyID,
+		teamID:     cfg.TeamID,
+		topic:      cfg.Topic,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		key:        key,
+		httpClient: httpClient,
+	}, nil
+}
+
+// apnsAlert is the aps.alert dictionary.
+type apnsAlert struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+// apnsAPS is the reserved aps dictionary of the payload.
+type apnsAPS struct {
+	Alert *apnsAlert `json:"alert,omitempty"`
+	Badge *int       `json:"badge,omitempty"`
+	Sound string     `json:"sound,omitempty"`
+}
+
+// Send delivers msg to a single APNs device token.
+func (c *APNsClient) Send(ctx context.Context, token string, msg *Message) error {
+	body, err := buildAPNsPayload(msg)
+	if err != nil {
+		return err
+	}
+
+	providerToken, err := c.providerToken()
+	if err != nil {
+		return err
+	}
+
+	endpoint := c.baseURL + "/3/device/" + url.PathEscape(token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create apns request: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("Apns-Topic", c.topic)
+	req.Header.Set("Apns-Push-Type", "alert")
+	req.Header.Set("Apns-Priority", "10")
+	req.Header.Set("Content-Type", "application/json")
+	if msg.CollapseID != "" {
+		req.Header.Set("Apns-Collapse-Id", msg.CollapseID)
+	}
+	if msg.TTL > 0 {
+		req.Header.Set("Apns-Expiration", strconv.FormatInt(time.Now().Add(msg.TTL).Unix(), 10))
+	}
+
+	resp, err := c.httpClient.Do(req) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("request apns: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return parseAPNsError(resp)
+}
+
+func buildAPNsPayload(msg *Message) ([]byte, error) {
+	aps := apnsAPS{Badge: msg.Badge}
+	if msg.Title != "" || msg.Body != "" {
+		aps.Alert = &apnsAlert{Title: msg.Title, Body: msg.Body}
+		aps.Sound = "default"
+	}
+
+	// Custom data lives alongside the reserved "aps" key at the top level.
+	payload := make(map[string]any, len(msg.Data)+1)
+	for k, v := range msg.Data {
+		payload[k] = v
+	}
+	payload["aps"] = aps
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal apns payload: %w", err)
+	}
+	return body, nil
+}
+
+// parseAPNsError maps an APNs error response to an error. BadDeviceToken,
+// Unregistered (410) and DeviceTokenNotForTopic are reported as ErrInvalidToken,
+// TooManyRequests (429) as a throttle rate limit.
+func parseAPNsError(resp *http.Response) error {
+	var errResp struct {
+		Reason string `json:"reason"`
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	_ = json.Unmarshal(raw, &errResp)
+
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		errResp.Reason == "BadDeviceToken",
+		errResp.Reason == "Unregistered",
+		errResp.Reason == "DeviceTokenNotForTopic":
+		return fmt.Errorf("apns %d %s: %w", resp.StatusCode, errResp.Reason, ErrInvalidToken)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		err := fmt.Errorf("apns error: %d - %s", resp.StatusCode, errResp.Reason)
+		return throttle.RateLimited(err, throttle.ParseRetryAfter(resp.Header.Get("Retry-After")))
+	default:
+		return fmt.Errorf("apns error: %d - %s", resp.StatusCode, errResp.Reason)
+	}
+}
+
+// providerToken returns the cached ES256 provider JWT, re-signing it before
+// Apple's one-hour expiry.
+func (c *APNsClient) providerToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.jwt != "" && time.Since(c.issuedAt) < apnsTokenLifetime {
+		return c.jwt, nil
+	}
+
+	now := time.Now()
+	token, err := signJWT(
+		map[string]any{"alg": "ES256", "kid": c.keyID},
+		map[string]any{"iss": c.teamID, "iat": now.Unix()},
+		c.key,
+	)
+	if err != nil {
+		return "", fmt.Errorf("apns provider token: %w", err)
+	}
+
+	c.jwt = token
+	c.issuedAt = now
+	return c.jwt, nil
+}
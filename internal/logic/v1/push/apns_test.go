@@ -0,0 +1,149 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/throttle"
+)
+
+// newAPNsStandIn starts a local APNs that verifies the ES256 provider token and answers
+// with respond, and returns a client that uses it.
+func newAPNsStandIn(t *testing.T, respond func(w http.ResponseWriter)) *APNsClient {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/3/device/device-1" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "bearer ")
+		if !ok {
+			t.Errorf("Authorization = %q, want a bearer provider token", r.Header.Get("Authorization"))
+		}
+		header, claims := verifyES256(t, token, &key.PublicKey)
+		if header["alg"] != "ES256" || header["kid"] != "ABC123DEFG" {
+			t.Errorf("provider token header = %v", header)
+		}
+		if claims["iss"] != "TEAM123456" || claims["iat"] == nil {
+			t.Errorf("provider token claims = %v", claims)
+		}
+		if got := r.Header.Get("Apns-Topic"); got != "com.example.app" {
+			t.Errorf("apns-topic = %q", got)
+		}
+
+		var payload struct {
+			APS apnsAPS `json:"aps"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode apns payload: %v", err)
+		}
+		if payload.APS.Alert == nil || payload.APS.Alert.Title != "Hello" {
+			t.Errorf("apns payload = %+v", payload.APS)
+		}
+		respond(w)
+	}))
+	t.Cleanup(server.Close)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewAPNsClient(APNsConfig{
+		KeyPEM:     pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		KeyID:      "ABC123DEFG",
+		TeamID:     "TEAM123456",
+		Topic:      "com.example.app",
+		BaseURL:    server.URL,
+		HTTPClient: server.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// verifyES256 checks the r||s signature of a compact JWS and returns its header and claims.
+func verifyES256(t *testing.T, token string, pub *ecdsa.PublicKey) (header, claims map[string]any) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("jwt has %d parts, want 3", len(parts))
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(sig) != 64 {
+		t.Fatalf("jwt signature is not a 64-byte r||s value: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(pub, digest[:], r, s) {
+		t.Fatal("jwt signature does not verify")
+	}
+	return decodeJWTPart(t, parts[0]), decodeJWTPart(t, parts[1])
+}
+
+func TestAPNsClientSend(t *testing.T) {
+	client := newAPNsStandIn(t, func(w http.ResponseWriter) {
+		w.Header().Set("Apns-Id", "EC1BF194-B3B2-424A-89A9-5A918A6E6B5D")
+	})
+
+	if err := client.Send(context.Background(), "device-1", &Message{Title: "Hello", Body: "World"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+}
+
+func TestAPNsClientErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		reason  string
+		invalid bool
+		limited bool
+	}{
+		{name: "bad device token", status: http.StatusBadRequest, reason: "BadDeviceToken", invalid: true},
+		{name: "unregistered", status: http.StatusGone, reason: "Unregistered", invalid: true},
+		{name: "gone without reason", status: http.StatusGone, invalid: true},
+		{name: "wrong topic", status: http.StatusBadRequest, reason: "DeviceTokenNotForTopic", invalid: true},
+		{name: "payload too large", status: http.StatusRequestEntityTooLarge, reason: "PayloadTooLarge"},
+		{name: "server error", status: http.StatusInternalServerError, reason: "InternalServerError"},
+		{name: "too many requests", status: http.StatusTooManyRequests, reason: "TooManyRequests", limited: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newAPNsStandIn(t, func(w http.ResponseWriter) {
+				w.WriteHeader(tt.status)
+				if tt.reason != "" {
+					_, _ = io.WriteString(w, `{"reason":"`+tt.reason+`"}`)
+				}
+			})
+
+			err := client.Send(context.Background(), "device-1", &Message{Title: "Hello"})
+			if err == nil {
+				t.Fatal("Send succeeded, want an error")
+			}
+			if got := errors.Is(err, ErrInvalidToken); got != tt.invalid {
+				t.Errorf("errors.Is(%v, ErrInvalidToken) = %v, want %v", err, got, tt.invalid)
+			}
+			if got := errors.Is(err, throttle.ErrRateLimited); got != tt.limited {
+				t.Errorf("errors.Is(%v, throttle.ErrRateLimited) = %v, want %v", err, got, tt.limited)
+			}
+		})
+	}
+}
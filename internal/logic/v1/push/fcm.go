@@ -0,0 +1,312 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/throttle"
+)
+
+const (
+	// DefaultFCMBaseURL is the production FCM HTTP v1 endpoint.
+	DefaultFCMBaseURL = "https://fcm.googleapis.com"
+
+	fcmScope           = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmJWTGrantType    = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	fcmTokenLifetime   = time.Hour
+	fcmTokenRefreshGap = time.Minute
+)
+
+// FCMConfig configures the FCM HTTP v1 client.
+type FCMConfig struct {
+	ProjectID       string       // Firebase project ID
+	CredentialsJSON []byte       // Google service-account key file contents
+	BaseURL         string       // Overrides DefaultFCMBaseURL (e.g. an httptest server)
+	TokenURL        string       // Overrides the service account's token_uri
+	HTTPClient      *http.Client // Optional; defaults to a client with a 10s timeout
+}
+
+// serviceAccount is the subset of a Google service-account key file used for OAuth2.
+type serviceAccount struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+	ProjectID    string `json:"project_id"`
+}
+
+// FCMClient sends messages through the FCM HTTP v1 API.
+type FCMClient struct {
+	projectID   string
+	baseURL     string
+	tokenURL    string
+	clientEmail string
+	keyID       string
+	key         crypto.Signer
+	httpClient  *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMClient creates an FCM client from a service-account key.
+func NewFCMClient(cfg FCMConfig) (*FCMClient, error) {
+	var sa serviceAccount
+	if err := json.Unmarshal(cfg.CredentialsJSON, &sa); err != nil {
+		return nil, fmt.Errorf("parse fcm credentials: %w", err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, errors.New("fcm credentials missing client_email or private_key")
+	}
+	key, err := parsePrivateKeyPEM([]byte(sa.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("fcm credentials: %w", err)
+	}
+
+	projectID := cfg.ProjectID
+	if projectID == "" {
+		projectID = sa.ProjectID
+	}
+	if projectID == "" {
+		return nil, errors.New("fcm project id is required")
+	}
+
+	tokenURL := cfg.TokenURL
+	if tokenURL == "" {
+		tokenURL = sa.TokenURI
+	}
+	if tokenURL == "" {
+		return nil, errors.New("fcm token url is required")
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultFCMBaseURL
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &FCMClient{
+		projectID:   projectID,
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		tokenURL:    tokenURL,
+		clientEmail: sa.ClientEmail,
+		keyID:       sa.PrivateKeyID,
+		key:         key,
+		httpClient:  httpClient,
+	}, nil
+}
+
+// fcmRequest is the body of projects.messages.send.
+type fcmRequest struct {
+	Message fcmMessage `json:"message"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification *fcmNotification  `json:"notification,omitempty"`
+	Data         map[string]string `json:"data,omitempty"`
+	Android      *fcmAndroid       `json:"android,omitempty"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+type fcmAndroid struct {
+	CollapseKey  string                  `json:"collapse_key,omitempty"`
+	TTL          string                  `json:"ttl,omitempty"`
+	Notification *fcmAndroidNotification `json:"notification,omitempty"`
+}
+
+type fcmAndroidNotification struct {
+	NotificationCount *int `json:"notification_count,omitempty"`
+}
+
+// fcmErrorResponse is the google.rpc.Status error envelope returned by FCM.
+type fcmErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			Type            string     `json:"@type"`
+			ErrorCode       string     `json:"errorCode"` // google.firebase.fcm.v1.FcmError
+			FieldViolations []struct { // google.rpc.BadRequest
+				Field       string `json:"field"`
+				Description string `json:"description"`
+			} `json:"fieldViolations"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// Send delivers msg to a single FCM registration token.
+func (c *FCMClient) Send(ctx context.Context, token string, msg *Message) error {
+	body, err := json.Marshal(fcmRequest{Message: buildFCMMessage(token, msg)})
+	if err != nil {
+		return fmt.Errorf("marshal fcm message: %w", err)
+	}
+
+	accessToken, err := c.getAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/projects/%s/messages:send", c.baseURL, url.PathEscape(c.projectID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create fcm request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("request fcm: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return parseFCMError(resp)
+}
+
+func buildFCMMessage(token string, msg *Message) fcmMessage {
+	m := fcmMessage{
+		Token: token,
+		Data:  msg.Data,
+	}
+	if msg.Title != "" || msg.Body != "" {
+		m.Notification = &fcmNotification{Title: msg.Title, Body: msg.Body}
+	}
+
+	android := &fcmAndroid{CollapseKey: msg.CollapseID}
+	if msg.TTL > 0 {
+		android.TTL = strconv.FormatInt(int64(msg.TTL/time.Second), 10) + "s"
+	}
+	if msg.Badge != nil {
+		android.Notification = &fcmAndroidNotification{NotificationCount: msg.Badge}
+	}
+	if android.CollapseKey != "" || android.TTL != "" || android.Notification != nil {
+		m.Android = android
+	}
+	return m
+}
+
+// parseFCMError maps an FCM error response to an error. UNREGISTERED (the app was
+// uninstalled or the token expired) and INVALID_ARGUMENT about the message's token
+// are reported as ErrInvalidToken, QUOTA_EXCEEDED (429) as a throttle rate limit.
+// Other errors, including a 404 for a wrong project or endpoint, leave tokens alone.
+func parseFCMError(resp *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	var errResp fcmErrorResponse
+	if err := json.Unmarshal(raw, &errResp); err != nil {
+		err := fmt.Errorf("fcm error: %d - %s", resp.StatusCode, string(raw))
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return throttle.RateLimited(err, throttle.ParseRetryAfter(resp.Header.Get("Retry-After")))
+		}
+		return err
+	}
+
+	for _, d := range errResp.Error.Details {
+		if d.ErrorCode == "UNREGISTERED" {
+			return fmt.Errorf("fcm %s: %w", d.ErrorCode, ErrInvalidToken)
+		}
+		if errResp.Error.Status != "INVALID_ARGUMENT" {
+			continue
+		}
+		for _, v := range d.FieldViolations {
+			if v.Field == "message.token" {
+				return fmt.Errorf("fcm %s %s: %s: %w", errResp.Error.Status, v.Field, v.Description, ErrInvalidToken)
+			}
+		}
+	}
+
+	err := fmt.Errorf("fcm error: %d %s - %s", resp.StatusCode, errResp.Error.Status, errResp.Error.Message)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return throttle.RateLimited(err, throttle.ParseRetryAfter(resp.Header.Get("Retry-After")))
+	}
+	return err
+}
+
+// getAccessToken returns a cached OAuth2 access token, exchanging a signed
+// service-account JWT for a new one when the cached token is about to expire.
+func (c *FCMClient) getAccessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accessToken != "" && time.Now().Add(fcmTokenRefreshGap).Before(c.expiresAt) {
+		return c.accessToken, nil
+	}
+
+	now := time.Now()
+	header := map[string]any{"alg": "RS256", "typ": "JWT"}
+	if c.keyID != "" {
+		header["kid"] = c.keyID
+	}
+	assertion, err := signJWT(header, map[string]any{
+		"iss":   c.clientEmail,
+		"scope": fcmScope,
+		"aud":   c.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(fcmTokenLifetime).Unix(),
+	}, c.key)
+	if err != nil {
+		return "", fmt.Errorf("fcm oauth assertion: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", fcmJWTGrantType)
+	form.Set("assertion", assertion)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("create oauth request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req) //nolint:gosec
+	if err != nil {
+		return "", fmt.Errorf("request oauth token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return "", fmt.Errorf("oauth token error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("decode oauth token: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return "", errors.New("oauth token response missing access_token")
+	}
+
+	c.accessToken = tok.AccessToken
+	c.expiresAt = now.Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return c.accessToken, nil
+}
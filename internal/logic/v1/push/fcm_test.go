@@ -0,0 +1,208 @@
+package push
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const fcmTestEmail = "sender@example.iam.gserviceaccount.com"
+
+// fcmStandIn is a local FCM: it serves the OAuth2 token endpoint, verifying the RS256
+// assertion, and messages:send, answering with respond.
+type fcmStandIn struct {
+	*httptest.Server
+	exchanges int
+}
+
+// newFCMStandIn starts an FCM stand-in and returns it with a client that uses it.
+func newFCMStandIn(t *testing.T, respond func(w http.ResponseWriter)) (*fcmStandIn, *FCMClient) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fcm := &fcmStandIn{}
+	fcm.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			fcm.exchanges++
+			if got := r.FormValue("grant_type"); got != fcmJWTGrantType {
+				t.Errorf("grant_type = %q, want %q", got, fcmJWTGrantType)
+			}
+			header, claims := verifyRS256(t, r.FormValue("assertion"), &key.PublicKey)
+			if header["alg"] != "RS256" || header["kid"] != "key-1" {
+				t.Errorf("assertion header = %v", header)
+			}
+			if claims["iss"] != fcmTestEmail || claims["scope"] != fcmScope || claims["aud"] != fcm.URL+"/token" {
+				t.Errorf("assertion claims = %v", claims)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"access_token":"access-1","expires_in":3600,"token_type":"Bearer"}`)
+		case "/v1/projects/demo/messages:send":
+			if got := r.Header.Get("Authorization"); got != "Bearer access-1" {
+				t.Errorf("Authorization = %q, want the exchanged access token", got)
+			}
+			var body fcmRequest
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decode fcm message: %v", err)
+			}
+			if body.Message.Token != "device-1" || body.Message.Notification == nil || body.Message.Notification.Title != "Hello" {
+				t.Errorf("fcm message = %+v", body.Message)
+			}
+			respond(w)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(fcm.Close)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials, err := json.Marshal(serviceAccount{
+		ClientEmail:  fcmTestEmail,
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		PrivateKeyID: "key-1",
+		TokenURI:     fcm.URL + "/token",
+		ProjectID:    "demo",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewFCMClient(FCMConfig{CredentialsJSON: credentials, BaseURL: fcm.URL, HTTPClient: fcm.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fcm, client
+}
+
+// verifyRS256 checks the signature of a compact JWS and returns its header and claims.
+func verifyRS256(t *testing.T, token string, pub *rsa.PublicKey) (header, claims map[string]any) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("jwt has %d parts, want 3", len(parts))
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("decode jwt signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+		t.Fatalf("verify jwt signature: %v", err)
+	}
+	return decodeJWTPart(t, parts[0]), decodeJWTPart(t, parts[1])
+}
+
+func decodeJWTPart(t *testing.T, part string) map[string]any {
+	t.Helper()
+	raw, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		t.Fatalf("decode jwt part: %v", err)
+	}
+	var v map[string]any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		t.Fatalf("parse jwt part: %v", err)
+	}
+	return v
+}
+
+func TestFCMClientSend(t *testing.T) {
+	fcm, client := newFCMStandIn(t, func(w http.ResponseWriter) {
+		_, _ = io.WriteString(w, `{"name":"projects/demo/messages/1"}`)
+	})
+
+	msg := &Message{Title: "Hello", Body: "World"}
+	for range 2 {
+		if err := client.Send(context.Background(), "device-1", msg); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	if fcm.exchanges != 1 {
+		t.Errorf("token exchanges = %d, want 1 (cached)", fcm.exchanges)
+	}
+}
+
+func TestFCMClientErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		invalid bool
+	}{
+		{
+			name:    "unregistered",
+			status:  http.StatusNotFound,
+			body:    `{"error":{"code":404,"status":"NOT_FOUND","details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNREGISTERED"}]}}`,
+			invalid: true,
+		},
+		{
+			name:    "unregistered on bad request",
+			status:  http.StatusBadRequest,
+			body:    `{"error":{"code":400,"status":"INVALID_ARGUMENT","details":[{"errorCode":"UNREGISTERED"}]}}`,
+			invalid: true,
+		},
+		{
+			name:   "invalid argument",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"code":400,"status":"INVALID_ARGUMENT","details":[{"errorCode":"INVALID_ARGUMENT"}]}}`,
+		},
+		{
+			name:    "invalid token field",
+			status:  http.StatusBadRequest,
+			body:    `{"error":{"code":400,"status":"INVALID_ARGUMENT","details":[{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[{"field":"message.token","description":"The registration token is not a valid FCM registration token"}]}]}}`,
+			invalid: true,
+		},
+		{
+			name:   "invalid other field",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"code":400,"status":"INVALID_ARGUMENT","details":[{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[{"field":"message.android.ttl","description":"Invalid duration"}]}]}}`,
+		},
+		{
+			name:   "wrong project",
+			status: http.StatusNotFound,
+			body:   `{"error":{"code":404,"status":"NOT_FOUND","message":"Requested entity was not found."}}`,
+		},
+		{
+			name:   "not found page",
+			status: http.StatusNotFound,
+			body:   `Not Found`,
+		},
+		{
+			name:   "server error",
+			status: http.StatusInternalServerError,
+			body:   `{"error":{"code":500,"status":"INTERNAL","message":"boom"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client := newFCMStandIn(t, func(w http.ResponseWriter) {
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.body)
+			})
+
+			err := client.Send(context.Background(), "device-1", &Message{Title: "Hello"})
+			if err == nil {
+				t.Fatal("Send succeeded, want an error")
+			}
+			if got := errors.Is(err, ErrInvalidToken); got != tt.invalid {
+				t.Errorf("errors.Is(%v, ErrInvalidToken) = %v, want %v", err, got, tt.invalid)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Package push provides mobile push notification providers for API version 1.
+//
+// Three providers are supported:
+//   - FCM (Firebase Cloud Messaging) via the HTTP v1 API with service-account OAuth2
+//   - APNs (Apple Push Notification service) via token-based (JWT, .p8 key) HTTP/2
+//   - Web Push (RFC 8030) with VAPID authentication and aes128gcm payload encryption
+//
+// The FCM and APNs clients accept a configurable base URL so they can be pointed at
+// local httptest stand-ins instead of the real provider endpoints. Web Push messages
+// go to the endpoint of each browser subscription.
+//
+// Invalid-token responses from FCM and APNs are reported as ErrInvalidToken; the
+// Dispatcher uses this to deactivate device tokens automatically. Expired browser
+// subscriptions are reported as ErrSubscriptionExpired.
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/health"
+	"github.com/duynhne/notification-service/internal/logic/v1/throttle"
+)
+
+// Platform identifies the push provider a device token belongs to.
+type Platform string
+
+const (
+	PlatformAndroid Platform = "android" // Delivered via FCM
+	PlatformIOS     Platform = "ios"     // Delivered via APNs
+)
+
+// Sentinel errors for push delivery.
+var (
+	// ErrInvalidToken indicates the provider rejected the device token permanently
+	// (unregistered app, malformed token, wrong environment). The token should be deactivated.
+	ErrInvalidToken = errors.New("invalid device token")
+
+	// ErrUnsupportedPlatform indicates no provider is configured for the platform.
+	ErrUnsupportedPlatform = errors.New("unsupported push platform")
+)
+
+// Message is a provider-agnostic push payload.
+type Message struct {
+	Title      string            // Alert title
+	Body       string            // Alert body
+	Badge      *int              // App icon badge count (nil leaves the badge unchanged)
+	Data       map[string]string // Custom key/value data delivered to the app
+	CollapseID string            // Newer messages with the same ID replace older undelivered ones
+	TTL        time.Duration     // How long the provider keeps the message if the device is offline (0 = provider default)
+}
+
+// Sender delivers a push message to a single device token.
+type Sender interface {
+	Send(ctx context.Context, token string, msg *Message) error
+}
+
+// WithBreaker routes sender's calls through a circuit breaker. Invalid-token and
+// rate-limit responses do not count against the provider.
+func WithBreaker(sender Sender, breaker *health.Breaker) Sender {
+	return &breakerSender{sender: sender, breaker: breaker}
+}
+
+type breakerSender struct {
+	sender  Sender
+	breaker *health.Breaker
+}
+
+func (s *breakerSender) Send(ctx context.Context, token string, msg *Message) error {
+	return s.breaker.Call(func() error {
+		return s.sender.Send(ctx, token, msg)
+	}, func(err error) bool {
+		return !errors.Is(err, ErrInvalidToken) && !errors.Is(err, throttle.ErrRateLimited)
+	})
+}
+
+// WithThrottle paces sender's calls with limiter under the "push" channel. Wrap it
+// around WithBreaker so time spent waiting for a slot is not counted as latency.
+func WithThrottle(sender Sender, limiter *throttle.Limiter, provider string) Sender {
+	return &throttledSender{sender: sender, limiter: limiter, provider: provider}
+}
+
+type throttledSender struct {
+	sender   Sender
+	limiter  *throttle.Limiter
+	provider string
+}
+
+func (s *throttledSender) Send(ctx context.Context, token string, msg *Message) error {
+	return s.limiter.Do(ctx, "push", s.provider, func() error {
+		return s.sender.Send(ctx, token, msg)
+	})
+}
+
+// TokenDeactivator marks device tokens as no longer deliverable.
+type TokenDeactivator interface {
+	DeactivateToken(ctx context.Context, token string) error
+}
+
+// Target is a single device to deliver to.
+type Target struct {
+	Platform Platform
+	Token    string
+}
+
+// Result summarizes a fan-out delivery.
+type Result struct {
+	Sent        int // Messages accepted by the provider
+	Failed      int // Messages rejected for transient or unknown reasons, or for a token that was not deactivated
+	Deactivated int // Tokens deactivated after an invalid-token response
+}
+
+// Dispatcher routes messages to the provider for each target platform and
+// deactivates tokens the provider reports as invalid.
+type Dispatcher struct {
+	senders     map[Platform]Sender
+	deactivator TokenDeactivator
+}
+
+// NewDispatcher creates a dispatcher. deactivator may be nil, in which case
+// invalid tokens are counted as failed and left active.
+func NewDispatcher(deactivator TokenDeactivator) *Dispatcher {
+	return &Dispatcher{
+		senders:     make(map[Platform]Sender),
+		deactivator: deactivator,
+	}
+}
+
+// Register sets the sender used for a platform.
+func (d *Dispatcher) Register(platform Platform, sender Sender) {
+	d.senders[platform] = sender
+}
+
+// Supports reports whether a sender is registered for the platform.
+func (d *Dispatcher) Supports(platform Platform) bool {
+	_, ok := d.senders[platform]
+	return ok
+}
+
+// Dispatch sends msg to every target. Per-target failures do not abort the fan-out;
+// the first non-token error is returned alongside the result.
+func (d *Dispatcher) Dispatch(ctx context.Context, targets []Target, msg *Message) (Result, error) {
+	var result Result
+	var firstErr error
+
+	for _, target := range targets {
+		sender, ok := d.senders[target.Platform]
+		if !ok {
+			result.Failed++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("platform %q: %w", target.Platform, ErrUnsupportedPlatform)
+			}
+			continue
+		}
+
+		err := sender.Send(ctx, target.Token, msg)
+		switch {
+		case err == nil:
+			result.Sent++
+		case errors.Is(err, ErrInvalidToken):
+			if d.deactivator == nil {
+				result.Failed++
+				continue
+			}
+			if derr := d.deactivator.DeactivateToken(ctx, target.Token); derr != nil {
+				result.Failed++
+				if firstErr == nil {
+					firstErr = fmt.Errorf("deactivate token: %w", derr)
+				}
+				continue
+			}
+			result.Deactivated++
+		default:
+			result.Failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return result, firstErr
+}
@@ -0,0 +1,71 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// senderFunc adapts a function to Sender.
+type senderFunc func(ctx context.Context, token string, msg *Message) error
+
+func (f senderFunc) Send(ctx context.Context, token string, msg *Message) error {
+	return f(ctx, token, msg)
+}
+
+// deactivatorFunc adapts a function to TokenDeactivator.
+type deactivatorFunc func(ctx context.Context, token string) error
+
+func (f deactivatorFunc) DeactivateToken(ctx context.Context, token string) error {
+	return f(ctx, token)
+}
+
+func TestDispatcherDeactivation(t *testing.T) {
+	targets := []Target{
+		{Platform: PlatformAndroid, Token: "valid"},
+		{Platform: PlatformAndroid, Token: "invalid"},
+	}
+	sender := senderFunc(func(_ context.Context, token string, _ *Message) error {
+		if token == "invalid" {
+			return ErrInvalidToken
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name        string
+		deactivator TokenDeactivator
+		want        Result
+		wantErr     bool
+	}{
+		{
+			name:        "deactivated",
+			deactivator: deactivatorFunc(func(context.Context, string) error { return nil }),
+			want:        Result{Sent: 1, Deactivated: 1},
+		},
+		{
+			name: "no deactivator",
+			want: Result{Sent: 1, Failed: 1},
+		},
+		{
+			name:        "deactivation fails",
+			deactivator: deactivatorFunc(func(context.Context, string) error { return errors.New("db down") }),
+			want:        Result{Sent: 1, Failed: 1},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher := NewDispatcher(tt.deactivator)
+			dispatcher.Register(PlatformAndroid, sender)
+
+			got, err := dispatcher.Dispatch(context.Background(), targets, &Message{Title: "Hello"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Dispatch error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Dispatch = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
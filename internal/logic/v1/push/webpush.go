@@ -0,0 +1,345 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/egress"
+	"github.com/duynhne/notification-service/internal/logic/v1/health"
+	"github.com/duynhne/notification-service/internal/logic/v1/throttle"
+)
+
+const (
+	// webPushRecordSize is the aes128gcm record size advertised in the header (RFC 8188).
+	// Push services must accept at least 4096 bytes, so the payload is sent as a single record.
+	webPushRecordSize = 4096
+	// webPushMaxPayload is the largest plaintext that fits one 4096-byte record:
+	// 4096 - 86 header bytes - 16 byte GCM tag - 1 padding delimiter.
+	webPushMaxPayload = 3993
+
+	vapidTokenLifetime = 12 * time.Hour
+	webPushDefaultTTL  = 4 * 7 * 24 * time.Hour
+)
+
+// ErrSubscriptionExpired indicates the push service no longer accepts messages for a
+// subscription (404 Not Found or 410 Gone). The subscription should be removed.
+var ErrSubscriptionExpired = errors.New("push subscription expired")
+
+// Subscription is a browser PushSubscription (the result of pushManager.subscribe()).
+type Subscription struct {
+	Endpoint string // Push service URL unique to this browser installation
+	P256dh   string // Base64url user agent ECDH public key (uncompressed P-256 point)
+	Auth     string // Base64url 16-byte authentication secret
+}
+
+// WebPushConfig configures the Web Push (RFC 8030) client.
+type WebPushConfig struct {
+	VAPIDPrivateKey string       // Base64url raw P-256 private scalar (as generated by web-push tooling)
+	Subject         string       // Contact URI for the push service operator (mailto: or https:)
+	HTTPClient      *http.Client // Optional; defaults to an egress.Client with a 10s timeout
+	// Optional; gives every push service host a circuit breaker under the "webpush" channel
+	Health *health.Registry
+	// Optional; paces sends to every push service host under the "webpush" channel
+	Limiter *throttle.Limiter
+}
+
+// WebPushClient sends encrypted messages to browser push services, authenticating
+// with VAPID (RFC 8292) and encrypting payloads with aes128gcm (RFC 8291).
+type WebPushClient struct {
+	key        *ecdsa.PrivateKey
+	publicKey  string
+	subject    string
+	httpClient *http.Client
+	health     *health.Registry
+	limiter    *throttle.Limiter
+
+	mu         sync.Mutex
+	vapidCache map[string]vapidToken // keyed by push service origin
+}
+
+type vapidToken struct {
+	jwt       string
+	expiresAt time.Time
+}
+
+// webPushPayload is the JSON document delivered to the service worker's push event.
+type webPushPayload struct {
+	Title string            `json:"title,omitempty"`
+	Body  string            `json:"body,omitempty"`
+	Badge *int              `json:"badge,omitempty"`
+	Tag   string            `json:"tag,omitempty"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+// NewWebPushClient creates a Web Push client from a VAPID key pair.
+func NewWebPushClient(cfg WebPushConfig) (*WebPushClient, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(cfg.VAPIDPrivateKey, "="))
+	if err != nil {
+		return nil, fmt.Errorf("decode vapid private key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse vapid private key: %w", err)
+	}
+	pub, err := key.PublicKey.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("encode vapid public key: %w", err)
+	}
+	if !strings.HasPrefix(cfg.Subject, "mailto:") && !strings.HasPrefix(cfg.Subject, "https:") {
+		return nil, errors.New("vapid subject must be a mailto: or https: URI")
+	}
+
+	// Endpoints are chosen by users, so the default client refuses internal addresses
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = egress.Client(10 * time.Second)
+	}
+
+	return &WebPushClient{
+		key:        key,
+		publicKey:  base64.RawURLEncoding.EncodeToString(pub),
+		subject:    cfg.Subject,
+		httpClient: httpClient,
+		health:     cfg.Health,
+		limiter:    cfg.Limiter,
+		vapidCache: make(map[string]vapidToken),
+	}, nil
+}
+
+// PublicKey returns the base64url VAPID public key browsers pass as applicationServerKey.
+func (c *WebPushClient) PublicKey() string {
+	return c.publicKey
+}
+
+// Send encrypts msg for the subscription and posts it to the push service.
+func (c *WebPushClient) Send(ctx context.Context, sub Subscription, msg *Message) error {
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Host == "" {
+		return fmt.Errorf("invalid endpoint %q: %w", sub.Endpoint, ErrSubscriptionExpired)
+	}
+
+	plaintext, err := json.Marshal(webPushPayload{
+		Title: msg.Title,
+		Body:  msg.Body,
+		Badge: msg.Badge,
+		Tag:   msg.CollapseID,
+		Data:  msg.Data,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal web push payload: %w", err)
+	}
+	body, err := encryptWebPush(sub, plaintext)
+	if err != nil {
+		return err
+	}
+
+	authorization, err := c.vapidAuthorization(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return err
+	}
+
+	ttl := msg.TTL
+	if ttl <= 0 {
+		ttl = webPushDefaultTTL
+	}
+	topic := webPushTopic(msg.CollapseID)
+
+	// Push services are tracked and paced by host: one browser vendor's outage or
+	// rate limit must not hold up the others
+	breaker := c.health.Breaker("webpush", endpoint.Host)
+	return c.limiter.Do(ctx, "webpush", endpoint.Host, func() error {
+		return breaker.Call(func() error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+			if err != nil {
+				return fmt.Errorf("create web push request: %w", err)
+			}
+			req.Header.Set("Authorization", authorization)
+			req.Header.Set("Content-Encoding", "aes128gcm")
+			req.Header.Set("Content-Type", "application/octet-stream")
+			req.Header.Set("Ttl", strconv.FormatInt(int64(ttl/time.Second), 10))
+			if topic != "" {
+				req.Header.Set("Topic", topic)
+			}
+			return c.post(req)
+		}, func(err error) bool {
+			return !errors.Is(err, ErrSubscriptionExpired) && !errors.Is(err, throttle.ErrRateLimited)
+		})
+	})
+}
+
+// post delivers a prepared request to the push service. 429 responses are reported
+// as a throttle rate limit.
+func (c *WebPushClient) post(req *http.Request) error {
+	resp, err := c.httpClient.Do(req) //nolint:gosec // endpoint is checked on registration and the client refuses internal addresses
+	if err != nil {
+		return fmt.Errorf("request push service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return fmt.Errorf("push service %d: %w", resp.StatusCode, ErrSubscriptionExpired)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		err := fmt.Errorf("push service error: %d - %s", resp.StatusCode, string(raw))
+		return throttle.RateLimited(err, throttle.ParseRetryAfter(resp.Header.Get("Retry-After")))
+	default:
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return fmt.Errorf("push service error: %d - %s", resp.StatusCode, string(raw))
+	}
+}
+
+// webPushTopic converts a collapse ID to a Topic header value: at most 32 characters
+// from the URL-safe base64 alphabet (RFC 8030 §5.4). Other IDs are hashed.
+func webPushTopic(collapseID string) string {
+	if collapseID == "" {
+		return ""
+	}
+	if len(collapseID) <= 32 && strings.Trim(collapseID,
+		"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_") == "" {
+		return collapseID
+	}
+	sum := sha256.Sum256([]byte(collapseID))
+	return base64.RawURLEncoding.EncodeToString(sum[:24])
+}
+
+// vapidAuthorization returns the "vapid t=..., k=..." header for a push service origin.
+func (c *WebPushClient) vapidAuthorization(audience string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tok, ok := c.vapidCache[audience]
+	if !ok || time.Until(tok.expiresAt) < time.Hour {
+		expiresAt := time.Now().Add(vapidTokenLifetime)
+		jwt, err := signJWT(
+			map[string]any{"typ": "JWT", "alg": "ES256"},
+			map[string]any{"aud": audience, "exp": expiresAt.Unix(), "sub": c.subject},
+			c.key,
+		)
+		if err != nil {
+			return "", fmt.Errorf("vapid token: %w", err)
+		}
+		tok = vapidToken{jwt: jwt, expiresAt: expiresAt}
+		c.vapidCache[audience] = tok
+	}
+
+	return "vapid t=" + tok.jwt + ", k=" + c.publicKey, nil
+}
+
+// encryptWebPush encrypts plaintext for a subscription using the aes128gcm content
+// coding with the Web Push key derivation of RFC 8291 §3.4.
+func encryptWebPush(sub Subscription, plaintext []byte) ([]byte, error) {
+	if len(plaintext) > webPushMaxPayload {
+		return nil, fmt.Errorf("web push payload is %d bytes, max %d", len(plaintext), webPushMaxPayload)
+	}
+
+	uaPublicRaw, err := decodeBase64URL(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("decode p256dh: %w", err)
+	}
+	authSecret, err := decodeBase64URL(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("decode auth secret: %w", err)
+	}
+	if len(authSecret) != 16 {
+		return nil, fmt.Errorf("auth secret must be 16 bytes, got %d", len(authSecret))
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicRaw)
+	if err != nil {
+		return nil, fmt.Errorf("parse p256dh: %w", err)
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate ephemeral key: %w", err)
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("ecdh: %w", err)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+
+	cek, nonce := deriveWebPushKeys(ecdhSecret, authSecret, uaPublicRaw, asPublic, salt)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+
+	// Single final record: plaintext followed by the 0x02 last-record delimiter.
+	padded := append(append(make([]byte, 0, len(plaintext)+1), plaintext...), 0x02)
+
+	// Header: salt(16) || rs(4) || idlen(1) || keyid(65 = as_public)
+	header := make([]byte, 0, 21+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return gcm.Seal(header, nonce, padded, nil), nil
+}
+
+// deriveWebPushKeys implements the RFC 8291 §3.3/§3.4 and RFC 8188 §2.2 HKDF steps,
+// returning the 16-byte content encryption key and the 12-byte nonce.
+func deriveWebPushKeys(ecdhSecret, authSecret, uaPublic, asPublic, salt []byte) (cek, nonce []byte) {
+	// PRK_key = HMAC-SHA-256(auth_secret, ecdh_secret)
+	prkKey := hmacSHA256(authSecret, ecdhSecret)
+
+	// IKM = HMAC-SHA-256(PRK_key, "WebPush: info" || 0x00 || ua_public || as_public || 0x01)
+	keyInfo := make([]byte, 0, 14+len(uaPublic)+len(asPublic)+1)
+	keyInfo = append(keyInfo, "WebPush: info\x00"...)
+	keyInfo = append(keyInfo, uaPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+	keyInfo = append(keyInfo, 0x01)
+	ikm := hmacSHA256(prkKey, keyInfo)
+
+	// PRK = HMAC-SHA-256(salt, IKM)
+	prk := hmacSHA256(salt, ikm)
+
+	cek = hmacSHA256(prk, []byte("Content-Encoding: aes128gcm\x00\x01"))[:16]
+	nonce = hmacSHA256(prk, []byte("Content-Encoding: nonce\x00\x01"))[:12]
+	return cek, nonce
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// decodeBase64URL accepts padded or unpadded base64url, as browsers differ.
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,297 @@
+package push
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/egress"
+	"github.com/duynhne/notification-service/internal/logic/v1/throttle"
+)
+
+// subscriber is the browser side of a push subscription.
+type subscriber struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+}
+
+func newSubscriber(t *testing.T) *subscriber {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	if _, err := rand.Read(auth); err != nil {
+		t.Fatal(err)
+	}
+	return &subscriber{key: key, auth: auth}
+}
+
+func (s *subscriber) subscription(endpoint string) Subscription {
+	return Subscription{
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(s.key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(s.auth),
+	}
+}
+
+// decrypt decodes an aes128gcm body (RFC 8188) with the Web Push key derivation of
+// RFC 8291 §3.4, as a browser would.
+func (s *subscriber) decrypt(t *testing.T, body []byte) []byte {
+	t.Helper()
+	if len(body) < 21 {
+		t.Fatalf("body is %d bytes, shorter than the aes128gcm header", len(body))
+	}
+	salt, recordSize, idLen := body[:16], binary.BigEndian.Uint32(body[16:20]), int(body[20])
+	if recordSize < 18 || len(body) < 21+idLen {
+		t.Fatalf("bad aes128gcm header: rs=%d idlen=%d", recordSize, idLen)
+	}
+	asPublicRaw, ciphertext := body[21:21+idLen], body[21+idLen:]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicRaw)
+	if err != nil {
+		t.Fatalf("keyid is not a P-256 public key: %v", err)
+	}
+	ecdhSecret, err := s.key.ECDH(asPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), s.key.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublicRaw...)
+	ikm, err := hkdf.Key(sha256.New, ecdhSecret, s.auth, string(keyInfo), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	padded, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatalf("decrypt payload: %v", err)
+	}
+
+	// A single final record ends with the 0x02 delimiter, optionally followed by zero padding
+	end := len(padded) - 1
+	for end >= 0 && padded[end] == 0 {
+		end--
+	}
+	if end < 0 || padded[end] != 0x02 {
+		t.Fatal("payload has no last-record delimiter")
+	}
+	return padded[:end]
+}
+
+// verifyVAPID checks a "vapid t=<jwt>, k=<public key>" header (RFC 8292) and returns the claims.
+func verifyVAPID(t *testing.T, authorization string) (publicKey string, claims map[string]any) {
+	t.Helper()
+	params, ok := strings.CutPrefix(authorization, "vapid ")
+	if !ok {
+		t.Fatalf("Authorization = %q, want the vapid scheme", authorization)
+	}
+	var token string
+	for param := range strings.SplitSeq(params, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch name {
+		case "t":
+			token = value
+		case "k":
+			publicKey = value
+		}
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(publicKey)
+	if err != nil {
+		t.Fatalf("decode vapid public key: %v", err)
+	}
+	pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), raw)
+	if err != nil {
+		t.Fatalf("parse vapid public key: %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("vapid jwt has %d parts, want 3", len(parts))
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(sig) != 64 {
+		t.Fatalf("vapid signature is not a 64-byte r||s value: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !ecdsa.Verify(pub, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+		t.Fatal("vapid signature does not verify with k")
+	}
+	if header := decodeJWTPart(t, parts[0]); header["alg"] != "ES256" {
+		t.Errorf("vapid header = %v", header)
+	}
+	return publicKey, decodeJWTPart(t, parts[1])
+}
+
+func newVAPIDKey(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := key.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func TestWebPushClientSend(t *testing.T) {
+	browser := newSubscriber(t)
+	var received []byte
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Encoding"); got != "aes128gcm" {
+			t.Errorf("Content-Encoding = %q", got)
+		}
+		if got := r.Header.Get("Ttl"); got != "60" {
+			t.Errorf("TTL = %q, want 60", got)
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		received = browser.decrypt(t, body)
+
+		publicKey, claims := verifyVAPID(t, r.Header.Get("Authorization"))
+		if publicKey == "" {
+			t.Error("vapid k is empty")
+		}
+		if claims["aud"] != "https://"+r.Host {
+			t.Errorf("vapid aud = %v, want the push service origin", claims["aud"])
+		}
+		if claims["sub"] != "mailto:ops@example.com" {
+			t.Errorf("vapid sub = %v", claims["sub"])
+		}
+		if exp, _ := claims["exp"].(float64); time.Unix(int64(exp), 0).After(time.Now().Add(24 * time.Hour)) {
+			t.Errorf("vapid exp = %v, more than 24 hours ahead", claims["exp"])
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client, err := NewWebPushClient(WebPushConfig{
+		VAPIDPrivateKey: newVAPIDKey(t),
+		Subject:         "mailto:ops@example.com",
+		HTTPClient:      server.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &Message{Title: "Hello", Body: "World", Data: map[string]string{"order": "42"}, TTL: time.Minute}
+	if err := client.Send(context.Background(), browser.subscription(server.URL+"/push/abc"), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	var payload webPushPayload
+	if err := json.Unmarshal(received, &payload); err != nil {
+		t.Fatalf("decrypted payload is not JSON: %v (%q)", err, received)
+	}
+	if payload.Title != "Hello" || payload.Body != "World" || payload.Data["order"] != "42" {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestWebPushClientExpired(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	client, err := NewWebPushClient(WebPushConfig{
+		VAPIDPrivateKey: newVAPIDKey(t),
+		Subject:         "mailto:ops@example.com",
+		HTTPClient:      server.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.Send(context.Background(), newSubscriber(t).subscription(server.URL+"/push/abc"), &Message{Title: "Hello"})
+	if !errors.Is(err, ErrSubscriptionExpired) {
+		t.Errorf("Send = %v, want ErrSubscriptionExpired", err)
+	}
+}
+
+func TestWebPushClientRateLimited(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, err := NewWebPushClient(WebPushConfig{
+		VAPIDPrivateKey: newVAPIDKey(t),
+		Subject:         "mailto:ops@example.com",
+		HTTPClient:      server.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.Send(context.Background(), newSubscriber(t).subscription(server.URL+"/push/abc"), &Message{Title: "Hello"})
+	var limited *throttle.RateLimitError
+	if !errors.As(err, &limited) {
+		t.Fatalf("Send = %v, want a throttle.RateLimitError", err)
+	}
+	if limited.RetryAfter != 7*time.Second {
+		t.Errorf("RetryAfter = %v, want 7s", limited.RetryAfter)
+	}
+	if errors.Is(err, ErrSubscriptionExpired) {
+		t.Error("a rate limit must not expire the subscription")
+	}
+}
+
+func TestWebPushClientRefusesInternalEndpoints(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("request reached a loopback push service")
+	}))
+	defer server.Close()
+
+	// The default client is used, as in production
+	client, err := NewWebPushClient(WebPushConfig{VAPIDPrivateKey: newVAPIDKey(t), Subject: "mailto:ops@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.Send(context.Background(), newSubscriber(t).subscription(server.URL+"/push/abc"), &Message{Title: "Hello"})
+	if !errors.Is(err, egress.ErrForbiddenAddress) {
+		t.Errorf("Send = %v, want egress.ErrForbiddenAddress", err)
+	}
+}
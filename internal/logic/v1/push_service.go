@@ -0,0 +1,201 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/internal/logic/v1/push"
+	"github.com/duynhne/notification-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PushService delivers push notifications to every active device and browser of a user.
+type PushService struct {
+	repo          domain.NotificationRepository
+	deliveries    domain.NotificationDeliveryRepository
+	callbacks     *CallbackService
+	devices       domain.DeviceTokenRepository
+	dispatcher    *push.Dispatcher
+	subscriptions domain.WebPushSubscriptionRepository
+	webPush       *push.WebPushClient
+}
+
+// NewPushService creates a PushService. Pushes are queued in deliveries and sent by the
+// worker through a DeliveryService. Invalid tokens are deactivated by the dispatcher;
+// expired browser subscriptions are removed. webPush may be nil when Web Push is disabled.
+func NewPushService(
+	repo domain.NotificationRepository,
+	deliveries domain.NotificationDeliveryRepository,
+	callbacks *CallbackService,
+	devices domain.DeviceTokenRepository,
+	dispatcher *push.Dispatcher,
+	subscriptions domain.WebPushSubscriptionRepository,
+	webPush *push.WebPushClient,
+) *PushService {
+	return &PushService{
+		repo:          repo,
+		deliveries:    deliveries,
+		callbacks:     callbacks,
+		devices:       devices,
+		dispatcher:    dispatcher,
+		subscriptions: subscriptions,
+		webPush:       webPush,
+	}
+}
+
+// pushDelivery is the payload of a queued push: the message fields not stored on the
+// notification.
+type pushDelivery struct {
+	Badge      *int              `json:"badge,omitempty"`
+	Data       map[string]string `json:"data,omitempty"`
+	CollapseID string            `json:"collapse_id,omitempty"`
+	TTLSeconds int               `json:"ttl_seconds,omitempty"`
+}
+
+// SendPush records an in-app notification and queues the push to the user's devices.
+func (s *PushService) SendPush(ctx context.Context, req domain.SendPushRequest) (*domain.Notification, error) {
+	ctx, span := middleware.StartSpan(ctx, "notification.push", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.Int("user_id", req.UserID),
+	))
+	defer span.End()
+
+	notificationType := req.Type
+	if notificationType == "" {
+		notificationType = "push"
+	}
+	if err := validateContext(notificationType, req.NotificationContext); err != nil {
+		return nil, err
+	}
+
+	notification := &domain.Notification{
+		Type:      notificationType,
+		Title:     req.Title,
+		Message:   req.Body,
+		Status:    domain.StatusQueued,
+		Priority:  req.Priority,
+		ExpiresAt: req.ExpiresAt,
+		ClientID:  req.ClientID,
+
+		NotificationContext: req.NotificationContext,
+	}
+	if err := s.repo.Create(ctx, notification, req.UserID); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("create notification: %w", err)
+	}
+
+	queued := pushDelivery{Badge: req.Badge, Data: req.Data, CollapseID: req.CollapseID, TTLSeconds: req.TTLSeconds}
+	if err := queueDelivery(ctx, s.callbacks, s.deliveries, notification, channelPush, queued); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("push to user %d: %w", req.UserID, err)
+	}
+
+	span.AddEvent("notification.push.queued")
+	return notification, nil
+}
+
+// sendQueued fans a queued push out to the user's devices and browsers. It fails only
+// when none of them received it.
+func (s *PushService) sendQueued(ctx context.Context, notification *domain.Notification, payload []byte) error {
+	var queued pushDelivery
+	if err := json.Unmarshal(payload, &queued); err != nil {
+		return fmt.Errorf("decode queued push: %w", err)
+	}
+
+	devices, err := s.devices.ListActiveByUserID(ctx, notification.UserID)
+	if err != nil {
+		return fmt.Errorf("list devices: %w", err)
+	}
+
+	targets := make([]push.Target, 0, len(devices))
+	for _, d := range devices {
+		targets = append(targets, push.Target{Platform: push.Platform(d.Platform), Token: d.Token})
+	}
+
+	msg := &push.Message{
+		Title:      notification.Title,
+		Body:       notification.Message,
+		Badge:      queued.Badge,
+		Data:       queued.Data,
+		CollapseID: queued.CollapseID,
+		TTL:        time.Duration(queued.TTLSeconds) * time.Second,
+	}
+	if notification.ExpiresAt != nil {
+		// Providers must not hold the push for offline devices past its expiry either
+		if ttl := time.Until(*notification.ExpiresAt); msg.TTL == 0 || ttl < msg.TTL {
+			msg.TTL = max(ttl, time.Second)
+		}
+	}
+	res, err := s.dispatcher.Dispatch(ctx, targets, msg)
+
+	webRes, webErr := s.sendWebPush(ctx, notification.UserID, msg)
+	res.Sent += webRes.Sent
+	res.Failed += webRes.Failed
+	res.Deactivated += webRes.Deactivated
+	if err == nil {
+		err = webErr
+	}
+	if err == nil && res.Sent == 0 {
+		// No devices, or only tokens that turned out to be invalid: nothing was sent
+		err = ErrNoDevices
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.Int("push.devices", len(targets)),
+		attribute.Int("push.sent", res.Sent),
+		attribute.Int("push.failed", res.Failed),
+		attribute.Int("push.deactivated", res.Deactivated),
+	)
+	if err != nil {
+		span.RecordError(err)
+		if res.Sent == 0 {
+			return fmt.Errorf("push to user %d: %w", notification.UserID, err)
+		}
+	}
+	return nil
+}
+
+// sendWebPush delivers msg to the user's browser subscriptions, removing those the
+// push service reports as expired (404/410).
+func (s *PushService) sendWebPush(ctx context.Context, userID int, msg *push.Message) (push.Result, error) {
+	var result push.Result
+	if s.webPush == nil {
+		return result, nil
+	}
+
+	subs, err := s.subscriptions.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return result, fmt.Errorf("list web push subscriptions: %w", err)
+	}
+
+	var firstErr error
+	for _, sub := range subs {
+		err := s.webPush.Send(ctx, push.Subscription{Endpoint: sub.Endpoint, P256dh: sub.P256dh, Auth: sub.Auth}, msg)
+		switch {
+		case err == nil:
+			result.Sent++
+		case errors.Is(err, push.ErrSubscriptionExpired):
+			if derr := s.subscriptions.DeleteByEndpoint(ctx, sub.Endpoint); derr != nil {
+				result.Failed++
+				if firstErr == nil {
+					firstErr = derr
+				}
+				continue
+			}
+			result.Deactivated++
+		default:
+			result.Failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return result, firstErr
+}
@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/internal/logic/v1/push"
+)
+
+// fakeDevices lists a fixed set of devices and records deactivated tokens. Only the
+// methods used by sendQueued and the dispatcher are implemented.
+type fakeDevices struct {
+	domain.DeviceTokenRepository
+
+	devices     []domain.DeviceToken
+	deactivated []string
+}
+
+func (r *fakeDevices) ListActiveByUserID(context.Context, int) ([]domain.DeviceToken, error) {
+	return r.devices, nil
+}
+
+func (r *fakeDevices) DeactivateToken(_ context.Context, token string) error {
+	r.deactivated = append(r.deactivated, token)
+	return nil
+}
+
+// fakePushSender answers each token with the error mapped to it.
+type fakePushSender map[string]error
+
+func (s fakePushSender) Send(_ context.Context, token string, _ *push.Message) error {
+	return s[token]
+}
+
+var errPushDown = errors.New("provider unavailable")
+
+func TestPushSendQueued(t *testing.T) {
+	android := func(token string) domain.DeviceToken {
+		return domain.DeviceToken{Platform: string(push.PlatformAndroid), Token: token, Active: true}
+	}
+	tests := []struct {
+		name    string
+		devices []domain.DeviceToken
+		wantErr error
+	}{
+		{"no devices", nil, ErrNoDevices},
+		{"sent", []domain.DeviceToken{android("ok")}, nil},
+		{"sent to one of two", []domain.DeviceToken{android("ok"), android("down")}, nil},
+		{"only invalid tokens", []domain.DeviceToken{android("invalid")}, ErrNoDevices},
+		{"rejected", []domain.DeviceToken{android("down")}, errPushDown},
+		{"unsupported platform", []domain.DeviceToken{{Platform: "symbian", Token: "ok"}}, push.ErrUnsupportedPlatform},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devices := &fakeDevices{devices: tt.devices}
+			dispatcher := push.NewDispatcher(devices)
+			dispatcher.Register(push.PlatformAndroid, fakePushSender{
+				"invalid": push.ErrInvalidToken,
+				"down":    errPushDown,
+			})
+			service := NewPushService(nil, nil, nil, devices, dispatcher, nil, nil)
+
+			err := service.sendQueued(context.Background(), &domain.Notification{Title: "Hi"}, []byte("{}"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("sendQueued error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// trashRule names the purge of notifications left in the trash past the restore window.
+	trashRule = "trash"
+	// webhookDeliveriesRule names the purge of finished webhook deliveries.
+	webhookDeliveriesRule = "webhook_deliveries"
+	// callbackEventsRule names the purge of finished status callback events.
+	callbackEventsRule = "callback_events"
+)
+
+// RetentionService permanently deletes notifications past their retention: those
+// selected by the retention rules and those left in the trash past the restore window.
+// It also purges the finished rows of the webhook and status callback outboxes, which
+// would otherwise pile up.
+type RetentionService struct {
+	repo                 domain.NotificationRepository
+	rules                []domain.RetentionRule
+	trashFor             time.Duration
+	webhookDeliveries    domain.WebhookDeliveryRepository
+	webhookDeliveriesFor time.Duration
+	callbackEvents       domain.CallbackEventRepository
+	callbackEventsFor    time.Duration
+	batchSize            int
+}
+
+// PurgeResult is the number of rows one rule purged, or would purge.
+type PurgeResult struct {
+	// type, type:read, * (every other type), *:read, trash, webhook_deliveries or
+	// callback_events
+	Rule  string
+	Count int
+}
+
+// finishedOutbox is an outbox whose delivered and dead rows are purged by age.
+type finishedOutbox interface {
+	DeleteFinished(ctx context.Context, olderThan time.Duration, limit int) (int, error)
+	CountFinished(ctx context.Context, olderThan time.Duration) (int, error)
+}
+
+// NewRetentionService creates a RetentionService. Deleted notifications are purged once
+// they have been in the trash for trashFor. Delivered or dead webhook deliveries are
+// purged once older than webhookDeliveriesFor, and callback events once older than
+// callbackEventsFor or when their notification is purged (0 keeps them by age). Each
+// delete removes at most batchSize rows so a purge never holds many row locks at once.
+func NewRetentionService(
+	repo domain.NotificationRepository,
+	rules []domain.RetentionRule,
+	trashFor time.Duration,
+	webhookDeliveries domain.WebhookDeliveryRepository,
+	webhookDeliveriesFor time.Duration,
+	callbackEvents domain.CallbackEventRepository,
+	callbackEventsFor time.Duration,
+	batchSize int,
+) *RetentionService {
+	return &RetentionService{
+		repo:                 repo,
+		rules:                rules,
+		trashFor:             trashFor,
+		webhookDeliveries:    webhookDeliveries,
+		webhookDeliveriesFor: webhookDeliveriesFor,
+		callbackEvents:       callbackEvents,
+		callbackEventsFor:    callbackEventsFor,
+		batchSize:            batchSize,
+	}
+}
+
+// Purge deletes the notifications and outbox rows past their retention, batch by batch,
+// and returns the count of each rule. With dryRun it only counts them.
+func (s *RetentionService) Purge(ctx context.Context, dryRun bool) ([]PurgeResult, error) {
+	ctx, span := middleware.StartSpan(ctx, "notification.purge", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.Bool("purge.dry_run", dryRun),
+	))
+	defer span.End()
+
+	// Rules without a type cover the types that have no rule of their own
+	var typed []string
+	for _, rule := range s.rules {
+		if rule.Type != "" {
+			typed = append(typed, rule.Type)
+		}
+	}
+
+	results := make([]PurgeResult, 0, len(s.rules)+3)
+	total := 0
+	for _, rule := range s.rules {
+		var exclude []string
+		if rule.Type == "" {
+			exclude = typed
+		}
+		name := ruleName(rule)
+		var count int
+		var err error
+		if dryRun {
+			count, err = s.repo.CountOlderThan(ctx, rule, exclude)
+		} else {
+			count, err = s.drain(ctx, notificationsPurged.WithLabelValues(name), func(limit int) (int, error) {
+				return s.repo.DeleteOlderThan(ctx, rule, exclude, limit)
+			})
+		}
+		total += count
+		results = append(results, PurgeResult{Rule: name, Count: count})
+		if err != nil {
+			span.RecordError(err)
+			return results, err
+		}
+	}
+
+	var count int
+	var err error
+	if dryRun {
+		count, err = s.repo.CountTrashed(ctx, s.trashFor)
+	} else {
+		count, err = s.drain(ctx, notificationsPurged.WithLabelValues(trashRule), func(limit int) (int, error) {
+			return s.repo.DeleteTrashed(ctx, s.trashFor, limit)
+		})
+	}
+	total += count
+	results = append(results, PurgeResult{Rule: trashRule, Count: count})
+	if err != nil {
+		span.RecordError(err)
+		return results, err
+	}
+
+	span.SetAttributes(attribute.Int("notifications.purged", total))
+
+	outboxes := []struct {
+		rule    string
+		outbox  finishedOutbox
+		keepFor time.Duration
+		purged  prometheus.Counter
+	}{
+		{webhookDeliveriesRule, s.webhookDeliveries, s.webhookDeliveriesFor, webhookDeliveriesPurged},
+		{callbackEventsRule, s.callbackEvents, s.callbackEventsFor, callbackEventsPurged},
+	}
+	for _, o := range outboxes {
+		if o.keepFor <= 0 {
+			continue
+		}
+		if dryRun {
+			count, err = o.outbox.CountFinished(ctx, o.keepFor)
+		} else {
+			count, err = s.drain(ctx, o.purged, func(limit int) (int, error) {
+				return o.outbox.DeleteFinished(ctx, o.keepFor, limit)
+			})
+		}
+		results = append(results, PurgeResult{Rule: o.rule, Count: count})
+		if err != nil {
+			span.RecordError(err)
+			return results, err
+		}
+		span.SetAttributes(attribute.Int(o.rule+".purged", count))
+	}
+	return results, nil
+}
+
+// drain deletes batches until one comes back short and returns the number deleted,
+// which it adds to purged.
+func (s *RetentionService) drain(ctx context.Context, purged prometheus.Counter, deleteBatch func(limit int) (int, error)) (int, error) {
+	total := 0
+	for {
+		deleted, err := deleteBatch(s.batchSize)
+		total += deleted
+		purged.Add(float64(deleted))
+		if err != nil || deleted < s.batchSize {
+			return total, err
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+}
+
+// ruleName returns the name of a rule in the NOTIFICATION_RETENTION syntax.
+func ruleName(rule domain.RetentionRule) string {
+	name := rule.Type
+	if name == "" {
+		name = "*"
+	}
+	if rule.ReadOnly {
+		name += ":read"
+	}
+	return name
+}
@@ -0,0 +1,163 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+)
+
+// emptyTrash is a notification repository with nothing to purge. Only the methods used
+// by RetentionService without rules are implemented.
+type emptyTrash struct {
+	domain.NotificationRepository
+}
+
+func (emptyTrash) DeleteTrashed(context.Context, time.Duration, int) (int, error) { return 0, nil }
+func (emptyTrash) CountTrashed(context.Context, time.Duration) (int, error)       { return 0, nil }
+
+// finishedRows holds the finished rows of an outbox by age.
+type finishedRows struct {
+	ages    []time.Duration
+	batches []int // Rows deleted by each DeleteFinished call
+}
+
+func (r *finishedRows) DeleteFinished(_ context.Context, olderThan time.Duration, limit int) (int, error) {
+	var kept []time.Duration
+	deleted := 0
+	for _, age := range r.ages {
+		if age > olderThan && deleted < limit {
+			deleted++
+			continue
+		}
+		kept = append(kept, age)
+	}
+	r.ages = kept
+	r.batches = append(r.batches, deleted)
+	return deleted, nil
+}
+
+func (r *finishedRows) CountFinished(_ context.Context, olderThan time.Duration) (int, error) {
+	count := 0
+	for _, age := range r.ages {
+		if age > olderThan {
+			count++
+		}
+	}
+	return count, nil
+}
+
+type fakeWebhookOutbox struct {
+	domain.WebhookDeliveryRepository
+	*finishedRows
+}
+
+func (r fakeWebhookOutbox) DeleteFinished(ctx context.Context, olderThan time.Duration, limit int) (int, error) {
+	return r.finishedRows.DeleteFinished(ctx, olderThan, limit)
+}
+
+func (r fakeWebhookOutbox) CountFinished(ctx context.Context, olderThan time.Duration) (int, error) {
+	return r.finishedRows.CountFinished(ctx, olderThan)
+}
+
+type fakeCallbackOutbox struct {
+	domain.CallbackEventRepository
+	*finishedRows
+}
+
+func (r fakeCallbackOutbox) DeleteFinished(ctx context.Context, olderThan time.Duration, limit int) (int, error) {
+	return r.finishedRows.DeleteFinished(ctx, olderThan, limit)
+}
+
+func (r fakeCallbackOutbox) CountFinished(ctx context.Context, olderThan time.Duration) (int, error) {
+	return r.finishedRows.CountFinished(ctx, olderThan)
+}
+
+func TestRetentionPurgeOutboxes(t *testing.T) {
+	const day = 24 * time.Hour
+	ages := func(old, recent int) []time.Duration {
+		var ages []time.Duration
+		for range old {
+			ages = append(ages, 40*day)
+		}
+		for range recent {
+			ages = append(ages, day)
+		}
+		return ages
+	}
+	tests := []struct {
+		name          string
+		webhooks      []time.Duration
+		callbacks     []time.Duration
+		keepFor       time.Duration
+		dryRun        bool
+		want          []PurgeResult
+		wantBatches   []int // Of the webhook outbox
+		wantWebhooks  int   // Rows left
+		wantCallbacks int
+	}{
+		{
+			name:          "batched",
+			webhooks:      ages(5, 2),
+			callbacks:     ages(1, 1),
+			keepFor:       30 * day,
+			want:          []PurgeResult{{trashRule, 0}, {webhookDeliveriesRule, 5}, {callbackEventsRule, 1}},
+			wantBatches:   []int{2, 2, 1},
+			wantWebhooks:  2,
+			wantCallbacks: 1,
+		},
+		{
+			name:         "last batch full",
+			webhooks:     ages(4, 0),
+			keepFor:      30 * day,
+			want:         []PurgeResult{{trashRule, 0}, {webhookDeliveriesRule, 4}, {callbackEventsRule, 0}},
+			wantBatches:  []int{2, 2, 0},
+			wantWebhooks: 0,
+		},
+		{
+			name:          "dry run",
+			webhooks:      ages(5, 2),
+			callbacks:     ages(3, 0),
+			keepFor:       30 * day,
+			dryRun:        true,
+			want:          []PurgeResult{{trashRule, 0}, {webhookDeliveriesRule, 5}, {callbackEventsRule, 3}},
+			wantWebhooks:  7,
+			wantCallbacks: 3,
+		},
+		{
+			name:          "kept",
+			webhooks:      ages(5, 2),
+			callbacks:     ages(3, 0),
+			keepFor:       0,
+			want:          []PurgeResult{{trashRule, 0}},
+			wantWebhooks:  7,
+			wantCallbacks: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhooks := &finishedRows{ages: tt.webhooks}
+			callbacks := &finishedRows{ages: tt.callbacks}
+			service := NewRetentionService(emptyTrash{}, nil, 30*day,
+				fakeWebhookOutbox{finishedRows: webhooks}, tt.keepFor,
+				fakeCallbackOutbox{finishedRows: callbacks}, tt.keepFor, 2)
+
+			results, err := service.Purge(context.Background(), tt.dryRun)
+			if err != nil {
+				t.Fatalf("Purge: %v", err)
+			}
+			if !reflect.DeepEqual(results, tt.want) {
+				t.Errorf("results = %v, want %v", results, tt.want)
+			}
+			if !reflect.DeepEqual(webhooks.batches, tt.wantBatches) {
+				t.Errorf("webhook batches = %v, want %v", webhooks.batches, tt.wantBatches)
+			}
+			if len(webhooks.ages) != tt.wantWebhooks || len(callbacks.ages) != tt.wantCallbacks {
+				t.Errorf("left %d webhook deliveries and %d callback events, want %d and %d",
+					len(webhooks.ages), len(callbacks.ages), tt.wantWebhooks, tt.wantCallbacks)
+			}
+		})
+	}
+}
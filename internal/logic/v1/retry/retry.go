@@ -0,0 +1,105 @@
+// Package retry provides the retry policy shared by outbound delivery channels.
+//
+// Errors are retried by default. Wrap an error with Permanent to stop retrying
+// (e.g. a 4xx response that will never succeed):
+//
+//	err := policy.Do(ctx, func(ctx context.Context) error {
+//	    resp, err := send(ctx)
+//	    if resp.StatusCode == http.StatusBadRequest {
+//	        return retry.Permanent(fmt.Errorf("rejected: %d", resp.StatusCode))
+//	    }
+//	    return err
+//	})
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy describes how many times and how quickly a failed operation is retried.
+type Policy struct {
+	MaxAttempts    int           // Total attempts including the first (1 = no retries)
+	InitialBackoff time.Duration // Delay before the second attempt
+	MaxBackoff     time.Duration // Upper bound for a single delay
+	Multiplier     float64       // Backoff growth factor per attempt
+}
+
+// DefaultPolicy returns the policy used when none is configured:
+// 3 attempts with 500ms, 1s backoff (jittered), capped at 10s.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+	}
+}
+
+// permanentError marks an error that must not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that Do returns it immediately without further attempts.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err (or any error it wraps) was marked Permanent.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// Backoff returns the jittered delay before attempt n (n >= 1 is the first retry).
+// Half of the delay is randomized so retries from many callers don't hit a
+// recovering endpoint at the same instant.
+func (p Policy) Backoff(n int) time.Duration {
+	if n < 1 || p.InitialBackoff <= 0 {
+		return 0
+	}
+	d := float64(p.InitialBackoff)
+	for i := 1; i < n; i++ {
+		d *= p.Multiplier
+		if p.MaxBackoff > 0 && d >= float64(p.MaxBackoff) {
+			d = float64(p.MaxBackoff)
+			break
+		}
+	}
+	//nolint:gosec // jitter does not need a cryptographic source
+	return time.Duration(d/2 + rand.Float64()*d/2)
+}
+
+// Do calls fn until it succeeds, returns a Permanent error, the attempts are
+// exhausted, or ctx is done. The last error is returned.
+func (p Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	attempts := max(p.MaxAttempts, 1)
+
+	var err error
+	for attempt := range attempts {
+		if attempt > 0 {
+			timer := time.NewTimer(p.Backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return errors.Join(err, ctx.Err())
+			case <-timer.C:
+			}
+		}
+
+		err = fn(ctx)
+		if err == nil || IsPermanent(err) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	policy := Policy{MaxAttempts: 10, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	tests := []struct {
+		name   string
+		policy Policy
+		n      int
+		want   time.Duration // Delay before jitter; the result lies in [want/2, want)
+	}{
+		{"first attempt", policy, 0, 0},
+		{"first retry", policy, 1, 100 * time.Millisecond},
+		{"second retry", policy, 2, 200 * time.Millisecond},
+		{"fourth retry", policy, 4, 800 * time.Millisecond},
+		{"capped", policy, 5, time.Second},
+		{"long after the cap", policy, 1000, time.Second},
+		{"uncapped", Policy{InitialBackoff: time.Second, Multiplier: 3}, 4, 27 * time.Second},
+		{"constant", Policy{InitialBackoff: time.Second, Multiplier: 1}, 5, time.Second},
+		{"no backoff", Policy{Multiplier: 2}, 3, 0},
+		{"default", DefaultPolicy(), 2, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lowest, highest := time.Duration(1<<63-1), time.Duration(0)
+			for range 1000 {
+				got := tt.policy.Backoff(tt.n)
+				lowest, highest = min(lowest, got), max(highest, got)
+			}
+			if tt.want == 0 {
+				if highest != 0 {
+					t.Fatalf("Backoff(%d) up to %s, want 0", tt.n, highest)
+				}
+				return
+			}
+			if lowest < tt.want/2 || highest >= tt.want {
+				t.Fatalf("Backoff(%d) in [%s, %s], want within [%s, %s)", tt.n, lowest, highest, tt.want/2, tt.want)
+			}
+			// Jitter spreads the delays over the whole upper half
+			if spread := highest - lowest; spread < tt.want/4 {
+				t.Errorf("Backoff(%d) spread over %s of 1000 samples, want at least %s", tt.n, spread, tt.want/4)
+			}
+		})
+	}
+}
+
+func TestDo(t *testing.T) {
+	errTransient := errors.New("transient")
+	errRejected := errors.New("rejected")
+	policy := Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 2}
+
+	tests := []struct {
+		name         string
+		policy       Policy
+		results      []error // Results of successive attempts; the last one repeats
+		wantAttempts int
+		wantErr      error
+	}{
+		{"success", policy, []error{nil}, 1, nil},
+		{"success after retries", policy, []error{errTransient, errTransient, nil}, 3, nil},
+		{"attempts exhausted", policy, []error{errTransient}, 3, errTransient},
+		{"permanent", policy, []error{errTransient, Permanent(errRejected)}, 2, errRejected},
+		{"no retries", Policy{MaxAttempts: 1}, []error{errTransient}, 1, errTransient},
+		{"zero attempts still tries once", Policy{}, []error{errTransient}, 1, errTransient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := tt.policy.Do(context.Background(), func(context.Context) error {
+				attempts++
+				return tt.results[min(attempts, len(tt.results))-1]
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Do error = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestDoCancelledDuringBackoff(t *testing.T) {
+	errTransient := errors.New("transient")
+	policy := Policy{MaxAttempts: 3, InitialBackoff: time.Hour, Multiplier: 2}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	attempts := 0
+	err := policy.Do(ctx, func(context.Context) error {
+		attempts++
+		cancel()
+		return errTransient
+	})
+	if !errors.Is(err, errTransient) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Do error = %v, want the last error joined with context.Canceled", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	err := errors.New("rejected")
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) != nil")
+	}
+	if wrapped := Permanent(err); !IsPermanent(wrapped) || !errors.Is(wrapped, err) || wrapped.Error() != err.Error() {
+		t.Errorf("Permanent(err) = %v, want a permanent error wrapping err", wrapped)
+	}
+	if IsPermanent(err) {
+		t.Error("IsPermanent of a plain error")
+	}
+}
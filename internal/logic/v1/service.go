@@ -2,23 +2,77 @@ package v1
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/textproto"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/internal/logic/v1/email"
+	"github.com/duynhne/notification-service/internal/logic/v1/sms"
 	"github.com/duynhne/notification-service/middleware"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// SMSPolicy controls how SMS recipients and message lengths are handled.
+type SMSPolicy struct {
+	DefaultRegion string // ISO 3166-1 alpha-2 region of numbers without a country code
+	MaxSegments   int    // Segments a message may use (0 = unlimited)
+	Truncate      bool   // Truncate longer messages instead of rejecting them
+}
+
+// InboxPolicy controls the archive and trash of users' notification lists.
+type InboxPolicy struct {
+	RestoreWindow time.Duration // How long deleted notifications can be restored
+	ListWindow    time.Duration // How far back lists and unread counts reach (0 = all)
+}
+
 type NotificationService struct {
-	repo domain.NotificationRepository
+	repo         domain.NotificationRepository
+	deliveries   domain.NotificationDeliveryRepository
+	ids          *NotificationIDs
+	callbacks    *CallbackService
+	suppressions *SuppressionService
+	unsubscribes *UnsubscribeService
+	attachments  *AttachmentService
+	mailer       email.Sender
+	smsRouter    *sms.Router
+	smsPolicy    SMSPolicy
+	inboxPolicy  InboxPolicy
 }
 
-func NewNotificationService(repo domain.NotificationRepository) *NotificationService {
+// NewNotificationService creates a NotificationService. Email and SMS sends are queued in
+// deliveries and sent by the worker through a DeliveryService. mailer and smsRouter may be
+// nil, in which case notifications of that channel are recorded but not sent.
+func NewNotificationService(
+	repo domain.NotificationRepository,
+	deliveries domain.NotificationDeliveryRepository,
+	ids *NotificationIDs,
+	callbacks *CallbackService,
+	suppressions *SuppressionService,
+	unsubscribes *UnsubscribeService,
+	attachments *AttachmentService,
+	mailer email.Sender,
+	smsRouter *sms.Router,
+	smsPolicy SMSPolicy,
+	inboxPolicy InboxPolicy,
+) *NotificationService {
 	return &NotificationService{
-		repo: repo,
+		repo:         repo,
+		deliveries:   deliveries,
+		ids:          ids,
+		callbacks:    callbacks,
+		suppressions: suppressions,
+		unsubscribes: unsubscribes,
+		attachments:  attachments,
+		mailer:       mailer,
+		smsRouter:    smsRouter,
+		smsPolicy:    smsPolicy,
+		inboxPolicy:  inboxPolicy,
 	}
 }
 
@@ -35,25 +89,86 @@ func (s *NotificationService) SendEmail(ctx context.Context, req domain.SendEmai
 		return nil, fmt.Errorf("send email to %q: %w", req.To, ErrInvalidRecipient)
 	}
 
+	// Mailing bounced or complaining addresses hurts sender reputation
+	if err := s.suppressions.Check(ctx, req.To); err != nil {
+		span.SetAttributes(attribute.Bool("email.sent", false), attribute.Bool("email.suppressed", true))
+		return nil, err
+	}
+	// Suppressed copies are dropped rather than failing the whole message
+	cc, err := s.suppressions.Filter(ctx, req.Cc)
+	if err != nil {
+		return nil, err
+	}
+	bcc, err := s.suppressions.Filter(ctx, req.Bcc)
+	if err != nil {
+		return nil, err
+	}
+	if dropped := len(req.Cc) + len(req.Bcc) - len(cc) - len(bcc); dropped > 0 {
+		span.SetAttributes(attribute.Int("email.suppressed_copies", dropped))
+	}
+	req.Cc, req.Bcc = cc, bcc
+
+	if err := s.callbacks.ValidateCallback(ctx, req.ClientID, req.CallbackURL); err != nil {
+		return nil, err
+	}
+	if err := validateContext("email", req.NotificationContext); err != nil {
+		return nil, err
+	}
+
+	// Fetch and check attachments before recording the notification
+	attachments, err := s.attachments.Resolve(ctx, req.Attachments)
+	if err != nil {
+		span.SetAttributes(attribute.Bool("email.sent", false))
+		return nil, err
+	}
+
 	// TODO: Extract user_id from email or JWT token
-	// For now, use mock user_id = 1
+	// For now, fall back to mock user_id = 1 when the caller does not pass one
 	userID := 1
+	if req.UserID > 0 {
+		userID = req.UserID
+	}
+
+	category := req.Category
+	if category == "" {
+		category = domain.CategoryTransactional
+	}
+	span.SetAttributes(attribute.String("email.category", category))
+
+	if err := s.unsubscribes.CheckOptIn(ctx, userID, category); err != nil {
+		span.SetAttributes(attribute.Bool("email.sent", false))
+		return nil, err
+	}
 
 	notification := &domain.Notification{
-		Type:    "email",
-		Message: req.Subject, // Using subject as message/title
-		Title:   req.Subject,
+		Type:        "email",
+		Message:     req.Subject, // Using subject as message/title
+		Title:       req.Subject,
+		Status:      domain.StatusQueued,
+		Priority:    req.Priority,
+		ExpiresAt:   req.ExpiresAt,
+		Recipient:   req.To,
+		ClientID:    req.ClientID,
+		CallbackURL: req.CallbackURL,
+
+		NotificationContext: req.NotificationContext,
 	}
 
 	// Insert using repository
-	err := s.repo.Create(ctx, notification, userID)
-	if err != nil {
+	if err := s.repo.Create(ctx, notification, userID); err != nil {
 		span.RecordError(err)
 		return nil, fmt.Errorf("create notification: %w", err)
 	}
 
-	span.SetAttributes(attribute.Bool("email.sent", true))
-	span.AddEvent("notification.email.sent")
+	// The attachments are queued as fetched, not as requested
+	req.Attachments = nil
+	queued := emailDelivery{Request: req, Attachments: attachments, UserID: userID, Category: category}
+	if err := queueDelivery(ctx, s.callbacks, s.deliveries, notification, channelEmail, queued); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.AddEvent("notification.email.queued")
 
 	return notification, nil
 }
@@ -65,30 +180,144 @@ func (s *NotificationService) SendSMS(ctx context.Context, req domain.SendSMSReq
 	))
 	defer span.End()
 
+	to, err := sms.NormalizeNumber(req.To, s.smsPolicy.DefaultRegion)
+	if err != nil {
+		span.SetAttributes(attribute.Bool("sms.sent", false))
+		return nil, fmt.Errorf("send sms: %w: %w", err, ErrInvalidRecipient)
+	}
+	span.SetAttributes(attribute.String("to", to))
+
+	text := req.Message
+	analysis := sms.Analyze(text)
+	if maxSegments := s.smsPolicy.MaxSegments; maxSegments > 0 && analysis.Segments > maxSegments {
+		if !s.smsPolicy.Truncate {
+			span.SetAttributes(attribute.Bool("sms.sent", false))
+			return nil, fmt.Errorf("sms needs %d %s segments, at most %d allowed: %w",
+				analysis.Segments, analysis.Encoding, maxSegments, ErrMessageTooLong)
+		}
+		text = sms.Truncate(text, maxSegments)
+		analysis = sms.Analyze(text)
+		span.SetAttributes(attribute.Bool("sms.truncated", true))
+	}
+	span.SetAttributes(
+		attribute.String("sms.encoding", string(analysis.Encoding)),
+		attribute.Int("sms.segments", analysis.Segments),
+	)
+
+	if err := s.callbacks.ValidateCallback(ctx, req.ClientID, req.CallbackURL); err != nil {
+		return nil, err
+	}
+	if err := validateContext("sms", req.NotificationContext); err != nil {
+		return nil, err
+	}
+
 	// TODO: Extract user_id from phone number or JWT token
 	userID := 1
 
 	notification := &domain.Notification{
-		Type:    "sms",
-		Message: req.Message,
-		Title:   "SMS",
+		Type:        "sms",
+		Message:     text,
+		Title:       "SMS",
+		Status:      domain.StatusQueued,
+		Priority:    req.Priority,
+		ExpiresAt:   req.ExpiresAt,
+		Recipient:   to,
+		ClientID:    req.ClientID,
+		CallbackURL: req.CallbackURL,
+		SMSEncoding: string(analysis.Encoding),
+		SMSSegments: analysis.Segments,
+
+		NotificationContext: req.NotificationContext,
 	}
 
 	// Insert using repository
-	err := s.repo.Create(ctx, notification, userID)
-	if err != nil {
+	if err := s.repo.Create(ctx, notification, userID); err != nil {
 		span.RecordError(err)
 		return nil, fmt.Errorf("create notification: %w", err)
 	}
 
-	span.SetAttributes(attribute.Bool("sms.sent", true))
-	span.AddEvent("notification.sms.sent")
+	// The recipient and text are stored on the notification
+	if err := queueDelivery(ctx, s.callbacks, s.deliveries, notification, channelSMS, nil); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.AddEvent("notification.sms.queued")
 
 	return notification, nil
 }
 
-// ListNotifications returns all notifications for a user
-func (s *NotificationService) ListNotifications(ctx context.Context, userID string) ([]domain.Notification, error) {
+// emailDelivery is the payload of a queued email: the request as accepted, with its
+// attachments already fetched and checked.
+type emailDelivery struct {
+	Request     domain.SendEmailRequest `json:"request"`
+	Attachments []email.Attachment      `json:"attachments,omitempty"`
+	UserID      int                     `json:"user_id"`
+	Category    string                  `json:"category"`
+}
+
+// sendQueuedEmail sends a queued email notification.
+func (s *NotificationService) sendQueuedEmail(ctx context.Context, notification *domain.Notification, payload []byte) error {
+	var queued emailDelivery
+	if err := json.Unmarshal(payload, &queued); err != nil {
+		return fmt.Errorf("decode queued email: %w", err)
+	}
+	return s.sendEmail(ctx, notification, queued.Request, queued.Attachments, queued.UserID, queued.Category)
+}
+
+// sendQueuedSMS sends a queued SMS notification to its recipient.
+func (s *NotificationService) sendQueuedSMS(ctx context.Context, notification *domain.Notification, _ []byte) error {
+	if s.smsRouter == nil {
+		return nil
+	}
+
+	delivery, err := s.smsRouter.Send(ctx, &sms.Message{To: notification.Recipient, Text: notification.Message})
+	if err != nil {
+		return err
+	}
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("sms.route", delivery.Route),
+		attribute.String("sms.provider", delivery.Provider),
+		attribute.Int("sms.attempts", delivery.Attempts),
+	)
+	return nil
+}
+
+// sendEmail renders the notification as an email and hands it to the mailer.
+func (s *NotificationService) sendEmail(
+	ctx context.Context,
+	notification *domain.Notification,
+	req domain.SendEmailRequest,
+	attachments []email.Attachment,
+	userID int,
+	category string,
+) error {
+	if s.mailer == nil {
+		return nil
+	}
+
+	msg := &email.Message{
+		ReplyTo:     req.ReplyTo,
+		To:          []string{req.To},
+		Cc:          req.Cc,
+		Bcc:         req.Bcc,
+		Subject:     req.Subject,
+		Text:        req.Body,
+		HTML:        req.HTML,
+		Attachments: attachments,
+		Header:      textproto.MIMEHeader{},
+	}
+	msg.Header.Set(email.HeaderNotificationID, notification.PublicID.String())
+	if err := s.unsubscribes.AddHeaders(msg.Header, userID, category); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, msg)
+}
+
+// ListNotifications returns the notifications in one folder of a user's list (the inbox
+// by default), without expired ones unless filter.IncludeExpired is set
+func (s *NotificationService) ListNotifications(ctx context.Context, userID string, filter domain.NotificationFilter) ([]domain.Notification, error) {
 	ctx, span := middleware.StartSpan(ctx, "notification.list", trace.WithAttributes(
 		attribute.String("layer", "logic"),
 		attribute.String("api.version", "v1"),
@@ -104,7 +333,9 @@ func (s *NotificationService) ListNotifications(ctx context.Context, userID stri
 		}
 	}
 
-	notifications, err := s.repo.ListByUserID(ctx, uid)
+	filter.RestoreWindow = s.inboxPolicy.RestoreWindow
+	filter.ListWindow = s.inboxPolicy.ListWindow
+	notifications, err := s.repo.ListByUserID(ctx, uid, filter)
 	if err != nil {
 		span.RecordError(err)
 		return nil, err
@@ -117,6 +348,40 @@ func (s *NotificationService) ListNotifications(ctx context.Context, userID stri
 	return notifications, nil
 }
 
+// ListNotificationGroups returns the same notifications as ListNotifications with each
+// group folded into one entry, ordered by the group's latest notification. Groups are
+// built by the database, so counts cover the whole list rather than one page of it.
+func (s *NotificationService) ListNotificationGroups(ctx context.Context, userID string, filter domain.NotificationFilter) ([]domain.NotificationGroup, error) {
+	ctx, span := middleware.StartSpan(ctx, "notification.list_groups", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("api.version", "v1"),
+		attribute.String("user_id", userID),
+	))
+	defer span.End()
+
+	// Use provided userID or default to "1"
+	uid := 1
+	if userID != "" {
+		if parsed, err := strconv.Atoi(userID); err == nil {
+			uid = parsed
+		}
+	}
+
+	filter.RestoreWindow = s.inboxPolicy.RestoreWindow
+	filter.ListWindow = s.inboxPolicy.ListWindow
+	groups, err := s.repo.ListGroupsByUserID(ctx, uid, filter)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("notifications.groups", len(groups)))
+	if groups == nil {
+		return []domain.NotificationGroup{}, nil
+	}
+	return groups, nil
+}
+
 // GetNotification retrieves a single notification by ID
 func (s *NotificationService) GetNotification(ctx context.Context, id string) (*domain.Notification, error) {
 	ctx, span := middleware.StartSpan(ctx, "notification.get", trace.WithAttributes(
@@ -126,13 +391,13 @@ func (s *NotificationService) GetNotification(ctx context.Context, id string) (*
 	))
 	defer span.End()
 
-	notificationID, err := strconv.Atoi(id)
+	key, err := s.ids.Resolve(ctx, id)
 	if err != nil {
 		span.SetAttributes(attribute.Bool("notification.found", false))
-		return nil, fmt.Errorf("invalid notification id %q: %w", id, ErrNotificationNotFound)
+		return nil, err
 	}
 
-	notification, err := s.repo.FindByID(ctx, notificationID)
+	notification, err := s.repo.FindByID(ctx, key)
 	if err != nil {
 		span.RecordError(err)
 		return nil, err
@@ -156,12 +421,12 @@ func (s *NotificationService) MarkAsRead(ctx context.Context, id string) (*domai
 	))
 	defer span.End()
 
-	notificationID, err := strconv.Atoi(id)
+	key, err := s.ids.Resolve(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid notification id %q: %w", id, ErrNotificationNotFound)
+		return nil, err
 	}
 
-	updated, err := s.repo.MarkAsRead(ctx, notificationID)
+	updated, err := s.repo.MarkAsRead(ctx, key)
 	if err != nil {
 		span.RecordError(err)
 		return nil, err
@@ -178,8 +443,181 @@ func (s *NotificationService) MarkAsRead(ctx context.Context, id string) (*domai
 	return s.GetNotification(ctx, id)
 }
 
-// CountUnread returns unread notification count for a user
-func (s *NotificationService) CountUnread(ctx context.Context, userID string) (int, error) {
+// MarkGroupAsRead marks all unread notifications of one of the user's groups as read
+// and returns how many it marked
+func (s *NotificationService) MarkGroupAsRead(ctx context.Context, userID, groupKey string) (int, error) {
+	ctx, span := middleware.StartSpan(ctx, "notification.mark_group_read", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("api.version", "v1"),
+		attribute.String("notification.group_key", groupKey),
+	))
+	defer span.End()
+
+	uid, err := parseUserID(userID)
+	if err != nil {
+		return 0, err
+	}
+	updated, err := s.repo.MarkGroupAsRead(ctx, uid, groupKey)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	span.SetAttributes(attribute.Int("notifications.updated", updated))
+	return updated, nil
+}
+
+// TakeAction records the action a user chose on one of their notifications, marks it
+// read and forwards the choice to the service that sent it. Choosing the action already
+// taken again returns the notification unchanged.
+func (s *NotificationService) TakeAction(ctx context.Context, userID, id, action string) (*domain.Notification, error) {
+	ctx, span := middleware.StartSpan(ctx, "notification.take_action", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("api.version", "v1"),
+		attribute.String("notification.id", id),
+		attribute.String("notification.action", action),
+	))
+	defer span.End()
+
+	uid, err := parseUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	notification, err := s.GetNotification(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if notification.UserID != uid {
+		// Other users' notifications are reported as missing, not forbidden
+		return nil, fmt.Errorf("get notification by id %q: %w", id, ErrNotificationNotFound)
+	}
+	if !slices.ContainsFunc(notification.Actions, func(a domain.NotificationAction) bool { return a.ID == action }) {
+		return nil, fmt.Errorf("notification %s has no action %q: %w", id, action, ErrActionNotFound)
+	}
+	if notification.ActionTaken == action {
+		return notification, nil
+	}
+	if notification.ActionTaken != "" {
+		return nil, fmt.Errorf("notification %s already has action %q: %w", id, notification.ActionTaken, ErrActionAlreadyTaken)
+	}
+	if notification.ExpiresAt != nil && !time.Now().Before(*notification.ExpiresAt) {
+		return nil, fmt.Errorf("notification %s: %w", id, ErrNotificationExpired)
+	}
+
+	recorded, err := s.repo.RecordAction(ctx, notification.Key(), action)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if !recorded {
+		// Another request recorded an action first
+		return nil, fmt.Errorf("notification %s: %w", id, ErrActionAlreadyTaken)
+	}
+	notification.ActionTaken = action
+	now := time.Now().UTC()
+	notification.ActionTakenAt = &now
+	notification.Read = true
+	if notification.ReadAt == nil {
+		notification.ReadAt = &now
+	}
+
+	if err := s.callbacks.ActionTaken(ctx, notification); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("forward action %q of notification %s: %w", action, id, err)
+	}
+	return notification, nil
+}
+
+// ArchiveNotification moves one of the user's notifications to the archive.
+func (s *NotificationService) ArchiveNotification(ctx context.Context, userID, id string) (*domain.Notification, error) {
+	if err := s.updateOne(ctx, "archive", userID, id); err != nil {
+		return nil, err
+	}
+	return s.GetNotification(ctx, id)
+}
+
+// DeleteNotification moves one of the user's notifications to the trash, from where it
+// can be restored within the restore window.
+func (s *NotificationService) DeleteNotification(ctx context.Context, userID, id string) error {
+	return s.updateOne(ctx, "delete", userID, id)
+}
+
+// RestoreNotification moves one of the user's notifications back to the inbox.
+func (s *NotificationService) RestoreNotification(ctx context.Context, userID, id string) (*domain.Notification, error) {
+	if err := s.updateOne(ctx, "restore", userID, id); err != nil {
+		return nil, err
+	}
+	return s.GetNotification(ctx, id)
+}
+
+// BulkUpdate applies an inbox operation to several of the user's notifications and
+// returns how many it applied to. Ids of other users' notifications are ignored.
+func (s *NotificationService) BulkUpdate(ctx context.Context, userID string, req domain.BulkNotificationRequest) (int, error) {
+	uid, err := parseUserID(userID)
+	if err != nil {
+		return 0, err
+	}
+	keys, err := s.ids.ResolveAll(ctx, req.IDs)
+	if err != nil {
+		return 0, err
+	}
+	return s.applyInbox(ctx, req.Operation, uid, keys)
+}
+
+// updateOne applies an inbox operation to a single notification of the user.
+func (s *NotificationService) updateOne(ctx context.Context, operation, userID, id string) error {
+	uid, err := parseUserID(userID)
+	if err != nil {
+		return err
+	}
+	key, err := s.ids.Resolve(ctx, id)
+	if err != nil {
+		return err
+	}
+	updated, err := s.applyInbox(ctx, operation, uid, []domain.NotificationKey{key})
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return fmt.Errorf("%s notification id %q: %w", operation, id, ErrNotificationNotFound)
+	}
+	return nil
+}
+
+// applyInbox runs an inbox operation (archive, delete or restore) on notifications of a user.
+func (s *NotificationService) applyInbox(ctx context.Context, operation string, userID int, keys []domain.NotificationKey) (int, error) {
+	ctx, span := middleware.StartSpan(ctx, "notification."+operation, trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("api.version", "v1"),
+		attribute.Int("user_id", userID),
+		attribute.Int("notifications.requested", len(keys)),
+	))
+	defer span.End()
+
+	var updated int
+	var err error
+	switch operation {
+	case "archive":
+		updated, err = s.repo.Archive(ctx, userID, keys)
+	case "delete":
+		updated, err = s.repo.SoftDelete(ctx, userID, keys)
+	case "restore":
+		updated, err = s.repo.Restore(ctx, userID, keys, s.inboxPolicy.RestoreWindow)
+	default:
+		err = fmt.Errorf("unknown inbox operation %q", operation)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	span.SetAttributes(attribute.Int("notifications.updated", updated))
+	return updated, nil
+}
+
+// CountUnread returns unread notification count for a user, without expired ones unless
+// includeExpired is set
+func (s *NotificationService) CountUnread(ctx context.Context, userID string, includeExpired bool) (int, error) {
 	ctx, span := middleware.StartSpan(ctx, "notification.count_unread", trace.WithAttributes(
 		attribute.String("layer", "logic"),
 		attribute.String("api.version", "v1"),
@@ -188,17 +626,15 @@ func (s *NotificationService) CountUnread(ctx context.Context, userID string) (i
 	defer span.End()
 
 	// Security: Validate userID - reject empty or invalid input
-	if userID == "" {
-		return 0, errors.New("user_id is required")
-	}
-	uid, err := strconv.Atoi(userID)
-	if err != nil || uid <= 0 {
-		span.RecordError(fmt.Errorf("invalid user_id: %s", userID))
-		return 0, fmt.Errorf("invalid user_id: %s", userID)
+	uid, err := parseUserID(userID)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
 	}
 
 	// Use repository for database access (proper 3-layer architecture)
-	count, err := s.repo.CountUnreadByUserID(ctx, uid)
+	filter := domain.NotificationFilter{IncludeExpired: includeExpired, ListWindow: s.inboxPolicy.ListWindow}
+	count, err := s.repo.CountUnreadByUserID(ctx, uid, filter)
 	if err != nil {
 		span.RecordError(err)
 		return 0, err
@@ -207,3 +643,15 @@ func (s *NotificationService) CountUnread(ctx context.Context, userID string) (i
 	span.SetAttributes(attribute.Int("notifications.unread_count", count))
 	return count, nil
 }
+
+// parseUserID validates a user_id set by the auth middleware.
+func parseUserID(userID string) (int, error) {
+	if userID == "" {
+		return 0, errors.New("user_id is required")
+	}
+	uid, err := strconv.Atoi(userID)
+	if err != nil || uid <= 0 {
+		return 0, fmt.Errorf("invalid user_id: %s", userID)
+	}
+	return uid, nil
+}
@@ -0,0 +1,187 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidNumber indicates a phone number cannot be normalized to E.164.
+var ErrInvalidNumber = errors.New("invalid phone number")
+
+// region describes national dialing rules used to interpret numbers without a
+// country code and to check number lengths.
+type region struct {
+	code      string // Country calling code
+	trunk     string // National trunk prefix dropped in international format ("" if none)
+	minDigits int    // National significant number length
+	maxDigits int
+}
+
+// regions covers the default regions the service is deployed with and the most
+// common destinations. Numbers for other countries are accepted in international
+// format with E.164 length checks only.
+var regions = map[string]region{
+	"AE": {"971", "0", 8, 9},
+	"AR": {"54", "0", 10, 10},
+	"AT": {"43", "0", 4, 13},
+	"AU": {"61", "0", 9, 9},
+	"BE": {"32", "0", 8, 9},
+	"BR": {"55", "0", 10, 11},
+	"CA": {"1", "1", 10, 10},
+	"CH": {"41", "0", 9, 9},
+	"CL": {"56", "", 9, 9},
+	"CN": {"86", "0", 10, 11},
+	"CO": {"57", "", 10, 10},
+	"DE": {"49", "0", 6, 13},
+	"DK": {"45", "", 8, 8},
+	"EG": {"20", "0", 9, 10},
+	"ES": {"34", "", 9, 9},
+	"FI": {"358", "0", 5, 12},
+	"FR": {"33", "0", 9, 9},
+	"GB": {"44", "0", 9, 10},
+	"HK": {"852", "", 8, 8},
+	"ID": {"62", "0", 9, 12},
+	"IE": {"353", "0", 7, 9},
+	"IL": {"972", "0", 8, 9},
+	"IN": {"91", "0", 10, 10},
+	"IT": {"39", "", 6, 11}, // Italian numbers keep their leading 0
+	"JP": {"81", "0", 9, 10},
+	"KE": {"254", "0", 9, 9},
+	"KR": {"82", "0", 8, 10},
+	"MX": {"52", "", 10, 10},
+	"MY": {"60", "0", 9, 10},
+	"NG": {"234", "0", 8, 10},
+	"NL": {"31", "0", 9, 9},
+	"NO": {"47", "", 8, 8},
+	"NZ": {"64", "0", 8, 10},
+	"PH": {"63", "0", 10, 10},
+	"PK": {"92", "0", 9, 10},
+	"PL": {"48", "", 9, 9},
+	"PT": {"351", "", 9, 9},
+	"RU": {"7", "8", 10, 10},
+	"SA": {"966", "0", 9, 9},
+	"SE": {"46", "0", 7, 10},
+	"SG": {"65", "", 8, 8},
+	"TH": {"66", "0", 8, 9},
+	"TR": {"90", "0", 10, 10},
+	"UA": {"380", "0", 9, 9},
+	"US": {"1", "1", 10, 10},
+	"VN": {"84", "0", 9, 10},
+	"ZA": {"27", "0", 9, 9},
+}
+
+// callingCodes lists assigned geographic country calling codes (ITU-T E.164).
+// Non-geographic codes (satellite, international freephone) cannot receive SMS.
+var callingCodes = func() map[string]bool {
+	codes := make(map[string]bool)
+	for _, list := range []string{
+		"1 7",
+		"20 27 30 31 32 33 34 36 39 40 41 43 44 45 46 47 48 49 51 52 53 54 55 56 57 58",
+		"60 61 62 63 64 65 66 81 82 84 86 90 91 92 93 94 95 98",
+		"211 212 213 216 218 220 221 222 223 224 225 226 227 228 229 230 231 232 233 234",
+		"235 236 237 238 239 240 241 242 243 244 245 246 247 248 249 250 251 252 253 254",
+		"255 256 257 258 260 261 262 263 264 265 266 267 268 269 290 291 297 298 299",
+		"350 351 352 353 354 355 356 357 358 359 370 371 372 373 374 375 376 377 378 379",
+		"380 381 382 383 385 386 387 389 420 421 423 500 501 502 503 504 505 506 507 508 509",
+		"590 591 592 593 594 595 596 597 598 599 670 672 673 674 675 676 677 678 679 680",
+		"681 682 683 685 686 687 688 689 690 691 692 850 852 853 855 856 880 886",
+		"960 961 962 963 964 965 966 967 968 970 971 972 973 974 975 976 977 992 993 994 995 996 998",
+	} {
+		for _, code := range strings.Fields(list) {
+			codes[code] = true
+		}
+	}
+	return codes
+}()
+
+// regionByCode maps a calling code to dialing rules for length checks. Codes shared by
+// several regions (1 for NANP, 7) use the same rules for all of them.
+var regionByCode = func() map[string]region {
+	byCode := make(map[string]region, len(regions))
+	for _, r := range regions {
+		byCode[r.code] = r
+	}
+	return byCode
+}()
+
+// KnownRegion reports whether numbers without a country code can be interpreted for
+// the ISO 3166-1 alpha-2 region.
+func KnownRegion(code string) bool {
+	_, ok := regions[strings.ToUpper(code)]
+	return ok
+}
+
+// NormalizeNumber parses a phone number and returns it in E.164 format ("+84912345678").
+// Numbers in international format ("+", "00", or "011" in North America) are taken as
+// is; others are national numbers of defaultRegion. Spaces, dashes, dots, slashes and
+// parentheses are ignored.
+func NormalizeNumber(raw, defaultRegion string) (string, error) {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case strings.ContainsRune(" -./()\u00a0", r):
+		default:
+			return "", fmt.Errorf("%q contains %q: %w", raw, r, ErrInvalidNumber)
+		}
+	}
+	number := b.String()
+
+	home, homeKnown := regions[strings.ToUpper(defaultRegion)]
+	var international string
+	switch {
+	case strings.HasPrefix(number, "+"):
+		international = number[1:]
+	case strings.HasPrefix(number, "00"):
+		international = number[2:]
+	case homeKnown && home.code == "1" && strings.HasPrefix(number, "011"):
+		international = number[3:]
+	case homeKnown:
+		national := number
+		if hasTrunk(national, home) {
+			national = national[len(home.trunk):]
+		}
+		international = home.code + national
+	default:
+		return "", fmt.Errorf("%q has no country code and default region %q is unknown: %w", raw, defaultRegion, ErrInvalidNumber)
+	}
+
+	code := ""
+	for n := 1; n <= 3 && n < len(international); n++ {
+		if callingCodes[international[:n]] {
+			code = international[:n]
+			break
+		}
+	}
+	if code == "" {
+		return "", fmt.Errorf("%q has an unknown country code: %w", raw, ErrInvalidNumber)
+	}
+	national := international[len(code):]
+
+	if r, ok := regionByCode[code]; ok {
+		// "+44 (0)20 ..." style: drop a trunk prefix written after the country code
+		if hasTrunk(national, r) {
+			national = national[len(r.trunk):]
+		}
+		if len(national) < r.minDigits || len(national) > r.maxDigits {
+			return "", fmt.Errorf("%q has %d national digits, expected %d-%d: %w",
+				raw, len(national), r.minDigits, r.maxDigits, ErrInvalidNumber)
+		}
+	}
+
+	// E.164 allows at most 15 digits; shorter than 8 is never a mobile number
+	if total := len(code) + len(national); total < 8 || total > 15 {
+		return "", fmt.Errorf("%q has %d digits: %w", raw, total, ErrInvalidNumber)
+	}
+	return "+" + code + national, nil
+}
+
+// hasTrunk reports whether a national number starts with the region's trunk prefix
+// and is long enough to still be valid without it.
+func hasTrunk(national string, r region) bool {
+	return r.trunk != "" && strings.HasPrefix(national, r.trunk) && len(national)-len(r.trunk) >= r.minDigits
+}
@@ -0,0 +1,57 @@
+package sms
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeNumber(t *testing.T) {
+	tests := []struct {
+		raw    string
+		region string
+		want   string
+	}{
+		{"+84 912 345 678", "", "+84912345678"},
+		{"0912 345 678", "VN", "+84912345678"},
+		{"(020) 7946 0018", "GB", "+442079460018"},
+		{"+44 (0)20 7946 0018", "", "+442079460018"},
+		{"0044 20 7946 0018", "VN", "+442079460018"},
+		{"011 44 20 7946 0018", "US", "+442079460018"},
+		{"(415) 555-2671", "US", "+14155552671"},
+		{"1-415-555-2671", "us", "+14155552671"},
+		{"06.12.34.56.78", "FR", "+33612345678"},
+		{"8 912 345 67 89", "RU", "+79123456789"},
+		{"06 1234 5678", "IT", "+390612345678"},   // Italian numbers keep their leading 0
+		{"+233 24 123 4567", "", "+233241234567"}, // No dialing rules, E.164 length only
+	}
+	for _, tt := range tests {
+		got, err := NormalizeNumber(tt.raw, tt.region)
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeNumber(%q, %q) = %q, %v, want %q", tt.raw, tt.region, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizeNumberInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		region string
+	}{
+		{"letters", "0912-ABC-678", "VN"},
+		{"plus inside", "84+912345678", "VN"},
+		{"unknown default region", "0912345678", ""},
+		{"unknown country code", "+999 123 456 789", ""},
+		{"too short for region", "+84 12", ""},
+		{"too long for region", "+1 415 555 26711", ""},
+		{"too long for E.164", "+233 1234 5678 90123", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeNumber(tt.raw, tt.region)
+			if !errors.Is(err, ErrInvalidNumber) {
+				t.Errorf("NormalizeNumber(%q, %q) = %q, %v, want ErrInvalidNumber", tt.raw, tt.region, got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/health"
+	"github.com/duynhne/notification-service/internal/logic/v1/throttle"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// DefaultRoute is the route prefix matching every number not covered by another route.
+const DefaultRoute = "*"
+
+var (
+	routeAttempts = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sms_route_attempts_total",
+			Help: "SMS send attempts per route and provider, by result (sent, failed, rejected, skipped, expired)",
+		},
+		[]string{"route", "provider", "result"},
+	)
+
+	routeFailovers = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sms_route_failovers_total",
+			Help: "SMS sends moved to the next provider of a route after an error",
+		},
+		[]string{"route", "provider"},
+	)
+
+	routeSegments = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sms_route_segments_total",
+			Help: "SMS segments accepted per route and provider (billing units)",
+		},
+		[]string{"route", "provider"},
+	)
+
+	routeDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "sms_route_send_duration_seconds",
+			Help:    "Duration of SMS provider requests in seconds",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10},
+		},
+		[]string{"route", "provider"},
+	)
+)
+
+// Route sends numbers starting with Prefix (a country calling code, optionally
+// followed by more digits, or DefaultRoute) through its targets.
+type Route struct {
+	Prefix  string
+	Targets []RouteTarget
+}
+
+// RouteTarget is a provider of a route and the sender ID it uses there.
+type RouteTarget struct {
+	Provider string
+	Weight   int // Relative share of the route's traffic
+	From     SenderID
+}
+
+// Delivery reports which route and provider accepted a message.
+type Delivery struct {
+	Route     string
+	Provider  string
+	MessageID string
+	Attempts  int
+}
+
+// Router selects the provider and sender ID for a message from the recipient's
+// country prefix. Each send picks a first provider at random by weight; when it
+// fails, the route's remaining providers are tried in weighted order. Providers whose
+// circuit breaker is open are skipped, and sends to each provider are paced by a
+// throttle.Limiter.
+type Router struct {
+	providers map[string]Provider
+	breakers  map[string]*health.Breaker
+	limiter   *throttle.Limiter
+	routes    []Route         // Longest prefix first
+	intN      func(n int) int // Random source of weightedOrder
+}
+
+// NewRouter creates a router. Every route target must name a provider in providers.
+// Breakers come from registry under the "sms" channel; registry and limiter may be nil.
+func NewRouter(
+	providers map[string]Provider,
+	routes []Route,
+	registry *health.Registry,
+	limiter *throttle.Limiter,
+) (*Router, error) {
+	sorted := make([]Route, 0, len(routes))
+	seen := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		prefix := strings.TrimPrefix(route.Prefix, "+")
+		if prefix != DefaultRoute && (prefix == "" || strings.Trim(prefix, "0123456789") != "") {
+			return nil, fmt.Errorf("sms route prefix %q must be digits or %q", route.Prefix, DefaultRoute)
+		}
+		if seen[prefix] {
+			return nil, fmt.Errorf("duplicate sms route %q", prefix)
+		}
+		seen[prefix] = true
+		if len(route.Targets) == 0 {
+			return nil, fmt.Errorf("sms route %q has no providers", prefix)
+		}
+		for _, target := range route.Targets {
+			if _, ok := providers[target.Provider]; !ok {
+				return nil, fmt.Errorf("sms route %q: provider %q is not configured", prefix, target.Provider)
+			}
+			if target.Weight <= 0 {
+				return nil, fmt.Errorf("sms route %q: provider %q needs a positive weight", prefix, target.Provider)
+			}
+		}
+		sorted = append(sorted, Route{Prefix: prefix, Targets: route.Targets})
+	}
+
+	// The default route sorts last: its prefix length counts as zero
+	slices.SortFunc(sorted, func(a, b Route) int {
+		return prefixLen(b.Prefix) - prefixLen(a.Prefix)
+	})
+	// Rank providers by name so the status endpoint lists them in a stable order
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	breakers := make(map[string]*health.Breaker, len(providers))
+	for _, name := range names {
+		breakers[name] = registry.Breaker("sms", name)
+	}
+	return &Router{
+		providers: providers,
+		breakers:  breakers,
+		limiter:   limiter,
+		routes:    sorted,
+		intN:      rand.IntN, //nolint:gosec // Load distribution, not security
+	}, nil
+}
+
+func prefixLen(prefix string) int {
+	if prefix == DefaultRoute {
+		return 0
+	}
+	return len(prefix)
+}
+
+// Send delivers msg through the route matching msg.To, failing over between the
+// route's providers. ErrInvalidDestination from a provider ends the attempt: the
+// number is unreachable, not the provider.
+func (r *Router) Send(ctx context.Context, msg *Message) (*Delivery, error) {
+	route, ok := r.match(msg.To)
+	if !ok {
+		return nil, fmt.Errorf("no sms route for %s: %w", msg.To, ErrInvalidDestination)
+	}
+	segments := float64(Analyze(msg.Text).Segments)
+
+	var errs []error
+	targets := weightedOrder(route.Targets, r.intN)
+	for i, target := range targets {
+		if i > 0 {
+			routeFailovers.WithLabelValues(route.Prefix, targets[i-1].Provider).Inc()
+		}
+
+		attempt := *msg
+		attempt.From = target.From
+		var id string
+		err := r.limiter.Do(ctx, "sms", target.Provider, func() error {
+			return r.breakers[target.Provider].Call(func() error {
+				var err error
+				start := time.Now()
+				id, err = r.providers[target.Provider].Send(ctx, &attempt)
+				routeDuration.WithLabelValues(route.Prefix, target.Provider).Observe(time.Since(start).Seconds())
+				return err
+			}, func(err error) bool {
+				return !errors.Is(err, ErrInvalidDestination) && !errors.Is(err, throttle.ErrRateLimited)
+			})
+		})
+
+		switch {
+		case err == nil:
+			routeAttempts.WithLabelValues(route.Prefix, target.Provider, "sent").Inc()
+			routeSegments.WithLabelValues(route.Prefix, target.Provider).Add(segments)
+			return &Delivery{Route: route.Prefix, Provider: target.Provider, MessageID: id, Attempts: i + 1}, nil
+		case errors.Is(err, ErrInvalidDestination):
+			routeAttempts.WithLabelValues(route.Prefix, target.Provider, "rejected").Inc()
+			return nil, fmt.Errorf("%s: %w", target.Provider, err)
+		case errors.Is(err, throttle.ErrExpired):
+			routeAttempts.WithLabelValues(route.Prefix, target.Provider, "expired").Inc()
+			return nil, fmt.Errorf("%s: %w", target.Provider, err)
+		case errors.Is(err, health.ErrCircuitOpen):
+			routeAttempts.WithLabelValues(route.Prefix, target.Provider, "skipped").Inc()
+			errs = append(errs, err)
+		default:
+			routeAttempts.WithLabelValues(route.Prefix, target.Provider, "failed").Inc()
+			errs = append(errs, fmt.Errorf("%s: %w", target.Provider, err))
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, fmt.Errorf("all sms providers of route %s failed: %w", route.Prefix, errors.Join(errs...))
+}
+
+// match returns the route with the longest prefix of the E.164 number.
+func (r *Router) match(to string) (Route, bool) {
+	digits := strings.TrimPrefix(to, "+")
+	for _, route := range r.routes {
+		if route.Prefix == DefaultRoute || strings.HasPrefix(digits, route.Prefix) {
+			return route, true
+		}
+	}
+	return Route{}, false
+}
+
+// weightedOrder returns targets in a random order where each position is drawn by
+// weight from the targets not yet placed. intN returns a number in [0, n).
+func weightedOrder(targets []RouteTarget, intN func(n int) int) []RouteTarget {
+	remaining := slices.Clone(targets)
+	ordered := make([]RouteTarget, 0, len(targets))
+	for len(remaining) > 0 {
+		total := 0
+		for _, t := range remaining {
+			total += t.Weight
+		}
+		pick := intN(total)
+		for i, t := range remaining {
+			if pick < t.Weight {
+				ordered = append(ordered, t)
+				remaining = slices.Delete(remaining, i, i+1)
+				break
+			}
+			pick -= t.Weight
+		}
+	}
+	return ordered
+}
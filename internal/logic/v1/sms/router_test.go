@@ -0,0 +1,271 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/health"
+)
+
+// providerFunc adapts a function to Provider.
+type providerFunc func(ctx context.Context, msg *Message) (string, error)
+
+func (f providerFunc) Send(ctx context.Context, msg *Message) (string, error) {
+	return f(ctx, msg)
+}
+
+// recorder is a set of providers that record the calls made to them, failing with
+// the error set for them.
+type recorder struct {
+	calls []string
+	from  []string
+	errs  map[string]error
+}
+
+func (r *recorder) providers(names ...string) map[string]Provider {
+	providers := make(map[string]Provider, len(names))
+	for _, name := range names {
+		providers[name] = providerFunc(func(_ context.Context, msg *Message) (string, error) {
+			r.calls = append(r.calls, name)
+			r.from = append(r.from, msg.From.Value)
+			if err := r.errs[name]; err != nil {
+				return "", err
+			}
+			return name + "-1", nil
+		})
+	}
+	return providers
+}
+
+// sequence returns an intN that draws the given numbers in turn.
+func sequence(t *testing.T, picks ...int) func(n int) int {
+	return func(n int) int {
+		if len(picks) == 0 {
+			t.Fatal("random source exhausted")
+		}
+		pick := picks[0]
+		picks = picks[1:]
+		if pick >= n {
+			t.Fatalf("pick %d out of range [0, %d)", pick, n)
+		}
+		return pick
+	}
+}
+
+func target(provider string, weight int) RouteTarget {
+	return RouteTarget{Provider: provider, Weight: weight, From: SenderID{Type: SenderAlphanumeric, Value: provider}}
+}
+
+func TestRouterMatch(t *testing.T) {
+	rec := &recorder{}
+	router, err := NewRouter(rec.providers("twilio", "vonage", "local"), []Route{
+		{Prefix: DefaultRoute, Targets: []RouteTarget{target("twilio", 1)}},
+		{Prefix: "+44", Targets: []RouteTarget{target("vonage", 1)}},
+		{Prefix: "447", Targets: []RouteTarget{target("local", 1)}},
+		{Prefix: "1", Targets: []RouteTarget{target("twilio", 1)}},
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		to    string
+		route string
+	}{
+		{"+447700900123", "447"},
+		{"+442079460018", "44"},
+		{"+14155552671", "1"},
+		{"+84912345678", DefaultRoute},
+	}
+	for _, tt := range tests {
+		delivery, err := router.Send(context.Background(), &Message{To: tt.to, Text: "hi"})
+		if err != nil {
+			t.Fatalf("Send(%s): %v", tt.to, err)
+		}
+		if delivery.Route != tt.route {
+			t.Errorf("Send(%s) route = %q, want %q", tt.to, delivery.Route, tt.route)
+		}
+	}
+
+	// Without a default route, numbers outside every prefix are unroutable
+	router, err = NewRouter(rec.providers("twilio"), []Route{{Prefix: "1", Targets: []RouteTarget{target("twilio", 1)}}}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := router.Send(context.Background(), &Message{To: "+84912345678"}); !errors.Is(err, ErrInvalidDestination) {
+		t.Errorf("Send without a matching route = %v, want ErrInvalidDestination", err)
+	}
+}
+
+func TestNewRouterInvalid(t *testing.T) {
+	rec := &recorder{}
+	providers := rec.providers("twilio")
+	tests := []struct {
+		name   string
+		routes []Route
+	}{
+		{"bad prefix", []Route{{Prefix: "44a", Targets: []RouteTarget{target("twilio", 1)}}}},
+		{"empty prefix", []Route{{Prefix: "", Targets: []RouteTarget{target("twilio", 1)}}}},
+		{"duplicate", []Route{
+			{Prefix: "44", Targets: []RouteTarget{target("twilio", 1)}},
+			{Prefix: "+44", Targets: []RouteTarget{target("twilio", 1)}},
+		}},
+		{"no providers", []Route{{Prefix: "44"}}},
+		{"unknown provider", []Route{{Prefix: "44", Targets: []RouteTarget{target("vonage", 1)}}}},
+		{"zero weight", []Route{{Prefix: "44", Targets: []RouteTarget{target("twilio", 0)}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRouter(providers, tt.routes, nil, nil); err == nil {
+				t.Error("NewRouter succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestWeightedOrder(t *testing.T) {
+	targets := []RouteTarget{target("a", 3), target("b", 1), target("c", 2)}
+	tests := []struct {
+		picks []int
+		want  []string
+	}{
+		{[]int{0, 0, 0}, []string{"a", "b", "c"}},
+		{[]int{2, 1, 0}, []string{"a", "c", "b"}},
+		{[]int{3, 0, 0}, []string{"b", "a", "c"}},
+		{[]int{4, 2, 0}, []string{"c", "a", "b"}},
+		{[]int{5, 3, 0}, []string{"c", "b", "a"}},
+	}
+	for _, tt := range tests {
+		got := weightedOrder(targets, sequence(t, tt.picks...))
+		names := make([]string, len(got))
+		for i, target := range got {
+			names[i] = target.Provider
+		}
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("weightedOrder with picks %v = %v, want %v", tt.picks, names, tt.want)
+		}
+	}
+}
+
+func TestRouterWeightedDistribution(t *testing.T) {
+	rec := &recorder{}
+	router, err := NewRouter(rec.providers("twilio", "vonage"), []Route{
+		{Prefix: DefaultRoute, Targets: []RouteTarget{target("twilio", 3), target("vonage", 1)}},
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.intN = rand.New(rand.NewPCG(1, 2)).IntN //nolint:gosec // Seeded for a repeatable test
+
+	const sends = 4000
+	for range sends {
+		if _, err := router.Send(context.Background(), &Message{To: "+84912345678", Text: "hi"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	counts := map[string]int{}
+	for _, name := range rec.calls {
+		counts[name]++
+	}
+	// 3:1 split, within 3 percentage points
+	if share := float64(counts["twilio"]) / sends; share < 0.72 || share > 0.78 {
+		t.Errorf("twilio share = %.3f (%v), want about 0.75", share, counts)
+	}
+}
+
+func TestRouterFailover(t *testing.T) {
+	routes := []Route{{Prefix: DefaultRoute, Targets: []RouteTarget{target("a", 1), target("b", 1), target("c", 1)}}}
+	tests := []struct {
+		name      string
+		errs      map[string]error
+		wantCalls []string
+		wantFrom  string // Provider of the delivery; "" when the send fails
+		wantErr   error
+	}{
+		{
+			name:      "first accepts",
+			wantCalls: []string{"b"},
+			wantFrom:  "b",
+		},
+		{
+			name:      "fails over in weighted order",
+			errs:      map[string]error{"b": errors.New("timeout"), "a": errors.New("500")},
+			wantCalls: []string{"b", "a", "c"},
+			wantFrom:  "c",
+		},
+		{
+			name:      "invalid destination stops",
+			errs:      map[string]error{"b": ErrInvalidDestination},
+			wantCalls: []string{"b"},
+			wantErr:   ErrInvalidDestination,
+		},
+		{
+			name:      "all fail",
+			errs:      map[string]error{"a": errors.New("500"), "b": errors.New("500"), "c": errors.New("500")},
+			wantCalls: []string{"b", "a", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{errs: tt.errs}
+			router, err := NewRouter(rec.providers("a", "b", "c"), routes, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Draws b, then a from {a, c}, then c
+			router.intN = sequence(t, 1, 0, 0)
+
+			delivery, err := router.Send(context.Background(), &Message{To: "+84912345678", Text: "hi"})
+			if !slices.Equal(rec.calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", rec.calls, tt.wantCalls)
+			}
+			// Each attempt uses the sender ID of its own provider
+			if !slices.Equal(rec.from, tt.wantCalls) {
+				t.Errorf("sender IDs = %v, want %v", rec.from, tt.wantCalls)
+			}
+			if tt.wantFrom == "" {
+				if err == nil {
+					t.Fatalf("Send = %+v, want an error", delivery)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Send error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			if delivery.Provider != tt.wantFrom || delivery.Attempts != len(tt.wantCalls) {
+				t.Errorf("Send = %+v, want provider %s after %d attempts", delivery, tt.wantFrom, len(tt.wantCalls))
+			}
+		})
+	}
+}
+
+func TestRouterSkipsOpenBreaker(t *testing.T) {
+	registry := health.NewRegistry(health.Config{MinRequests: 1, FailureRate: 0.5, OpenFor: time.Hour})
+	rec := &recorder{errs: map[string]error{"a": errors.New("500")}}
+	router, err := NewRouter(rec.providers("a", "b"), []Route{
+		{Prefix: DefaultRoute, Targets: []RouteTarget{target("a", 1), target("b", 1)}},
+	}, registry, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.intN = sequence(t, 0, 0, 0, 0)
+
+	// The first send fails on a, opening its breaker, and fails over to b
+	for range 2 {
+		delivery, err := router.Send(context.Background(), &Message{To: "+84912345678", Text: "hi"})
+		if err != nil || delivery.Provider != "b" {
+			t.Fatalf("Send = %+v, %v, want delivery through b", delivery, err)
+		}
+	}
+	// The second send skipped a without calling it
+	if want := []string{"a", "b", "b"}; !slices.Equal(rec.calls, want) {
+		t.Errorf("calls = %v, want %v", rec.calls, want)
+	}
+}
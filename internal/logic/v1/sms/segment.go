@@ -0,0 +1,126 @@
+package sms
+
+import "strings"
+
+// Encoding is the character set an SMS is sent in.
+type Encoding string
+
+const (
+	// EncodingGSM7 packs 7-bit characters of the GSM 03.38 alphabet: 160 per single
+	// message, 153 per part of a concatenated message.
+	EncodingGSM7 Encoding = "gsm7"
+	// EncodingUCS2 sends UTF-16 code units: 70 per single message, 67 per part.
+	EncodingUCS2 Encoding = "ucs2"
+)
+
+// gsm7Basic is the GSM 03.38 default alphabet (without the escape character).
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extension characters are sent as an escape plus a character and take two septets.
+const gsm7Extension = "\f^{}\\[~]|€"
+
+// Analysis describes how a message is encoded and billed.
+type Analysis struct {
+	Encoding Encoding
+	Units    int // Septets (GSM-7) or UTF-16 code units (UCS-2)
+	Segments int // Messages billed by the carrier
+}
+
+// Analyze picks the encoding for text and counts its segments. A single character
+// outside the GSM-7 alphabet (emoji, most accents, non-Latin scripts) switches the
+// whole message to UCS-2, more than halving its capacity.
+func Analyze(text string) Analysis {
+	encoding := encodingOf(text)
+	units := 0
+	for _, r := range text {
+		units += unitsOf(r, encoding)
+	}
+	return Analysis{Encoding: encoding, Units: units, Segments: countSegments(text, encoding)}
+}
+
+// Truncate shortens text to fit in maxSegments, keeping its encoding. Characters that
+// take two units are never split.
+func Truncate(text string, maxSegments int) string {
+	encoding := encodingOf(text)
+	if maxSegments <= 0 || countSegments(text, encoding) <= maxSegments {
+		return text
+	}
+	single, multi := limits(encoding)
+
+	if maxSegments == 1 {
+		return prefixWithin(text, encoding, single, 1)
+	}
+	return prefixWithin(text, encoding, multi, maxSegments)
+}
+
+func encodingOf(text string) Encoding {
+	for _, r := range text {
+		if !strings.ContainsRune(gsm7Basic, r) && !strings.ContainsRune(gsm7Extension, r) {
+			return EncodingUCS2
+		}
+	}
+	return EncodingGSM7
+}
+
+func unitsOf(r rune, encoding Encoding) int {
+	if encoding == EncodingGSM7 {
+		if strings.ContainsRune(gsm7Extension, r) {
+			return 2
+		}
+		return 1
+	}
+	if r > 0xFFFF {
+		return 2 // Surrogate pair
+	}
+	return 1
+}
+
+func limits(encoding Encoding) (single, multi int) {
+	if encoding == EncodingGSM7 {
+		return 160, 153
+	}
+	return 70, 67
+}
+
+// countSegments counts messages the way handsets split them: a part never ends in the
+// middle of a two-unit character.
+func countSegments(text string, encoding Encoding) int {
+	single, multi := limits(encoding)
+	total := 0
+	for _, r := range text {
+		total += unitsOf(r, encoding)
+	}
+	if total <= single {
+		return 1
+	}
+
+	segments, used := 1, 0
+	for _, r := range text {
+		n := unitsOf(r, encoding)
+		if used+n > multi {
+			segments++
+			used = 0
+		}
+		used += n
+	}
+	return segments
+}
+
+// prefixWithin returns the longest prefix of text that fits in parts parts of
+// partSize units, packed as countSegments does.
+func prefixWithin(text string, encoding Encoding, partSize, parts int) string {
+	segments, used := 1, 0
+	for i, r := range text {
+		n := unitsOf(r, encoding)
+		if used+n > partSize {
+			if segments == parts {
+				return text[:i]
+			}
+			segments++
+			used = 0
+		}
+		used += n
+	}
+	return text
+}
@@ -0,0 +1,68 @@
+package sms
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	a := func(n int) string { return strings.Repeat("a", n) }
+	tests := []struct {
+		name string
+		text string
+		want Analysis
+	}{
+		{"empty", "", Analysis{EncodingGSM7, 0, 1}},
+		{"gsm7 single", a(160), Analysis{EncodingGSM7, 160, 1}},
+		{"gsm7 concatenated", a(161), Analysis{EncodingGSM7, 161, 2}},
+		{"gsm7 two full parts", a(306), Analysis{EncodingGSM7, 306, 2}},
+		{"gsm7 third part", a(307), Analysis{EncodingGSM7, 307, 3}},
+		{"gsm7 accents", "Äpfel für 5€", Analysis{EncodingGSM7, 13, 1}},
+		{"extension counts twice", strings.Repeat("€", 80), Analysis{EncodingGSM7, 160, 1}},
+		{"extension over single", strings.Repeat("{", 81), Analysis{EncodingGSM7, 162, 2}},
+		// The escape sequence is not split, so the euro sign starts the second part
+		{"extension not split", a(152) + "€" + a(152), Analysis{EncodingGSM7, 306, 3}},
+		{"ucs2 single", "Xin chào bạn", Analysis{EncodingUCS2, 12, 1}},
+		{"ucs2 full single", strings.Repeat("ạ", 70), Analysis{EncodingUCS2, 70, 1}},
+		{"ucs2 concatenated", strings.Repeat("ạ", 71), Analysis{EncodingUCS2, 71, 2}},
+		{"ucs2 two full parts", strings.Repeat("ạ", 134), Analysis{EncodingUCS2, 134, 2}},
+		// Surrogate pairs are not split: 33 emoji fit in a 67-unit part
+		{"emoji", strings.Repeat("😀", 67), Analysis{EncodingUCS2, 134, 3}},
+		{"one emoji switches encoding", a(100) + "😀", Analysis{EncodingUCS2, 102, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Analyze(tt.text); got != tt.want {
+				t.Errorf("Analyze = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		maxSegments int
+		want        string
+	}{
+		{"fits", "hello", 1, "hello"},
+		{"unlimited", strings.Repeat("a", 400), 0, strings.Repeat("a", 400)},
+		{"gsm7 single", strings.Repeat("a", 200), 1, strings.Repeat("a", 160)},
+		{"gsm7 two parts", strings.Repeat("a", 400), 2, strings.Repeat("a", 306)},
+		{"extension not split", strings.Repeat("a", 159) + "€€", 1, strings.Repeat("a", 159)},
+		{"ucs2 single", strings.Repeat("ạ", 100), 1, strings.Repeat("ạ", 70)},
+		{"emoji not split", strings.Repeat("😀", 40), 1, strings.Repeat("😀", 35)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.text, tt.maxSegments)
+			if got != tt.want {
+				t.Errorf("Truncate = %q (%d bytes), want %q", got, len(got), tt.want)
+			}
+			if tt.maxSegments > 0 && Analyze(got).Segments > tt.maxSegments {
+				t.Errorf("Truncate result needs %d segments, want at most %d", Analyze(got).Segments, tt.maxSegments)
+			}
+		})
+	}
+}
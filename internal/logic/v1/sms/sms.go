@@ -0,0 +1,73 @@
+// Package sms provides SMS delivery for API version 1.
+//
+// It covers the SMS-specific parts of a send:
+//   - E.164 phone number normalization with a default region
+//   - GSM-7/UCS-2 encoding detection and segment counting
+//   - Providers (Twilio, Vonage) behind a common Provider interface
+//   - A Router that picks the provider and sender ID by the recipient's country
+//     prefix, with weighted distribution and failover between providers, skipping
+//     providers whose circuit breaker is open and pacing each provider's sends
+//
+// Provider clients accept a configurable base URL so they can be pointed at local
+// httptest stand-ins instead of the real provider endpoints.
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidDestination indicates the provider rejected the recipient permanently
+// (not a mobile number, blocked or opted out). Other providers are not tried.
+var ErrInvalidDestination = errors.New("invalid sms destination")
+
+// SenderType is the kind of sender ID a message is sent from.
+type SenderType string
+
+const (
+	SenderAlphanumeric SenderType = "alphanumeric" // Brand name, one-way; not allowed in every country
+	SenderLongCode     SenderType = "long_code"    // Regular E.164 number
+	SenderShortCode    SenderType = "short_code"   // 3-8 digit number provisioned per country
+)
+
+// SenderID is the originator shown to the recipient.
+type SenderID struct {
+	Type  SenderType
+	Value string
+}
+
+// ParseSenderID infers the sender type: "+" followed by digits is a long code, 3-8
+// digits a short code, and up to 11 letters, digits and spaces with at least one
+// letter an alphanumeric sender.
+func ParseSenderID(value string) (SenderID, error) {
+	digits := strings.TrimPrefix(value, "+")
+	allDigits := digits != "" && strings.Trim(digits, "0123456789") == ""
+
+	switch {
+	case strings.HasPrefix(value, "+") && allDigits && len(digits) >= 8 && len(digits) <= 15:
+		return SenderID{Type: SenderLongCode, Value: value}, nil
+	case !strings.HasPrefix(value, "+") && allDigits && len(digits) >= 3 && len(digits) <= 8:
+		return SenderID{Type: SenderShortCode, Value: value}, nil
+	case len(value) >= 1 && len(value) <= 11 && !allDigits &&
+		strings.Trim(value, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 ") == "":
+		return SenderID{Type: SenderAlphanumeric, Value: value}, nil
+	default:
+		return SenderID{}, fmt.Errorf("sender id %q is not an E.164 long code, a 3-8 digit short code "+
+			"or 1-11 alphanumeric characters", value)
+	}
+}
+
+// Message is a single SMS to one recipient.
+type Message struct {
+	To   string   // E.164 recipient
+	From SenderID // Set by the Router from the matching route
+	Text string
+}
+
+// Provider delivers SMS through one aggregator account. It returns the provider's
+// message ID on acceptance.
+type Provider interface {
+	Send(ctx context.Context, msg *Message) (string, error)
+}
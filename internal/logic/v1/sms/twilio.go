@@ -0,0 +1,109 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/throttle"
+)
+
+// DefaultTwilioBaseURL is the production Twilio REST API endpoint.
+const DefaultTwilioBaseURL = "https://api.twilio.com"
+
+// Twilio error codes for recipients that can never be reached.
+var twilioInvalidDestination = map[int]bool{
+	21211: true, // Invalid 'To' phone number
+	21408: true, // Permission to send to this region is not enabled
+	21610: true, // Recipient replied STOP
+	21612: true, // 'To' number cannot receive messages from this sender
+	21614: true, // 'To' number is not a mobile number
+}
+
+// TwilioConfig configures the Twilio Programmable Messaging client.
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	BaseURL    string       // Overrides DefaultTwilioBaseURL (e.g. an httptest server)
+	HTTPClient *http.Client // Optional; defaults to a client with a 10s timeout
+}
+
+// TwilioClient sends SMS through the Twilio Messages API.
+type TwilioClient struct {
+	accountSID string
+	authToken  string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewTwilioClient creates a Twilio client.
+func NewTwilioClient(cfg TwilioConfig) (*TwilioClient, error) {
+	if cfg.AccountSID == "" || cfg.AuthToken == "" {
+		return nil, errors.New("twilio account sid and auth token are required")
+	}
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultTwilioBaseURL
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &TwilioClient{
+		accountSID: cfg.AccountSID,
+		authToken:  cfg.AuthToken,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}, nil
+}
+
+// Send implements Provider.
+func (c *TwilioClient) Send(ctx context.Context, msg *Message) (string, error) {
+	form := url.Values{}
+	form.Set("To", msg.To)
+	form.Set("From", msg.From.Value)
+	form.Set("Body", msg.Text)
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", c.baseURL, url.PathEscape(c.accountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("create twilio request: %w", err)
+	}
+	req.SetBasicAuth(c.accountSID, c.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req) //nolint:gosec
+	if err != nil {
+		return "", fmt.Errorf("request twilio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var body struct {
+		SID     string `json:"sid"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(raw, &body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return body.SID, nil
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", throttle.RateLimited(fmt.Errorf("twilio error: %d - %d %s", resp.StatusCode, body.Code, body.Message),
+			throttle.ParseRetryAfter(resp.Header.Get("Retry-After")))
+	}
+	if twilioInvalidDestination[body.Code] {
+		return "", fmt.Errorf("twilio %d %s: %w", body.Code, body.Message, ErrInvalidDestination)
+	}
+	if body.Code != 0 {
+		return "", fmt.Errorf("twilio error: %d - %d %s", resp.StatusCode, body.Code, body.Message)
+	}
+	return "", fmt.Errorf("twilio error: %d - %s", resp.StatusCode, string(raw))
+}
@@ -0,0 +1,125 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/throttle"
+)
+
+// DefaultVonageBaseURL is the production Vonage SMS API endpoint.
+const DefaultVonageBaseURL = "https://rest.nexmo.com"
+
+// vonageThrottled is the status of a message refused for exceeding the account's
+// messages per second.
+const vonageThrottled = "1"
+
+// Vonage status codes for recipients that can never be reached.
+var vonageInvalidDestination = map[string]bool{
+	"6":  true, // Invalid message: the destination is not routable
+	"7":  true, // Number barred
+	"29": true, // Non-whitelisted destination
+}
+
+// VonageConfig configures the Vonage SMS API client.
+type VonageConfig struct {
+	APIKey     string
+	APISecret  string
+	BaseURL    string       // Overrides DefaultVonageBaseURL (e.g. an httptest server)
+	HTTPClient *http.Client // Optional; defaults to a client with a 10s timeout
+}
+
+// VonageClient sends SMS through the Vonage (Nexmo) SMS API.
+type VonageClient struct {
+	apiKey     string
+	apiSecret  string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewVonageClient creates a Vonage client.
+func NewVonageClient(cfg VonageConfig) (*VonageClient, error) {
+	if cfg.APIKey == "" || cfg.APISecret == "" {
+		return nil, errors.New("vonage api key and secret are required")
+	}
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultVonageBaseURL
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &VonageClient{
+		apiKey:     cfg.APIKey,
+		apiSecret:  cfg.APISecret,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}, nil
+}
+
+// Send implements Provider. Vonage splits long messages itself and reports a status
+// per part; the message is accepted only if every part is.
+func (c *VonageClient) Send(ctx context.Context, msg *Message) (string, error) {
+	form := url.Values{}
+	form.Set("api_key", c.apiKey)
+	form.Set("api_secret", c.apiSecret)
+	form.Set("to", strings.TrimPrefix(msg.To, "+"))
+	form.Set("from", strings.TrimPrefix(msg.From.Value, "+"))
+	form.Set("text", msg.Text)
+	if encodingOf(msg.Text) == EncodingUCS2 {
+		form.Set("type", "unicode")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/sms/json", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("create vonage request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req) //nolint:gosec
+	if err != nil {
+		return "", fmt.Errorf("request vonage: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", throttle.RateLimited(fmt.Errorf("vonage error: %d - %s", resp.StatusCode, string(raw)),
+			throttle.ParseRetryAfter(resp.Header.Get("Retry-After")))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vonage error: %d - %s", resp.StatusCode, string(raw))
+	}
+
+	var body struct {
+		Messages []struct {
+			Status    string `json:"status"`
+			MessageID string `json:"message-id"`
+			ErrorText string `json:"error-text"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(raw, &body); err != nil || len(body.Messages) == 0 {
+		return "", fmt.Errorf("vonage error: unexpected response %s", string(raw))
+	}
+	for _, part := range body.Messages {
+		if part.Status == "0" {
+			continue
+		}
+		if part.Status == vonageThrottled {
+			return "", throttle.RateLimited(fmt.Errorf("vonage status %s: %s", part.Status, part.ErrorText), 0)
+		}
+		if vonageInvalidDestination[part.Status] {
+			return "", fmt.Errorf("vonage status %s %s: %w", part.Status, part.ErrorText, ErrInvalidDestination)
+		}
+		return "", fmt.Errorf("vonage status %s: %s", part.Status, part.ErrorText)
+	}
+	return body.Messages[0].MessageID, nil
+}
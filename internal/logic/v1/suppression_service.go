@@ -0,0 +1,267 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/internal/logic/v1/email"
+	"github.com/duynhne/notification-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SuppressionService maintains the email suppression list from provider bounce and
+// complaint events and from DSN messages, and guards the send path against it.
+type SuppressionService struct {
+	repo          domain.SuppressionRepository
+	callbacks     *CallbackService
+	softBounceTTL time.Duration
+}
+
+// NewSuppressionService creates a SuppressionService. Soft bounces suppress an address
+// for softBounceTTL; 0 ignores soft bounces. Hard bounces are reported to the calling
+// service through callbacks when the notification is known.
+func NewSuppressionService(
+	repo domain.SuppressionRepository,
+	callbacks *CallbackService,
+	softBounceTTL time.Duration,
+) *SuppressionService {
+	return &SuppressionService{
+		repo:          repo,
+		callbacks:     callbacks,
+		softBounceTTL: softBounceTTL,
+	}
+}
+
+// Check returns ErrRecipientSuppressed if the address must not be mailed.
+func (s *SuppressionService) Check(ctx context.Context, address string) error {
+	suppression, err := s.repo.FindActive(ctx, email.NormalizeAddress(address))
+	if err != nil {
+		return fmt.Errorf("check suppression: %w", err)
+	}
+	if suppression != nil {
+		return fmt.Errorf("recipient %q suppressed (%s): %w", address, suppression.Reason, ErrRecipientSuppressed)
+	}
+	return nil
+}
+
+// Filter returns the addresses that are not suppressed, keeping their order.
+func (s *SuppressionService) Filter(ctx context.Context, addresses []string) ([]string, error) {
+	allowed := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		err := s.Check(ctx, address)
+		if errors.Is(err, ErrRecipientSuppressed) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		allowed = append(allowed, address)
+	}
+	return allowed, nil
+}
+
+// ProcessEvents applies bounce and complaint events posted by an email provider.
+func (s *SuppressionService) ProcessEvents(ctx context.Context, req domain.EmailEventsRequest) (*domain.EmailEventsResult, error) {
+	ctx, span := middleware.StartSpan(ctx, "suppression.process_events", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("email.provider", req.Provider),
+		attribute.Int("email.events", len(req.Events)),
+	))
+	defer span.End()
+
+	result, err := s.apply(ctx, req.Provider, req.Events)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("email.suppressed", result.Suppressed))
+	return result, nil
+}
+
+// ProcessDSN applies a raw delivery status notification (RFC 3464) or abuse feedback
+// report (RFC 5965), e.g. forwarded from the bounce mailbox.
+func (s *SuppressionService) ProcessDSN(ctx context.Context, r io.Reader) (*domain.EmailEventsResult, error) {
+	ctx, span := middleware.StartSpan(ctx, "suppression.process_dsn", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	report, err := email.ParseReport(r)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("parse report: %w: %w", err, ErrInvalidReport)
+	}
+
+	var notificationID string
+	if report.OriginalHeaders != nil {
+		notificationID = report.OriginalHeaders.Get(email.HeaderNotificationID)
+	}
+
+	var events []domain.EmailEvent
+	result := &domain.EmailEventsResult{}
+	for _, rcpt := range report.Recipients {
+		event := domain.EmailEvent{
+			Recipient:      rcpt.Address,
+			Diagnostic:     rcpt.Diagnostic,
+			NotificationID: notificationID,
+		}
+		switch {
+		case report.Type == email.ReportFeedback:
+			event.Type = "complaint"
+		case rcpt.IsHardBounce():
+			event.Type, event.BounceType = "bounce", "hard"
+		case rcpt.IsSoftBounce():
+			event.Type, event.BounceType = "bounce", "soft"
+		default:
+			// delivered, relayed and expanded actions need no action
+			result.Processed++
+			result.Ignored++
+			continue
+		}
+		events = append(events, event)
+	}
+
+	applied, err := s.apply(ctx, "dsn", events)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	result.Processed += applied.Processed
+	result.Suppressed += applied.Suppressed
+	result.Ignored += applied.Ignored
+	span.SetAttributes(
+		attribute.String("email.report_type", string(report.Type)),
+		attribute.Int("email.suppressed", result.Suppressed),
+	)
+	return result, nil
+}
+
+// apply suppresses the recipients of bounce and complaint events and marks hard-bounced
+// notifications as bounced.
+func (s *SuppressionService) apply(ctx context.Context, source string, events []domain.EmailEvent) (*domain.EmailEventsResult, error) {
+	result := &domain.EmailEventsResult{}
+	for _, event := range events {
+		result.Processed++
+
+		suppression := &domain.Suppression{
+			Address:        email.NormalizeAddress(event.Recipient),
+			Source:         source,
+			Detail:         event.Diagnostic,
+			NotificationID: event.NotificationID,
+		}
+		if suppression.Address == "" {
+			result.Ignored++
+			continue
+		}
+
+		var ttl time.Duration
+		switch {
+		case event.Type == "complaint":
+			suppression.Reason = domain.SuppressionComplaint
+		case event.BounceType == "soft":
+			if s.softBounceTTL <= 0 {
+				result.Ignored++
+				continue
+			}
+			suppression.Reason = domain.SuppressionSoftBounce
+			ttl = s.softBounceTTL
+		default:
+			suppression.Reason = domain.SuppressionHardBounce
+		}
+
+		if err := s.repo.Upsert(ctx, suppression, ttl); err != nil {
+			return nil, fmt.Errorf("suppress %q: %w", suppression.Address, err)
+		}
+		result.Suppressed++
+
+		if suppression.Reason == domain.SuppressionHardBounce && event.NotificationID != "" {
+			if err := s.markBounced(ctx, event); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// markBounced reports a hard bounce on the notification. Reports for unknown
+// notifications or ones already bounced are ignored, so providers can safely redeliver.
+func (s *SuppressionService) markBounced(ctx context.Context, event domain.EmailEvent) error {
+	_, err := s.callbacks.UpdateStatus(ctx, event.NotificationID, domain.UpdateStatusRequest{
+		Status: domain.StatusBounced,
+		Reason: event.Diagnostic,
+	})
+	if err != nil && !errors.Is(err, ErrNotificationNotFound) && !errors.Is(err, ErrInvalidStatusTransition) {
+		return fmt.Errorf("mark notification %s bounced: %w", event.NotificationID, err)
+	}
+	return nil
+}
+
+// Add suppresses an address on behalf of an operator or calling service.
+func (s *SuppressionService) Add(ctx context.Context, req domain.CreateSuppressionRequest) (*domain.Suppression, error) {
+	ctx, span := middleware.StartSpan(ctx, "suppression.add", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	suppression := &domain.Suppression{
+		Address: email.NormalizeAddress(req.Address),
+		Reason:  req.Reason,
+		Source:  "api",
+		Detail:  req.Detail,
+	}
+	if suppression.Address == "" {
+		return nil, fmt.Errorf("suppress %q: %w", req.Address, ErrInvalidRecipient)
+	}
+	if suppression.Reason == "" {
+		suppression.Reason = domain.SuppressionManual
+	}
+
+	if err := s.repo.Upsert(ctx, suppression, time.Duration(req.ExpiresInSeconds)*time.Second); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return suppression, nil
+}
+
+// Get returns the active suppression of an address.
+func (s *SuppressionService) Get(ctx context.Context, address string) (*domain.Suppression, error) {
+	ctx, span := middleware.StartSpan(ctx, "suppression.get", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	suppression, err := s.repo.FindActive(ctx, email.NormalizeAddress(address))
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if suppression == nil {
+		return nil, fmt.Errorf("get suppression %q: %w", address, ErrSuppressionNotFound)
+	}
+	return suppression, nil
+}
+
+// Remove lifts the suppression of an address (e.g. after the recipient fixed their mailbox).
+func (s *SuppressionService) Remove(ctx context.Context, address string) error {
+	ctx, span := middleware.StartSpan(ctx, "suppression.remove", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	deleted, err := s.repo.Delete(ctx, email.NormalizeAddress(address))
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("remove suppression %q: %w", address, ErrSuppressionNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/internal/logic/v1/retry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	throttleWait = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "provider_throttle_wait_seconds",
+			Help:    "Time sends waited for a provider send slot in seconds",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60},
+		},
+		[]string{"channel", "provider", "priority"},
+	)
+
+	throttleQueued = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "provider_throttle_queued",
+			Help: "Sends waiting for a provider send slot",
+		},
+		[]string{"channel", "provider", "priority"},
+	)
+
+	rateLimitedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "provider_rate_limited_total",
+			Help: "Rate-limit responses from channel providers",
+		},
+		[]string{"channel", "provider"},
+	)
+)
+
+// Limiter paces provider calls. A nil Limiter makes every call immediately.
+type Limiter struct {
+	slots     domain.ProviderSlotRepository // Schedule shared by all replicas
+	local     *MemorySlots                  // Used for unlimited providers and when slots fails
+	intervals map[string]time.Duration      // By "channel/provider"
+	policy    retry.Policy                  // Attempts and backoff after rate-limit responses
+
+	mu     sync.Mutex
+	queues map[string]*queue // Sends waiting for their turn, by "channel/provider"
+}
+
+// NewLimiter creates a limiter. rates holds sends per second by "channel/provider"
+// key (e.g. "sms/twilio"); providers without a rate are only paused after rate-limit
+// responses, in this replica. slots may be nil to keep the schedule in memory.
+// policy.MaxAttempts bounds the attempts of a send that keeps getting rate limited.
+func NewLimiter(slots domain.ProviderSlotRepository, rates map[string]float64, policy retry.Policy) *Limiter {
+	intervals := make(map[string]time.Duration, len(rates))
+	for key, rate := range rates {
+		if rate > 0 {
+			intervals[key] = time.Duration(float64(time.Second) / rate)
+		}
+	}
+	return &Limiter{
+		slots:     slots,
+		local:     NewMemorySlots(),
+		intervals: intervals,
+		policy:    policy,
+		queues:    make(map[string]*queue),
+	}
+}
+
+// Do waits for a send slot of the provider and runs fn. Sends waiting for the same
+// provider go in order of the priority set with WithPriority. When fn returns a
+// RateLimitError the provider is paused and fn retried, up to the policy's attempts;
+// the last rate-limit error is returned if they run out. A send whose expiry (see
+// WithExpiry) passes before it gets a slot returns ErrExpired without calling fn.
+func (l *Limiter) Do(ctx context.Context, channel, provider string, fn func() error) error {
+	if l == nil {
+		return fn()
+	}
+	key := channel + "/" + provider
+	priority := PriorityFrom(ctx)
+	waitCtx := ctx
+	if expiresAt, ok := ctx.Value(expiryKey{}).(time.Time); ok {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithDeadline(ctx, expiresAt)
+		defer cancel()
+	}
+	for attempt := 1; ; attempt++ {
+		if err := l.wait(waitCtx, channel, provider, priority, key); err != nil {
+			if ctx.Err() == nil {
+				return ErrExpired
+			}
+			return err
+		}
+		err := fn()
+
+		var limited *RateLimitError
+		if !errors.As(err, &limited) {
+			return err
+		}
+		rateLimitedTotal.WithLabelValues(channel, provider).Inc()
+		if attempt >= l.policy.MaxAttempts {
+			return err
+		}
+		pause := limited.RetryAfter
+		if pause <= 0 {
+			pause = l.policy.Backoff(attempt)
+		}
+		l.pause(ctx, key, pause)
+	}
+}
+
+// wait takes the provider's turn, reserves its next send slot and sleeps until the
+// slot starts. The turn passes on then, so one send per replica holds a reservation
+// and the next slot goes to the highest-priority send waiting at that point.
+func (l *Limiter) wait(ctx context.Context, channel, provider, priority, key string) error {
+	start := time.Now()
+	defer func() {
+		throttleWait.WithLabelValues(channel, provider, priority).Observe(time.Since(start).Seconds())
+	}()
+	queued := throttleQueued.WithLabelValues(channel, provider, priority)
+	queued.Inc()
+	defer queued.Dec()
+
+	q := l.queue(key)
+	if !q.acquire(ctx, laneOf(priority)) {
+		return ctx.Err()
+	}
+	defer q.release()
+
+	interval, limited := l.intervals[key]
+
+	var delay time.Duration
+	var err error
+	if limited && l.slots != nil {
+		delay, err = l.slots.Reserve(ctx, key, interval)
+	}
+	if !limited || l.slots == nil || err != nil {
+		// Without the shared schedule each replica paces itself
+		delay, _ = l.local.Reserve(ctx, key, interval)
+	}
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// queue returns the send queue of a provider.
+func (l *Limiter) queue(key string) *queue {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	q, ok := l.queues[key]
+	if !ok {
+		q = &queue{}
+		l.queues[key] = q
+	}
+	return q
+}
+
+// pause pushes the provider's schedule back by d.
+func (l *Limiter) pause(ctx context.Context, key string, d time.Duration) {
+	if _, limited := l.intervals[key]; limited && l.slots != nil {
+		if err := l.slots.Pause(ctx, key, d); err == nil {
+			return
+		}
+	}
+	_ = l.local.Pause(ctx, key, d)
+}
+
+// MemorySlots is an in-process send schedule, for a single replica or when the
+// shared schedule is unavailable.
+type MemorySlots struct {
+	now func() time.Time // Clock of the schedule
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// NewMemorySlots creates an empty schedule.
+func NewMemorySlots() *MemorySlots {
+	return &MemorySlots{now: time.Now, next: make(map[string]time.Time)}
+}
+
+// Reserve implements domain.ProviderSlotRepository.
+func (m *MemorySlots) Reserve(_ context.Context, provider string, interval time.Duration) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	slot := m.next[provider]
+	if slot.Before(now) {
+		slot = now
+	}
+	m.next[provider] = slot.Add(interval)
+	return slot.Sub(now), nil
+}
+
+// Pause implements domain.ProviderSlotRepository.
+func (m *MemorySlots) Pause(_ context.Context, provider string, d time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if until := m.now().Add(d); until.After(m.next[provider]) {
+		m.next[provider] = until
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/retry"
+)
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestMemorySlots(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	slots := NewMemorySlots()
+	slots.now = clock.Now
+	ctx := context.Background()
+	interval := 100 * time.Millisecond
+
+	reserve := func(want time.Duration) {
+		t.Helper()
+		if got, _ := slots.Reserve(ctx, "sms/twilio", interval); got != want {
+			t.Errorf("Reserve = %v, want %v", got, want)
+		}
+	}
+
+	// Sends reserved at once are spaced by the interval
+	reserve(0)
+	reserve(100 * time.Millisecond)
+	reserve(200 * time.Millisecond)
+
+	// Time passing uses up the reserved slots
+	clock.Advance(250 * time.Millisecond)
+	reserve(50 * time.Millisecond)
+
+	// An idle provider's next send goes immediately
+	clock.Advance(time.Second)
+	reserve(0)
+
+	// A pause pushes back the schedule, but never brings it forward
+	_ = slots.Pause(ctx, "sms/twilio", 2*time.Second)
+	_ = slots.Pause(ctx, "sms/twilio", time.Second)
+	reserve(2 * time.Second)
+
+	// Providers are scheduled independently
+	if got, _ := slots.Reserve(ctx, "sms/vonage", interval); got != 0 {
+		t.Errorf("Reserve(sms/vonage) = %v, want 0", got)
+	}
+}
+
+func TestLimiterRateLimited(t *testing.T) {
+	policy := retry.Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 2}
+	tests := []struct {
+		name      string
+		limited   int // Calls answered with a rate-limit error before one succeeds
+		wantCalls int
+		wantErr   bool
+	}{
+		{"not limited", 0, 1, false},
+		{"recovers", 2, 3, false},
+		{"gives up", 5, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewLimiter(nil, nil, policy)
+			calls := 0
+			err := limiter.Do(context.Background(), "push", "fcm", func() error {
+				calls++
+				if calls <= tt.limited {
+					return RateLimited(errors.New("429"), time.Millisecond)
+				}
+				return nil
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantErr != errors.Is(err, ErrRateLimited) {
+				t.Errorf("Do = %v, want rate limited %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLimiterOtherErrors(t *testing.T) {
+	limiter := NewLimiter(nil, nil, retry.Policy{MaxAttempts: 3})
+	want := errors.New("500")
+	calls := 0
+	err := limiter.Do(context.Background(), "push", "fcm", func() error {
+		calls++
+		return want
+	})
+	if calls != 1 || !errors.Is(err, want) {
+		t.Errorf("Do = %v after %d calls, want the provider error after 1 call", err, calls)
+	}
+}
+
+func TestLimiterExpired(t *testing.T) {
+	limiter := NewLimiter(nil, nil, retry.Policy{MaxAttempts: 1})
+	ctx := WithExpiry(context.Background(), time.Now().Add(-time.Second))
+	err := limiter.Do(ctx, "sms", "twilio", func() error {
+		t.Error("expired send was made")
+		return nil
+	})
+	if !errors.Is(err, ErrExpired) {
+		t.Errorf("Do = %v, want ErrExpired", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{" 5 ", 5 * time.Second},
+		{"-3", 0},
+		{"soon", 0},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0}, // In the past
+	}
+	for _, tt := range tests {
+		if got := ParseRetryAfter(tt.header); got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
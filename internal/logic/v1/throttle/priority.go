@@ -0,0 +1,133 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose sends queue in the lane of priority
+// (domain.PriorityCritical, ...). Sends without a priority queue as normal.
+func WithPriority(ctx context.Context, priority string) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityFrom returns the priority set by WithPriority, or domain.PriorityNormal.
+func PriorityFrom(ctx context.Context) string {
+	if priority, ok := ctx.Value(priorityKey{}).(string); ok && laneOf(priority) >= 0 {
+		return priority
+	}
+	return domain.PriorityNormal
+}
+
+// lanes lists the priorities in the order their lanes are served. Critical sends
+// always go first; the other lanes share the remaining slots by weight so a backlog
+// of low-priority sends still moves while higher lanes are busy.
+var lanes = [...]struct {
+	priority string
+	weight   int // 0 = strict priority over the weighted lanes
+}{
+	{domain.PriorityCritical, 0},
+	{domain.PriorityHigh, 4},
+	{domain.PriorityNormal, 2},
+	{domain.PriorityLow, 1},
+}
+
+func laneOf(priority string) int {
+	for i, lane := range lanes {
+		if lane.priority == priority {
+			return i
+		}
+	}
+	return -1
+}
+
+type waiter struct {
+	ready chan struct{} // Closed when the waiter is given the turn
+}
+
+// queue orders the sends of one provider. Only the send holding the turn reserves a
+// slot, so sends queue here by priority rather than in the slot schedule by arrival.
+type queue struct {
+	mu      sync.Mutex
+	busy    bool // A send holds the turn
+	waiting [len(lanes)][]*waiter
+	credit  [len(lanes)]int // Smooth weighted round-robin state of the weighted lanes
+}
+
+// acquire waits for the turn. It returns false without the turn if ctx ends first.
+func (q *queue) acquire(ctx context.Context, lane int) bool {
+	q.mu.Lock()
+	if !q.busy {
+		q.busy = true
+		q.mu.Unlock()
+		return true
+	}
+	w := &waiter{ready: make(chan struct{})}
+	q.waiting[lane] = append(q.waiting[lane], w)
+	q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return true
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	select {
+	case <-w.ready:
+		// Given the turn while giving up; pass it on
+		q.mu.Unlock()
+		q.release()
+		return false
+	default:
+	}
+	for i, other := range q.waiting[lane] {
+		if other == w {
+			q.waiting[lane] = append(q.waiting[lane][:i], q.waiting[lane][i+1:]...)
+			break
+		}
+	}
+	q.mu.Unlock()
+	return false
+}
+
+// release hands the turn to the next waiter, if any.
+func (q *queue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	lane := q.next()
+	if lane < 0 {
+		q.busy = false
+		return
+	}
+	w := q.waiting[lane][0]
+	q.waiting[lane][0] = nil
+	q.waiting[lane] = q.waiting[lane][1:]
+	close(w.ready)
+}
+
+// next picks the lane served next, or -1 when nothing waits. The caller holds mu.
+func (q *queue) next() int {
+	total, best := 0, -1
+	for i, lane := range lanes {
+		if len(q.waiting[i]) == 0 {
+			continue
+		}
+		if lane.weight == 0 {
+			return i
+		}
+		q.credit[i] += lane.weight
+		total += lane.weight
+		if best < 0 || q.credit[i] > q.credit[best] {
+			best = i
+		}
+	}
+	if best >= 0 {
+		q.credit[best] -= total
+	}
+	return best
+}
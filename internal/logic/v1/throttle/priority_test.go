@@ -0,0 +1,152 @@
+package throttle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+)
+
+// lanedQueue is a busy queue with waiters in some of its lanes.
+type lanedQueue struct {
+	q       *queue
+	waiters []*waiter
+	lanes   []string // Priority of each waiter
+}
+
+func newLanedQueue() *lanedQueue {
+	return &lanedQueue{q: &queue{busy: true}}
+}
+
+// add queues n waiters at priority.
+func (l *lanedQueue) add(priority string, n int) {
+	lane := laneOf(priority)
+	for range n {
+		w := &waiter{ready: make(chan struct{})}
+		l.q.waiting[lane] = append(l.q.waiting[lane], w)
+		l.waiters = append(l.waiters, w)
+		l.lanes = append(l.lanes, priority)
+	}
+}
+
+// serve releases the turn n times and returns the priority of each waiter served.
+func (l *lanedQueue) serve(t *testing.T, n int) []string {
+	t.Helper()
+	served := make([]string, 0, n)
+	for range n {
+		l.q.release()
+		found := false
+		for i, w := range l.waiters {
+			select {
+			case <-w.ready:
+			default:
+				continue
+			}
+			served = append(served, l.lanes[i])
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			l.lanes = append(l.lanes[:i], l.lanes[i+1:]...)
+			found = true
+			break
+		}
+		if !found {
+			t.Fatalf("release %d served no waiter", len(served)+1)
+		}
+	}
+	return served
+}
+
+func TestQueueWeightedLanes(t *testing.T) {
+	l := newLanedQueue()
+	l.add(domain.PriorityHigh, 20)
+	l.add(domain.PriorityNormal, 20)
+	l.add(domain.PriorityLow, 20)
+
+	// Smooth weighted round-robin interleaves the lanes 4:2:1 within every 7 turns
+	want := []string{"high", "normal", "high", "low", "high", "normal", "high"}
+	for round := range 2 {
+		got := l.serve(t, len(want))
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("round %d served %v, want %v", round, got, want)
+			}
+		}
+	}
+}
+
+func TestQueueCriticalFirst(t *testing.T) {
+	l := newLanedQueue()
+	l.add(domain.PriorityLow, 5)
+	l.add(domain.PriorityHigh, 5)
+	l.add(domain.PriorityCritical, 3)
+
+	got := l.serve(t, 4)
+	want := []string{"critical", "critical", "critical", "high"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("served %v, want %v", got, want)
+		}
+	}
+
+	// Critical sends arriving later still go before the backlog
+	l.add(domain.PriorityCritical, 1)
+	if got := l.serve(t, 1); got[0] != "critical" {
+		t.Errorf("served %v, want critical", got)
+	}
+}
+
+func TestQueueLowLaneProgresses(t *testing.T) {
+	l := newLanedQueue()
+	l.add(domain.PriorityHigh, 100)
+	l.add(domain.PriorityLow, 100)
+
+	// With only high and low waiting, low gets one turn in five
+	counts := map[string]int{}
+	for _, priority := range l.serve(t, 50) {
+		counts[priority]++
+	}
+	if counts["high"] != 40 || counts["low"] != 10 {
+		t.Errorf("served %v, want 40 high and 10 low", counts)
+	}
+}
+
+func TestQueueAcquire(t *testing.T) {
+	q := &queue{}
+	if !q.acquire(context.Background(), laneOf(domain.PriorityNormal)) {
+		t.Fatal("acquire on an idle queue did not take the turn")
+	}
+
+	// A waiter that gives up leaves its lane
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if q.acquire(ctx, laneOf(domain.PriorityNormal)) {
+		t.Fatal("acquire with a cancelled context took the busy turn")
+	}
+	for lane, waiting := range q.waiting {
+		if len(waiting) != 0 {
+			t.Errorf("lane %d holds %d waiters after cancellation", lane, len(waiting))
+		}
+	}
+
+	q.release()
+	if q.busy {
+		t.Error("queue still busy after the last release")
+	}
+}
+
+func TestPriorityFrom(t *testing.T) {
+	tests := []struct {
+		ctx  context.Context
+		want string
+	}{
+		{context.Background(), domain.PriorityNormal},
+		{WithPriority(context.Background(), domain.PriorityCritical), domain.PriorityCritical},
+		{WithPriority(context.Background(), domain.PriorityLow), domain.PriorityLow},
+		{WithPriority(context.Background(), "urgent"), domain.PriorityNormal},
+		{WithPriority(context.Background(), ""), domain.PriorityNormal},
+	}
+	for _, tt := range tests {
+		if got := PriorityFrom(tt.ctx); got != tt.want {
+			t.Errorf("PriorityFrom = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package throttle paces calls to channel providers so the service as a whole stays
+// under each provider's account-wide rate limit.
+//
+// Every send reserves the next free slot of its provider in a schedule shared by all
+// replicas and waits for it: excess sends queue up and go out evenly spaced at the
+// configured rate instead of failing. When a provider still answers with a rate-limit
+// response (HTTP 429, SMTP 421/454), the provider's schedule is pushed back by the
+// response's Retry-After, or an exponential backoff, for every replica and the send
+// is retried.
+//
+// Sends queued for the same provider are served by priority: critical sends go
+// first, high, normal and low sends share the remaining slots 4:2:1, so a
+// password reset does not wait behind a marketing blast. A send given an expiry with
+// WithExpiry leaves the queue with ErrExpired once it passes, without being made.
+package throttle
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrRateLimited indicates a provider refused a call because a rate limit was exceeded.
+	ErrRateLimited = errors.New("provider rate limit exceeded")
+
+	// ErrExpired indicates a send was dropped because it expired while waiting for a slot.
+	ErrExpired = errors.New("expired while waiting for a send slot")
+)
+
+type expiryKey struct{}
+
+// WithExpiry returns a context whose sends are dropped with ErrExpired if they are
+// still waiting for a send slot at expiresAt.
+func WithExpiry(ctx context.Context, expiresAt time.Time) context.Context {
+	return context.WithValue(ctx, expiryKey{}, expiresAt)
+}
+
+// RateLimitError is a rate-limit response from a provider.
+type RateLimitError struct {
+	Err        error
+	RetryAfter time.Duration // Delay the provider asked for (0 = none given)
+}
+
+func (e *RateLimitError) Error() string   { return e.Err.Error() }
+func (e *RateLimitError) Unwrap() []error { return []error{e.Err, ErrRateLimited} }
+
+// RateLimited marks err as a rate-limit response. Provider clients return it so the
+// Limiter can back off.
+func RateLimited(err error, retryAfter time.Duration) error {
+	return &RateLimitError{Err: err, RetryAfter: retryAfter}
+}
+
+// ParseRetryAfter reads a Retry-After header given in seconds or as an HTTP date.
+// It returns 0 when the header is missing or invalid.
+func ParseRetryAfter(header string) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
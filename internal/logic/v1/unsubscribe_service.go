@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/internal/logic/v1/email"
+	"github.com/duynhne/notification-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// UnsubscribeService issues one-click unsubscribe links for non-transactional email and
+// applies them to the user's category preferences.
+type UnsubscribeService struct {
+	repo     domain.PreferenceRepository
+	secret   []byte
+	baseURL  string
+	tokenTTL time.Duration
+}
+
+// NewUnsubscribeService creates an UnsubscribeService. baseURL is the public unsubscribe
+// endpoint; when it or secret is empty no List-Unsubscribe headers are added.
+func NewUnsubscribeService(
+	repo domain.PreferenceRepository,
+	secret string,
+	baseURL string,
+	tokenTTL time.Duration,
+) *UnsubscribeService {
+	return &UnsubscribeService{
+		repo:     repo,
+		secret:   []byte(secret),
+		baseURL:  baseURL,
+		tokenTTL: tokenTTL,
+	}
+}
+
+// CheckOptIn returns ErrRecipientUnsubscribed if the user opted out of the email category.
+// Transactional email is always allowed.
+func (s *UnsubscribeService) CheckOptIn(ctx context.Context, userID int, category string) error {
+	if category == domain.CategoryTransactional {
+		return nil
+	}
+
+	enabled, err := s.repo.IsEnabled(ctx, userID, "email", category)
+	if err != nil {
+		return fmt.Errorf("check email preference: %w", err)
+	}
+	if !enabled {
+		return fmt.Errorf("user %d unsubscribed from %s email: %w", userID, category, ErrRecipientUnsubscribed)
+	}
+	return nil
+}
+
+// AddHeaders sets List-Unsubscribe and List-Unsubscribe-Post on a non-transactional message.
+func (s *UnsubscribeService) AddHeaders(header textproto.MIMEHeader, userID int, category string) error {
+	if category == domain.CategoryTransactional || s.baseURL == "" || len(s.secret) == 0 {
+		return nil
+	}
+
+	token, err := email.SignUnsubscribeToken(s.secret, email.UnsubscribeClaims{
+		UserID:    userID,
+		Channel:   "email",
+		Category:  category,
+		ExpiresAt: time.Now().Add(s.tokenTTL).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	return email.SetListUnsubscribe(header, s.baseURL, token)
+}
+
+// Unsubscribe verifies a token and disables the category it names. Applying the same
+// token twice is harmless.
+func (s *UnsubscribeService) Unsubscribe(ctx context.Context, token string) (*domain.UnsubscribeResult, error) {
+	ctx, span := middleware.StartSpan(ctx, "unsubscribe.apply", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	claims, err := email.VerifyUnsubscribeToken(s.secret, token, time.Now())
+	if err != nil {
+		span.SetAttributes(attribute.Bool("token.expired", errors.Is(err, email.ErrUnsubscribeTokenExpired)))
+		return nil, fmt.Errorf("%w: %w", err, ErrInvalidUnsubscribeToken)
+	}
+
+	span.SetAttributes(
+		attribute.Int("user_id", claims.UserID),
+		attribute.String("category", claims.Category),
+	)
+	if err := s.repo.Set(ctx, claims.UserID, claims.Channel, claims.Category, false); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.AddEvent("notification.unsubscribed")
+	return &domain.UnsubscribeResult{Channel: claims.Channel, Category: claims.Category}, nil
+}
@@ -0,0 +1,186 @@
+// Package webhook provides the outbound webhook channel for API version 1.
+//
+// Each delivery is a JSON event POSTed to a partner-owned HTTPS endpoint. Requests carry:
+//
+//	X-Webhook-Id:        unique event ID (receivers should de-duplicate on it)
+//	X-Webhook-Timestamp: Unix seconds when the request was signed
+//	X-Webhook-Signature: v1=<hex HMAC-SHA256(secret, "<timestamp>.<body>")>
+//
+// Receivers verify the signature with the endpoint secret and reject timestamps outside
+// their tolerance window to prevent replay (see Verify).
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/egress"
+	"github.com/duynhne/notification-service/internal/logic/v1/retry"
+)
+
+// Header names set on every webhook request.
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	signatureVersion = "v1"
+	userAgent        = "notification-service-webhooks/1.0"
+)
+
+// ErrInvalidSignature indicates a signature did not match or its timestamp is outside tolerance.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Event is the JSON envelope delivered to webhook endpoints.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt string          `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewEvent creates an event with a random ID and the current time.
+func NewEvent(eventType string, data json.RawMessage) (*Event, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+	return &Event{
+		ID:        "evt_" + id,
+		Type:      eventType,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Data:      data,
+	}, nil
+}
+
+// GenerateSecret returns a new random endpoint signing secret.
+func GenerateSecret() (string, error) {
+	s, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + s, nil
+}
+
+// Sign computes the signature header value for body signed at timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a received signature header against body. tolerance bounds how far the
+// timestamp header may be from now.
+func Verify(secret, timestampHeader, signatureHeader string, body []byte, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parse timestamp: %w", ErrInvalidSignature)
+	}
+	signedAt := time.Unix(ts, 0)
+	if d := time.Since(signedAt); d > tolerance || d < -tolerance {
+		return fmt.Errorf("timestamp outside tolerance: %w", ErrInvalidSignature)
+	}
+
+	expected := Sign(secret, signedAt, body)
+	for _, candidate := range strings.Split(signatureHeader, ",") {
+		if hmac.Equal([]byte(strings.TrimSpace(candidate)), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// Client posts signed events to webhook endpoints.
+type Client struct {
+	httpClient *http.Client
+	policy     retry.Policy
+}
+
+// NewClient creates a webhook client. timeout bounds each attempt; policy controls retries.
+// public restricts requests to public addresses (see egress), for URLs registered by
+// parties outside the cluster.
+func NewClient(timeout time.Duration, policy retry.Policy, public bool) *Client {
+	httpClient := &http.Client{Timeout: timeout}
+	if public {
+		httpClient = egress.Client(timeout)
+	}
+	// Partners must answer directly; following redirects would re-send the
+	// signed payload to a location the partner did not register.
+	httpClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &Client{httpClient: httpClient, policy: policy}
+}
+
+// Send delivers event to url signed with secret, retrying transient failures
+// (network errors, 408, 429 and 5xx) according to the client's policy.
+func (c *Client) Send(ctx context.Context, url, secret string, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal webhook event: %w", err)
+	}
+
+	return c.policy.Do(ctx, func(ctx context.Context) error {
+		return c.post(ctx, url, secret, event.ID, body)
+	})
+}
+
+func (c *Client) post(ctx context.Context, url, secret, eventID string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("create webhook request: %w", err))
+	}
+
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderID, eventID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(secret, now, body))
+
+	resp, err := c.httpClient.Do(req) //nolint:gosec // url is a registered partner endpoint
+	if errors.Is(err, egress.ErrForbiddenAddress) {
+		return retry.Permanent(fmt.Errorf("post webhook: %w", err))
+	}
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		return fmt.Errorf("webhook endpoint returned %d", resp.StatusCode)
+	default:
+		return retry.Permanent(fmt.Errorf("webhook endpoint returned %d", resp.StatusCode))
+	}
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate random id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,147 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/logic/v1/retry"
+)
+
+const (
+	testSecret = "whsec_test"
+	testBody   = `{"id":"evt_5f1c","type":"notification.sent","created_at":"2026-10-05T10:00:00Z","data":{}}`
+)
+
+func TestSign(t *testing.T) {
+	// Expected values computed independently: HMAC-SHA256(secret, "<unix seconds>.<body>")
+	signedAt := time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC) // 1791194400
+	tests := []struct {
+		name     string
+		signedAt time.Time
+		body     string
+		want     string
+	}{
+		{"event", signedAt, testBody, "v1=ec609cf86c068bea8767efc9b1b62081aa773938be9cd76ca6326a41ecd82ff1"},
+		{"empty body", signedAt, "", "v1=ce08552a639a4911f8207b95e9d01c0ad0b46725d17927947795db05b377ad63"},
+		// Only whole seconds are signed, in any time zone
+		{"sub-second", signedAt.Add(999 * time.Millisecond).In(time.FixedZone("ICT", 7*3600)), testBody, "v1=ec609cf86c068bea8767efc9b1b62081aa773938be9cd76ca6326a41ecd82ff1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(testSecret, tt.signedAt, []byte(tt.body)); got != tt.want {
+				t.Errorf("Sign = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	tolerance := 5 * time.Minute
+	timestamp := func(d time.Duration) string { return strconv.FormatInt(now.Add(d).Unix(), 10) }
+	sign := func(d time.Duration) string { return Sign(testSecret, now.Add(d), []byte(testBody)) }
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      string
+		wantErr   bool
+	}{
+		{"valid", testSecret, timestamp(0), sign(0), testBody, false},
+		{"slightly old", testSecret, timestamp(-4 * time.Minute), sign(-4 * time.Minute), testBody, false},
+		{"slightly ahead", testSecret, timestamp(4 * time.Minute), sign(4 * time.Minute), testBody, false},
+		{"one of several", testSecret, timestamp(0), "v1=0000, " + sign(0), testBody, false},
+		{"replayed", testSecret, timestamp(-time.Hour), sign(-time.Hour), testBody, true},
+		{"from the future", testSecret, timestamp(time.Hour), sign(time.Hour), testBody, true},
+		{"timestamp changed", testSecret, timestamp(-time.Minute), sign(0), testBody, true},
+		{"body changed", testSecret, timestamp(0), sign(0), testBody + " ", true},
+		{"wrong secret", "whsec_other", timestamp(0), sign(0), testBody, true},
+		{"without version", testSecret, timestamp(0), sign(0)[len("v1="):], testBody, true},
+		{"no signature", testSecret, timestamp(0), "", testBody, true},
+		{"timestamp not a number", testSecret, "2026-10-05T10:00:00Z", sign(0), testBody, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.timestamp, tt.signature, []byte(tt.body), tolerance)
+			if tt.wantErr != (err != nil) || (err != nil && !errors.Is(err, ErrInvalidSignature)) {
+				t.Errorf("Verify error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientSend(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int // Responses to successive attempts; the last one repeats
+		wantAttempts int
+		wantErr      bool
+	}{
+		{"delivered", []int{http.StatusNoContent}, 1, false},
+		{"retried after a server error", []int{http.StatusBadGateway, http.StatusOK}, 2, false},
+		{"retried after throttling", []int{http.StatusTooManyRequests, http.StatusRequestTimeout, http.StatusOK}, 3, false},
+		{"attempts exhausted", []int{http.StatusServiceUnavailable}, 3, true},
+		{"rejected", []int{http.StatusBadRequest}, 1, true},
+		{"redirect not followed", []int{http.StatusFound}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var requests []*http.Request
+			var bodies [][]byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				mu.Lock()
+				defer mu.Unlock()
+				requests = append(requests, r)
+				bodies = append(bodies, body)
+				status := tt.statuses[min(len(requests), len(tt.statuses))-1]
+				if status == http.StatusFound {
+					w.Header().Set("Location", "/elsewhere")
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			policy := retry.Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 2}
+			client := NewClient(time.Second, policy, false)
+			event := &Event{ID: "evt_5f1c", Type: "notification.sent", CreatedAt: "2026-10-05T10:00:00Z", Data: json.RawMessage("{}")}
+			err := client.Send(context.Background(), server.URL, testSecret, event)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Send error = %v, want error %v", err, tt.wantErr)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(requests) != tt.wantAttempts {
+				t.Fatalf("attempts = %d, want %d", len(requests), tt.wantAttempts)
+			}
+			for i, r := range requests {
+				if string(bodies[i]) != testBody {
+					t.Errorf("attempt %d body = %s, want %s", i+1, bodies[i], testBody)
+				}
+				if got := r.Header.Get(HeaderID); got != event.ID {
+					t.Errorf("attempt %d %s = %q, want %q", i+1, HeaderID, got, event.ID)
+				}
+				if got := r.Header.Get("Content-Type"); got != "application/json" {
+					t.Errorf("attempt %d Content-Type = %q", i+1, got)
+				}
+				// Every attempt is signed afresh, so a receiver's tolerance applies to it
+				timestamp, signature := r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature)
+				if err := Verify(testSecret, timestamp, signature, bodies[i], time.Minute); err != nil {
+					t.Errorf("attempt %d signature %q at %q: %v", i+1, signature, timestamp, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/internal/logic/v1/egress"
+	"github.com/duynhne/notification-service/internal/logic/v1/retry"
+	"github.com/duynhne/notification-service/internal/logic/v1/webhook"
+	"github.com/duynhne/notification-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// webhookLease is how long a claimed webhook delivery is hidden from other workers.
+// It must exceed the per-attempt request timeout.
+const webhookLease = time.Minute
+
+// WebhookService manages partner webhook endpoints and delivers events to them. Events
+// are written to an outbox and delivered by DispatchDue, so the request that sends an
+// event never waits on partner endpoints.
+type WebhookService struct {
+	repo         domain.WebhookEndpointRepository
+	deliveries   domain.WebhookDeliveryRepository
+	client       *webhook.Client
+	policy       retry.Policy
+	disableAfter int
+	allowHTTP    bool
+	allowPrivate bool
+}
+
+// NewWebhookService creates a WebhookService. client should not retry on its own;
+// policy schedules redelivery through the outbox instead (MaxAttempts bounds the total).
+// Endpoints are disabled after disableAfter consecutive deliveries that failed every
+// attempt. allowHTTP permits plain-HTTP endpoints and allowPrivate endpoints on
+// internal addresses, both for local development.
+func NewWebhookService(
+	repo domain.WebhookEndpointRepository,
+	deliveries domain.WebhookDeliveryRepository,
+	client *webhook.Client,
+	policy retry.Policy,
+	disableAfter int,
+	allowHTTP bool,
+	allowPrivate bool,
+) *WebhookService {
+	return &WebhookService{
+		repo:         repo,
+		deliveries:   deliveries,
+		client:       client,
+		policy:       policy,
+		disableAfter: disableAfter,
+		allowHTTP:    allowHTTP,
+		allowPrivate: allowPrivate,
+	}
+}
+
+// CreateEndpoint registers a webhook endpoint and generates its signing secret.
+// The secret is only returned here; callers must store it.
+func (s *WebhookService) CreateEndpoint(ctx context.Context, req domain.CreateWebhookRequest) (*domain.WebhookEndpoint, error) {
+	ctx, span := middleware.StartSpan(ctx, "webhook.create_endpoint", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.Int("user_id", req.UserID),
+		attribute.String("tenant_id", req.TenantID),
+	))
+	defer span.End()
+
+	if err := validateWebhookOwner(req.UserID, req.TenantID); err != nil {
+		return nil, err
+	}
+	if err := s.validateURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	endpoint := &domain.WebhookEndpoint{
+		UserID:      req.UserID,
+		TenantID:    req.TenantID,
+		URL:         req.URL,
+		Secret:      secret,
+		Description: req.Description,
+	}
+	if err := s.repo.Create(ctx, endpoint); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("create webhook endpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+// ListEndpoints returns all endpoints of a user or tenant, without their secrets.
+func (s *WebhookService) ListEndpoints(ctx context.Context, owner domain.WebhookOwner) ([]domain.WebhookEndpoint, error) {
+	ctx, span := middleware.StartSpan(ctx, "webhook.list_endpoints", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.Int("user_id", owner.UserID),
+		attribute.String("tenant_id", owner.TenantID),
+	))
+	defer span.End()
+
+	if err := validateWebhookOwner(owner.UserID, owner.TenantID); err != nil {
+		return nil, err
+	}
+
+	endpoints, err := s.repo.ListByOwner(ctx, owner, false)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	for i := range endpoints {
+		endpoints[i].Secret = ""
+	}
+	if endpoints == nil {
+		return []domain.WebhookEndpoint{}, nil
+	}
+	return endpoints, nil
+}
+
+// DeleteEndpoint removes a webhook endpoint. Its undelivered events are dropped.
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, id string) error {
+	ctx, span := middleware.StartSpan(ctx, "webhook.delete_endpoint", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("webhook.endpoint_id", id),
+	))
+	defer span.End()
+
+	endpointID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid webhook id %q: %w", id, ErrWebhookNotFound)
+	}
+
+	deleted, err := s.repo.Delete(ctx, endpointID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("delete webhook %q: %w", id, ErrWebhookNotFound)
+	}
+
+	return nil
+}
+
+// Send creates an event and queues it for every active endpoint of the owner.
+// Delivery happens in the worker; the result lists the endpoints the event was queued for.
+func (s *WebhookService) Send(ctx context.Context, req domain.SendWebhookRequest) (*domain.WebhookResult, error) {
+	ctx, span := middleware.StartSpan(ctx, "notification.webhook", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.Int("user_id", req.UserID),
+		attribute.String("tenant_id", req.TenantID),
+		attribute.String("webhook.event", req.Event),
+	))
+	defer span.End()
+
+	if err := validateWebhookOwner(req.UserID, req.TenantID); err != nil {
+		return nil, err
+	}
+
+	endpoints, err := s.repo.ListByOwner(ctx, domain.WebhookOwner{UserID: req.UserID, TenantID: req.TenantID}, true)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("list webhook endpoints: %w", err)
+	}
+
+	event, err := webhook.NewEvent(req.Event, req.Data)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	result := &domain.WebhookResult{EventID: event.ID, EndpointIDs: []string{}}
+	if len(endpoints) == 0 {
+		return result, nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("marshal webhook event: %w", err)
+	}
+	endpointIDs := make([]int64, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		endpointIDs = append(endpointIDs, endpoint.ID)
+		result.EndpointIDs = append(result.EndpointIDs, strconv.FormatInt(endpoint.ID, 10))
+	}
+	if err := s.deliveries.Enqueue(ctx, event.ID, payload, endpointIDs); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("queue webhook event: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("webhook.endpoints", len(endpoints)))
+	span.AddEvent("notification.webhook.queued")
+	return result, nil
+}
+
+// DispatchDue delivers up to limit due webhook events concurrently. Failed deliveries are
+// rescheduled with the policy's backoff until MaxAttempts is reached, then marked dead and
+// counted against the endpoint. Returns the number of events delivered.
+func (s *WebhookService) DispatchDue(ctx context.Context, limit int) (int, error) {
+	ctx, span := middleware.StartSpan(ctx, "webhook.dispatch", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	deliveries, err := s.deliveries.ClaimDue(ctx, limit, webhookLease)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	delivered := make([]bool, len(deliveries))
+	errs := make([]error, len(deliveries))
+	var wg sync.WaitGroup
+	for i, delivery := range deliveries {
+		wg.Go(func() {
+			delivered[i], errs[i] = s.dispatch(ctx, delivery)
+		})
+	}
+	wg.Wait()
+
+	count := 0
+	for _, ok := range delivered {
+		if ok {
+			count++
+		}
+	}
+	span.SetAttributes(
+		attribute.Int("webhook.claimed", len(deliveries)),
+		attribute.Int("webhook.delivered", count),
+	)
+
+	for _, err := range errs {
+		if err != nil {
+			span.RecordError(err)
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// dispatch makes one delivery attempt and records its outcome. The returned error only
+// reports failures to record the outcome; delivery failures are stored on the delivery.
+func (s *WebhookService) dispatch(ctx context.Context, delivery domain.WebhookDelivery) (bool, error) {
+	var sendErr error
+	if !delivery.Active {
+		sendErr = retry.Permanent(fmt.Errorf("webhook endpoint %d is disabled", delivery.EndpointID))
+	} else {
+		var event webhook.Event
+		if err := json.Unmarshal(delivery.Payload, &event); err != nil {
+			sendErr = retry.Permanent(fmt.Errorf("decode webhook event: %w", err))
+		} else {
+			sendErr = s.client.Send(ctx, delivery.URL, delivery.Secret, &event)
+		}
+	}
+
+	if sendErr == nil {
+		if err := s.deliveries.MarkDelivered(ctx, delivery.ID); err != nil {
+			return true, err
+		}
+		if delivery.ConsecutiveFailures > 0 {
+			return true, s.repo.RecordSuccess(ctx, delivery.EndpointID)
+		}
+		return true, nil
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the lease expires and another run retries the delivery.
+		return false, nil
+	}
+	if !retry.IsPermanent(sendErr) && delivery.Attempts < s.policy.MaxAttempts {
+		return false, s.deliveries.MarkFailed(ctx, delivery.ID, s.policy.Backoff(delivery.Attempts), sendErr.Error())
+	}
+
+	if err := s.deliveries.MarkDead(ctx, delivery.ID, sendErr.Error()); err != nil {
+		return false, err
+	}
+	if !delivery.Active {
+		return false, nil
+	}
+	_, err := s.repo.RecordFailure(ctx, delivery.EndpointID, s.disableAfter)
+	return false, err
+}
+
+// validateURL checks an endpoint URL against the channel's HTTPS requirement and, unless
+// allowPrivate is set, refuses hosts on internal addresses.
+func (s *WebhookService) validateURL(ctx context.Context, raw string) error {
+	if err := validateEndpointURL(raw, s.allowHTTP); err != nil {
+		return fmt.Errorf("webhook %w: %w", err, ErrInvalidWebhook)
+	}
+	if !s.allowPrivate {
+		if err := egress.CheckURL(ctx, raw); err != nil {
+			return fmt.Errorf("webhook url %q: %w: %w", raw, err, ErrInvalidWebhook)
+		}
+	}
+	return nil
+}
+
+// validateEndpointURL requires an absolute HTTPS URL (HTTP only when allowHTTP is set)
+// without embedded credentials. It is shared by all channels that POST to caller-owned URLs.
+func validateEndpointURL(raw string, allowHTTP bool) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("url %q is not absolute", raw)
+	}
+	if u.Scheme != "https" && (u.Scheme != "http" || !allowHTTP) {
+		return fmt.Errorf("url %q must use https", raw)
+	}
+	if u.User != nil {
+		return errors.New("url must not contain credentials")
+	}
+	return nil
+}
+
+// validateWebhookOwner requires exactly one of userID or tenantID.
+func validateWebhookOwner(userID int, tenantID string) error {
+	if (userID > 0) == (tenantID != "") {
+		return fmt.Errorf("exactly one of user_id or tenant_id is required: %w", ErrInvalidWebhook)
+	}
+	return nil
+}
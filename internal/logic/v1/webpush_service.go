@@ -0,0 +1,137 @@
+package v1
+
+import (
+	"context"
+	"crypto/ecdh"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	"github.com/duynhne/notification-service/internal/logic/v1/egress"
+	"github.com/duynhne/notification-service/internal/logic/v1/push"
+	"github.com/duynhne/notification-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WebPushService manages browser Web Push subscriptions of authenticated users.
+type WebPushService struct {
+	repo   domain.WebPushSubscriptionRepository
+	client *push.WebPushClient
+}
+
+// NewWebPushService creates a WebPushService. client may be nil when Web Push is disabled.
+func NewWebPushService(repo domain.WebPushSubscriptionRepository, client *push.WebPushClient) *WebPushService {
+	return &WebPushService{
+		repo:   repo,
+		client: client,
+	}
+}
+
+// PublicKey returns the VAPID applicationServerKey browsers subscribe with.
+func (s *WebPushService) PublicKey() (string, error) {
+	if s.client == nil {
+		return "", ErrWebPushDisabled
+	}
+	return s.client.PublicKey(), nil
+}
+
+// Subscribe stores a browser PushSubscription for the user.
+func (s *WebPushService) Subscribe(
+	ctx context.Context,
+	userID, userAgent string,
+	req domain.PushSubscriptionRequest,
+) (*domain.WebPushSubscription, error) {
+	ctx, span := middleware.StartSpan(ctx, "webpush.subscribe", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("api.version", "v1"),
+		attribute.String("user_id", userID),
+	))
+	defer span.End()
+
+	if s.client == nil {
+		return nil, ErrWebPushDisabled
+	}
+
+	uid, err := parseUserID(userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err := validateSubscriptionKeys(req.Keys.P256dh, req.Keys.Auth); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		return nil, fmt.Errorf("subscribe %q: %w: %w", req.Endpoint, ErrInvalidSubscription, err)
+	}
+	// The endpoint is POSTed to on every push, so it must not point into the cluster
+	if err := egress.CheckURL(ctx, req.Endpoint); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		return nil, fmt.Errorf("subscribe %q: %w: %w", req.Endpoint, ErrInvalidSubscription, err)
+	}
+
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+	sub := &domain.WebPushSubscription{
+		UserID:         uid,
+		Endpoint:       req.Endpoint,
+		P256dh:         req.Keys.P256dh,
+		Auth:           req.Keys.Auth,
+		UserAgent:      userAgent,
+		ExpirationTime: req.ExpirationTime,
+	}
+	if err := s.repo.Upsert(ctx, sub); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("store web push subscription: %w", err)
+	}
+
+	return sub, nil
+}
+
+// Unsubscribe removes a browser subscription of the user.
+func (s *WebPushService) Unsubscribe(ctx context.Context, userID, endpoint string) error {
+	ctx, span := middleware.StartSpan(ctx, "webpush.unsubscribe", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("api.version", "v1"),
+		attribute.String("user_id", userID),
+	))
+	defer span.End()
+
+	uid, err := parseUserID(userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	deleted, err := s.repo.Delete(ctx, uid, endpoint)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("delete web push subscription: %w", err)
+	}
+	if !deleted {
+		return fmt.Errorf("unsubscribe for user %q: %w", userID, ErrDeviceNotFound)
+	}
+
+	return nil
+}
+
+// validateSubscriptionKeys checks that p256dh is a P-256 point and auth is a 16-byte secret,
+// so that malformed subscriptions are rejected at registration rather than on every send.
+func validateSubscriptionKeys(p256dh, auth string) error {
+	rawKey, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(p256dh, "="))
+	if err != nil {
+		return fmt.Errorf("p256dh is not base64url: %w", err)
+	}
+	if _, err := ecdh.P256().NewPublicKey(rawKey); err != nil {
+		return fmt.Errorf("p256dh is not a P-256 public key: %w", err)
+	}
+	rawAuth, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(auth, "="))
+	if err != nil {
+		return fmt.Errorf("auth is not base64url: %w", err)
+	}
+	if len(rawAuth) != 16 {
+		return fmt.Errorf("auth must be 16 bytes, got %d", len(rawAuth))
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	logicv1 "github.com/duynhne/notification-service/internal/logic/v1"
+	"github.com/duynhne/notification-service/middleware"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// CallbackHandler serves API client registration and delivery status reports.
+type CallbackHandler struct {
+	service *logicv1.CallbackService
+}
+
+func NewCallbackHandler(service *logicv1.CallbackService) *CallbackHandler {
+	return &CallbackHandler{service: service}
+}
+
+// writeCallbackError maps callback and status errors to HTTP responses.
+func writeCallbackError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logicv1.ErrInvalidCallback):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, logicv1.ErrClientNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Client not found"})
+	case errors.Is(err, logicv1.ErrNotificationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+	case errors.Is(err, logicv1.ErrInvalidStatusTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// RegisterClient handles PUT /notification/v1/internal/clients/:client_id
+func (h *CallbackHandler) RegisterClient(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+
+	var req domain.RegisterClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		zapLogger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	span.SetAttributes(attribute.Bool("request.valid", true))
+	client, err := h.service.RegisterClient(ctx, c.Param("client_id"), req)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to register client", zap.Error(err))
+		writeCallbackError(c, err)
+		return
+	}
+
+	zapLogger.Info("Client registered", zap.String("client_id", client.ClientID))
+	c.JSON(http.StatusOK, client)
+}
+
+// GetClient handles GET /notification/v1/internal/clients/:client_id
+func (h *CallbackHandler) GetClient(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+
+	client, err := h.service.GetClient(ctx, c.Param("client_id"))
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to get client", zap.Error(err))
+		writeCallbackError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
+
+// DeleteClient handles DELETE /notification/v1/internal/clients/:client_id
+func (h *CallbackHandler) DeleteClient(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+	clientID := c.Param("client_id")
+
+	if err := h.service.DeleteClient(ctx, clientID); err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to delete client", zap.Error(err))
+		writeCallbackError(c, err)
+		return
+	}
+
+	zapLogger.Info("Client deleted", zap.String("client_id", clientID))
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateStatus handles POST /notification/v1/internal/notifications/:id/status
+func (h *CallbackHandler) UpdateStatus(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+	id := c.Param("id")
+	span.SetAttributes(attribute.String("notification.id", id))
+
+	var req domain.UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		zapLogger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	span.SetAttributes(attribute.Bool("request.valid", true))
+	notification, err := h.service.UpdateStatus(ctx, id, req)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to update notification status", zap.Error(err))
+		writeCallbackError(c, err)
+		return
+	}
+
+	zapLogger.Info("Notification status updated",
+		zap.String("notification_id", id),
+		zap.String("status", notification.Status),
+	)
+	c.JSON(http.StatusOK, notification)
+}
@@ -0,0 +1,161 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	logicv1 "github.com/duynhne/notification-service/internal/logic/v1"
+	"github.com/duynhne/notification-service/middleware"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// DeviceHandler serves the push device token registry of the authenticated user.
+type DeviceHandler struct {
+	service *logicv1.DeviceService
+}
+
+func NewDeviceHandler(service *logicv1.DeviceService) *DeviceHandler {
+	return &DeviceHandler{service: service}
+}
+
+// requireUserID returns the user_id set by the auth middleware, responding 401 if missing.
+func requireUserID(c *gin.Context, span trace.Span, zapLogger *zap.Logger) (string, bool) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		span.SetAttributes(attribute.Bool("auth.missing", true))
+		zapLogger.Warn("Missing user_id in request context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return "", false
+	}
+	return userID, true
+}
+
+// writeDeviceError maps device registry errors to HTTP responses.
+func writeDeviceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logicv1.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+	case errors.Is(err, logicv1.ErrDeviceTokenInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": "Device token is registered to another user"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// RegisterDevice handles POST /notification/v1/private/devices
+func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("api.version", "v1"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+	userID, ok := requireUserID(c, span, zapLogger)
+	if !ok {
+		return
+	}
+
+	var req domain.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		zapLogger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	span.SetAttributes(attribute.Bool("request.valid", true))
+	device, err := h.service.Register(ctx, userID, req)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to register device", zap.Error(err))
+		writeDeviceError(c, err)
+		return
+	}
+
+	zapLogger.Info("Device registered", zap.Int64("device_id", device.ID), zap.String("platform", device.Platform))
+	c.JSON(http.StatusCreated, device)
+}
+
+// RefreshDevice handles PATCH /notification/v1/private/devices. The token is taken
+// from the body, not the path, so it stays out of access logs.
+func (h *DeviceHandler) RefreshDevice(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("api.version", "v1"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+	userID, ok := requireUserID(c, span, zapLogger)
+	if !ok {
+		return
+	}
+
+	// A body with only the token is a plain last-seen heartbeat.
+	var req domain.RefreshDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		zapLogger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	span.SetAttributes(attribute.Bool("request.valid", true))
+	device, err := h.service.Refresh(ctx, userID, req)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to refresh device", zap.Error(err))
+		writeDeviceError(c, err)
+		return
+	}
+
+	zapLogger.Info("Device refreshed", zap.Int64("device_id", device.ID))
+	c.JSON(http.StatusOK, device)
+}
+
+// UnregisterDevice handles DELETE /notification/v1/private/devices
+func (h *DeviceHandler) UnregisterDevice(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("api.version", "v1"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+	userID, ok := requireUserID(c, span, zapLogger)
+	if !ok {
+		return
+	}
+
+	var req domain.UnregisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		zapLogger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Unregister(ctx, userID, req.Token); err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to unregister device", zap.Error(err))
+		writeDeviceError(c, err)
+		return
+	}
+
+	zapLogger.Info("Device unregistered")
+	c.Status(http.StatusNoContent)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/duynhne/notification-service/internal/core/domain"
 	logicv1 "github.com/duynhne/notification-service/internal/logic/v1"
@@ -14,6 +15,31 @@ import (
 	"go.uber.org/zap"
 )
 
+// ClientIDHeader identifies the calling service on internal requests. Status callbacks
+// for the notifications it sends are signed with that client's secret.
+const ClientIDHeader = "X-Client-ID"
+
+// includeExpired reads the include_expired query parameter. It writes a 400 response
+// and returns false when the parameter is not a boolean.
+func includeExpired(c *gin.Context) (include, ok bool) {
+	return boolQuery(c, "include_expired")
+}
+
+// boolQuery reads an optional boolean query parameter, false when absent. It writes a
+// 400 response and returns false when the parameter is not a boolean.
+func boolQuery(c *gin.Context, name string) (value, ok bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return false, true
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be a boolean"})
+		return false, false
+	}
+	return value, true
+}
+
 type Handler struct {
 	service *logicv1.NotificationService
 }
@@ -40,6 +66,7 @@ func (h *Handler) SendEmail(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	req.ClientID = c.GetHeader(ClientIDHeader)
 
 	span.SetAttributes(attribute.Bool("request.valid", true))
 	notification, err := h.service.SendEmail(ctx, req)
@@ -50,6 +77,20 @@ func (h *Handler) SendEmail(c *gin.Context) {
 		switch {
 		case errors.Is(err, logicv1.ErrInvalidRecipient):
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipient"})
+		case errors.Is(err, logicv1.ErrRecipientSuppressed):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Recipient suppressed"})
+		case errors.Is(err, logicv1.ErrRecipientUnsubscribed):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Recipient unsubscribed"})
+		case errors.Is(err, logicv1.ErrInvalidAttachment):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, logicv1.ErrAttachmentTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, logicv1.ErrInvalidCallback):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, logicv1.ErrInvalidMetadata):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, logicv1.ErrNotificationExpired):
+			c.JSON(http.StatusGone, gin.H{"error": "Notification expired"})
 		case errors.Is(err, logicv1.ErrDeliveryFailed):
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Delivery failed"})
 		default:
@@ -58,8 +99,8 @@ func (h *Handler) SendEmail(c *gin.Context) {
 		return
 	}
 
-	zapLogger.Info("Email sent", zap.String("notification_id", notification.ID))
-	c.JSON(http.StatusOK, notification)
+	zapLogger.Info("Email queued", zap.Stringer("notification_id", notification.PublicID))
+	c.JSON(http.StatusAccepted, notification)
 }
 
 func (h *Handler) SendSMS(c *gin.Context) {
@@ -80,18 +121,33 @@ func (h *Handler) SendSMS(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	req.ClientID = c.GetHeader(ClientIDHeader)
 
 	span.SetAttributes(attribute.Bool("request.valid", true))
 	notification, err := h.service.SendSMS(ctx, req)
 	if err != nil {
 		span.RecordError(err)
 		zapLogger.Error("Failed to send SMS", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+
+		switch {
+		case errors.Is(err, logicv1.ErrInvalidRecipient):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipient"})
+		case errors.Is(err, logicv1.ErrMessageTooLong):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, logicv1.ErrInvalidCallback):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, logicv1.ErrInvalidMetadata):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, logicv1.ErrNotificationExpired):
+			c.JSON(http.StatusGone, gin.H{"error": "Notification expired"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
 		return
 	}
 
-	zapLogger.Info("SMS sent", zap.String("notification_id", notification.ID))
-	c.JSON(http.StatusOK, notification)
+	zapLogger.Info("SMS queued", zap.Stringer("notification_id", notification.PublicID))
+	c.JSON(http.StatusAccepted, notification)
 }
 
 // ListNotifications handles GET /notification/v1/private/notifications
@@ -112,7 +168,37 @@ func (h *Handler) ListNotifications(c *gin.Context) {
 		userID = "1"
 	}
 
-	notifications, err := h.service.ListNotifications(ctx, userID)
+	include, ok := includeExpired(c)
+	if !ok {
+		return
+	}
+	folder := c.DefaultQuery("folder", domain.FolderInbox)
+	if folder != domain.FolderInbox && folder != domain.FolderArchive && folder != domain.FolderTrash {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "folder must be inbox, archive or trash"})
+		return
+	}
+
+	grouped, ok := boolQuery(c, "grouped")
+	if !ok {
+		return
+	}
+
+	filter := domain.NotificationFilter{Folder: folder, IncludeExpired: include}
+	if grouped {
+		groups, err := h.service.ListNotificationGroups(ctx, userID, filter)
+		if err != nil {
+			span.RecordError(err)
+			zapLogger.Error("Failed to list notification groups", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		zapLogger.Info("Notification groups listed", zap.Int("count", len(groups)))
+		c.JSON(http.StatusOK, groups)
+		return
+	}
+
+	notifications, err := h.service.ListNotifications(ctx, userID, filter)
 	if err != nil {
 		span.RecordError(err)
 		zapLogger.Error("Failed to list notifications", zap.Error(err))
@@ -126,9 +212,11 @@ func (h *Handler) ListNotifications(c *gin.Context) {
 
 // handleNotificationByID is a shared handler for operations on a single notification by ID.
 // It extracts common boilerplate (span setup, ID extraction, error handling) to avoid duplication.
+// Operations on behalf of the user set requireUser and get the authenticated user's ID.
 func (h *Handler) handleNotificationByID(
 	c *gin.Context,
-	action func(ctx context.Context, id string) (*domain.Notification, error),
+	requireUser bool,
+	action func(ctx context.Context, userID, id string) (*domain.Notification, error),
 	successLog string,
 ) {
 	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
@@ -140,10 +228,17 @@ func (h *Handler) handleNotificationByID(
 	defer span.End()
 
 	zapLogger := middleware.GetLoggerFromGinContext(c)
+	var userID string
+	if requireUser {
+		var ok bool
+		if userID, ok = requireUserID(c, span, zapLogger); !ok {
+			return
+		}
+	}
 	id := c.Param("id")
 	span.SetAttributes(attribute.String("notification.id", id))
 
-	notification, err := action(ctx, id)
+	notification, err := action(ctx, userID, id)
 	if err != nil {
 		span.RecordError(err)
 		zapLogger.Error(successLog+" failed", zap.Error(err))
@@ -163,12 +258,168 @@ func (h *Handler) handleNotificationByID(
 
 // GetNotification handles GET /notification/v1/private/notifications/:id
 func (h *Handler) GetNotification(c *gin.Context) {
-	h.handleNotificationByID(c, h.service.GetNotification, "Notification retrieved")
+	h.handleNotificationByID(c, false, func(ctx context.Context, _, id string) (*domain.Notification, error) {
+		return h.service.GetNotification(ctx, id)
+	}, "Notification retrieved")
 }
 
 // MarkAsRead handles PATCH /notification/v1/private/notifications/:id
 func (h *Handler) MarkAsRead(c *gin.Context) {
-	h.handleNotificationByID(c, h.service.MarkAsRead, "Notification marked as read")
+	h.handleNotificationByID(c, false, func(ctx context.Context, _, id string) (*domain.Notification, error) {
+		return h.service.MarkAsRead(ctx, id)
+	}, "Notification marked as read")
+}
+
+// MarkGroupAsRead handles PATCH /notification/v1/private/notifications/groups/:group_key
+func (h *Handler) MarkGroupAsRead(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("api.version", "v1"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+	userID, ok := requireUserID(c, span, zapLogger)
+	if !ok {
+		return
+	}
+	groupKey := c.Param("group_key")
+	span.SetAttributes(attribute.String("notification.group_key", groupKey))
+
+	updated, err := h.service.MarkGroupAsRead(ctx, userID, groupKey)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to mark notification group as read", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	zapLogger.Info("Notification group marked as read", zap.String("group_key", groupKey), zap.Int("updated", updated))
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// ArchiveNotification handles POST /notification/v1/private/notifications/:id/archive
+func (h *Handler) ArchiveNotification(c *gin.Context) {
+	h.handleNotificationByID(c, true, h.service.ArchiveNotification, "Notification archived")
+}
+
+// RestoreNotification handles POST /notification/v1/private/notifications/:id/restore
+func (h *Handler) RestoreNotification(c *gin.Context) {
+	h.handleNotificationByID(c, true, h.service.RestoreNotification, "Notification restored")
+}
+
+// DeleteNotification handles DELETE /notification/v1/private/notifications/:id
+func (h *Handler) DeleteNotification(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("api.version", "v1"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+	userID, ok := requireUserID(c, span, zapLogger)
+	if !ok {
+		return
+	}
+	id := c.Param("id")
+	span.SetAttributes(attribute.String("notification.id", id))
+
+	if err := h.service.DeleteNotification(ctx, userID, id); err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to delete notification", zap.Error(err))
+
+		switch {
+		case errors.Is(err, logicv1.ErrNotificationNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	zapLogger.Info("Notification deleted", zap.String("notification_id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// BulkUpdateNotifications handles POST /notification/v1/private/notifications/bulk
+func (h *Handler) BulkUpdateNotifications(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("api.version", "v1"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+	userID, ok := requireUserID(c, span, zapLogger)
+	if !ok {
+		return
+	}
+
+	var req domain.BulkNotificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		zapLogger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.service.BulkUpdate(ctx, userID, req)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to update notifications", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	zapLogger.Info("Notifications updated", zap.String("operation", req.Operation), zap.Int("updated", updated))
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// TakeAction handles POST /notification/v1/private/notifications/:id/actions/:action
+func (h *Handler) TakeAction(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("api.version", "v1"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+	userID, ok := requireUserID(c, span, zapLogger)
+	if !ok {
+		return
+	}
+
+	id, action := c.Param("id"), c.Param("action")
+	notification, err := h.service.TakeAction(ctx, userID, id, action)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to take notification action", zap.Error(err))
+
+		switch {
+		case errors.Is(err, logicv1.ErrNotificationNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		case errors.Is(err, logicv1.ErrActionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Action not found"})
+		case errors.Is(err, logicv1.ErrActionAlreadyTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": "Another action was already taken"})
+		case errors.Is(err, logicv1.ErrNotificationExpired):
+			c.JSON(http.StatusGone, gin.H{"error": "Notification expired"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	zapLogger.Info("Notification action taken", zap.String("notification_id", id), zap.String("action", action))
+	c.JSON(http.StatusOK, notification)
 }
 
 // GetUnreadCount handles GET /notification/v1/private/notifications/count
@@ -184,15 +435,17 @@ func (h *Handler) GetUnreadCount(c *gin.Context) {
 	zapLogger := middleware.GetLoggerFromGinContext(c)
 
 	// Security: Require valid user_id from auth middleware
-	userID := c.GetString("user_id")
-	if userID == "" {
-		span.SetAttributes(attribute.Bool("auth.missing", true))
-		zapLogger.Warn("Missing user_id in request context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+	userID, ok := requireUserID(c, span, zapLogger)
+	if !ok {
+		return
+	}
+
+	include, ok := includeExpired(c)
+	if !ok {
 		return
 	}
 
-	count, err := h.service.CountUnread(ctx, userID)
+	count, err := h.service.CountUnread(ctx, userID, include)
 	if err != nil {
 		span.RecordError(err)
 		zapLogger.Error("Failed to count unread notifications", zap.Error(err))
@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"net/http"
+
+	logicv1 "github.com/duynhne/notification-service/internal/logic/v1"
+	"github.com/duynhne/notification-service/middleware"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ProviderHandler serves the health of the channel providers.
+type ProviderHandler struct {
+	service *logicv1.ProviderService
+}
+
+func NewProviderHandler(service *logicv1.ProviderService) *ProviderHandler {
+	return &ProviderHandler{service: service}
+}
+
+// GetProviderStatus handles GET /notification/v1/internal/providers
+func (h *ProviderHandler) GetProviderStatus(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	c.JSON(http.StatusOK, h.service.Status(ctx))
+}
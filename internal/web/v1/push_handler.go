@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	logicv1 "github.com/duynhne/notification-service/internal/logic/v1"
+	"github.com/duynhne/notification-service/middleware"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// PushHandler serves internal push delivery requests.
+type PushHandler struct {
+	service *logicv1.PushService
+}
+
+func NewPushHandler(service *logicv1.PushService) *PushHandler {
+	return &PushHandler{service: service}
+}
+
+// SendPush handles POST /notification/v1/internal/notify/push
+func (h *PushHandler) SendPush(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+
+	var req domain.SendPushRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		zapLogger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.ClientID = c.GetHeader(ClientIDHeader)
+
+	span.SetAttributes(attribute.Bool("request.valid", true))
+	notification, err := h.service.SendPush(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to send push", zap.Error(err))
+
+		switch {
+		case errors.Is(err, logicv1.ErrInvalidMetadata):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, logicv1.ErrNotificationExpired):
+			c.JSON(http.StatusGone, gin.H{"error": "Notification expired"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	zapLogger.Info("Push queued", zap.Stringer("notification_id", notification.PublicID))
+	c.JSON(http.StatusAccepted, notification)
+}
@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	logicv1 "github.com/duynhne/notification-service/internal/logic/v1"
+	"github.com/duynhne/notification-service/middleware"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// maxReportSize bounds raw DSN uploads. Reports may include the full original message.
+const maxReportSize = 10 << 20
+
+// SuppressionHandler serves inbound bounce/complaint reports and suppression list management.
+type SuppressionHandler struct {
+	service *logicv1.SuppressionService
+}
+
+func NewSuppressionHandler(service *logicv1.SuppressionService) *SuppressionHandler {
+	return &SuppressionHandler{service: service}
+}
+
+// writeSuppressionError maps suppression errors to HTTP responses.
+func writeSuppressionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logicv1.ErrInvalidReport), errors.Is(err, logicv1.ErrInvalidRecipient):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, logicv1.ErrSuppressionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Suppression not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// ProcessEmailEvents handles POST /notification/v1/internal/email/events
+func (h *SuppressionHandler) ProcessEmailEvents(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+
+	var req domain.EmailEventsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		zapLogger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	span.SetAttributes(attribute.Bool("request.valid", true))
+	result, err := h.service.ProcessEvents(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to process email events", zap.Error(err))
+		writeSuppressionError(c, err)
+		return
+	}
+
+	zapLogger.Info("Email events processed",
+		zap.String("provider", req.Provider),
+		zap.Int("processed", result.Processed),
+		zap.Int("suppressed", result.Suppressed),
+	)
+	c.JSON(http.StatusOK, result)
+}
+
+// ProcessDSN handles POST /notification/v1/internal/email/dsn with a raw message/rfc822 body
+func (h *SuppressionHandler) ProcessDSN(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxReportSize)
+	result, err := h.service.ProcessDSN(ctx, body)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to process DSN", zap.Error(err))
+		writeSuppressionError(c, err)
+		return
+	}
+
+	zapLogger.Info("DSN processed",
+		zap.Int("processed", result.Processed),
+		zap.Int("suppressed", result.Suppressed),
+	)
+	c.JSON(http.StatusOK, result)
+}
+
+// AddSuppression handles POST /notification/v1/internal/suppressions
+func (h *SuppressionHandler) AddSuppression(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+
+	var req domain.CreateSuppressionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		zapLogger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	span.SetAttributes(attribute.Bool("request.valid", true))
+	suppression, err := h.service.Add(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to add suppression", zap.Error(err))
+		writeSuppressionError(c, err)
+		return
+	}
+
+	zapLogger.Info("Suppression added", zap.String("reason", suppression.Reason))
+	c.JSON(http.StatusCreated, suppression)
+}
+
+// GetSuppression handles GET /notification/v1/internal/suppressions/:address
+func (h *SuppressionHandler) GetSuppression(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+
+	suppression, err := h.service.Get(ctx, c.Param("address"))
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to get suppression", zap.Error(err))
+		writeSuppressionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, suppression)
+}
+
+// RemoveSuppression handles DELETE /notification/v1/internal/suppressions/:address
+func (h *SuppressionHandler) RemoveSuppression(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+
+	if err := h.service.Remove(ctx, c.Param("address")); err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to remove suppression", zap.Error(err))
+		writeSuppressionError(c, err)
+		return
+	}
+
+	zapLogger.Info("Suppression removed")
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+
+	logicv1 "github.com/duynhne/notification-service/internal/logic/v1"
+	"github.com/duynhne/notification-service/middleware"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// unsubscribePage is shown to people who open the unsubscribe link in a browser. Opening
+// the link only shows the form: link scanners prefetch URLs in mail and must not unsubscribe.
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Unsubscribe</title></head>
+<body>
+{{- if .Done}}
+<p>You have been unsubscribed from {{.Category}} emails.</p>
+{{- else if .Error}}
+<p>{{.Error}}</p>
+{{- else}}
+<form method="post">
+<input type="hidden" name="token" value="{{.Token}}">
+<p>Stop receiving these emails?</p>
+<button type="submit">Unsubscribe</button>
+</form>
+{{- end}}
+</body>
+</html>
+`))
+
+type unsubscribePageData struct {
+	Token    string
+	Done     bool
+	Category string
+	Error    string
+}
+
+// UnsubscribeHandler serves the public one-click unsubscribe endpoint (RFC 8058).
+type UnsubscribeHandler struct {
+	service *logicv1.UnsubscribeService
+}
+
+func NewUnsubscribeHandler(service *logicv1.UnsubscribeService) *UnsubscribeHandler {
+	return &UnsubscribeHandler{service: service}
+}
+
+// ShowUnsubscribe handles GET /notification/v1/public/unsubscribe?token=
+func (h *UnsubscribeHandler) ShowUnsubscribe(c *gin.Context) {
+	status := http.StatusOK
+	data := unsubscribePageData{Token: c.Query("token")}
+	if data.Token == "" {
+		status = http.StatusBadRequest
+		data.Error = "This unsubscribe link is invalid."
+	}
+	renderUnsubscribePage(c, status, data)
+}
+
+// Unsubscribe handles POST /notification/v1/public/unsubscribe?token=
+//
+// Mail clients send "List-Unsubscribe=One-Click" as the form body with the token in the
+// URL; the confirmation form posts the token as a form field instead.
+func (h *UnsubscribeHandler) Unsubscribe(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+
+	token := c.Query("token")
+	if token == "" {
+		token = c.PostForm("token")
+	}
+
+	result, err := h.service.Unsubscribe(ctx, token)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Warn("Unsubscribe failed", zap.Error(err))
+		if errors.Is(err, logicv1.ErrInvalidUnsubscribeToken) {
+			renderUnsubscribePage(c, http.StatusBadRequest, unsubscribePageData{
+				Error: "This unsubscribe link is invalid or has expired.",
+			})
+			return
+		}
+		renderUnsubscribePage(c, http.StatusInternalServerError, unsubscribePageData{
+			Error: "Something went wrong. Please try again later.",
+		})
+		return
+	}
+
+	zapLogger.Info("User unsubscribed",
+		zap.String("channel", result.Channel),
+		zap.String("category", result.Category),
+	)
+	renderUnsubscribePage(c, http.StatusOK, unsubscribePageData{Done: true, Category: result.Category})
+}
+
+func renderUnsubscribePage(c *gin.Context, status int, data unsubscribePageData) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	c.Status(status)
+	_ = unsubscribePage.Execute(c.Writer, data)
+}
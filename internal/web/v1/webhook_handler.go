@@ -0,0 +1,158 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	logicv1 "github.com/duynhne/notification-service/internal/logic/v1"
+	"github.com/duynhne/notification-service/middleware"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// WebhookHandler serves partner webhook endpoint management and webhook delivery.
+type WebhookHandler struct {
+	service *logicv1.WebhookService
+}
+
+func NewWebhookHandler(service *logicv1.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// writeWebhookError maps webhook errors to HTTP responses.
+func writeWebhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logicv1.ErrInvalidWebhook):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, logicv1.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook endpoint not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// CreateWebhook handles POST /notification/v1/internal/webhooks
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+
+	var req domain.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		zapLogger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	span.SetAttributes(attribute.Bool("request.valid", true))
+	endpoint, err := h.service.CreateEndpoint(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to create webhook endpoint", zap.Error(err))
+		writeWebhookError(c, err)
+		return
+	}
+
+	zapLogger.Info("Webhook endpoint created", zap.Int64("endpoint_id", endpoint.ID))
+	c.JSON(http.StatusCreated, endpoint)
+}
+
+// ListWebhooks handles GET /notification/v1/internal/webhooks?user_id=|tenant_id=
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+
+	owner := domain.WebhookOwner{TenantID: c.Query("tenant_id")}
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user_id must be a number"})
+			return
+		}
+		owner.UserID = userID
+	}
+
+	endpoints, err := h.service.ListEndpoints(ctx, owner)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to list webhook endpoints", zap.Error(err))
+		writeWebhookError(c, err)
+		return
+	}
+
+	zapLogger.Info("Webhook endpoints listed", zap.Int("count", len(endpoints)))
+	c.JSON(http.StatusOK, endpoints)
+}
+
+// DeleteWebhook handles DELETE /notification/v1/internal/webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+	id := c.Param("id")
+
+	if err := h.service.DeleteEndpoint(ctx, id); err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to delete webhook endpoint", zap.Error(err))
+		writeWebhookError(c, err)
+		return
+	}
+
+	zapLogger.Info("Webhook endpoint deleted", zap.String("endpoint_id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// SendWebhook handles POST /notification/v1/internal/notify/webhook
+func (h *WebhookHandler) SendWebhook(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+
+	var req domain.SendWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		zapLogger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	span.SetAttributes(attribute.Bool("request.valid", true))
+	result, err := h.service.Send(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to send webhook", zap.Error(err))
+		writeWebhookError(c, err)
+		return
+	}
+
+	zapLogger.Info("Webhook queued", zap.String("event_id", result.EventID), zap.Int("endpoints", len(result.EndpointIDs)))
+	c.JSON(http.StatusAccepted, result)
+}
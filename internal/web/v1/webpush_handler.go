@@ -0,0 +1,121 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/duynhne/notification-service/internal/core/domain"
+	logicv1 "github.com/duynhne/notification-service/internal/logic/v1"
+	"github.com/duynhne/notification-service/middleware"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// WebPushHandler serves browser Web Push subscription management.
+type WebPushHandler struct {
+	service *logicv1.WebPushService
+}
+
+func NewWebPushHandler(service *logicv1.WebPushService) *WebPushHandler {
+	return &WebPushHandler{service: service}
+}
+
+// writeWebPushError maps Web Push subscription errors to HTTP responses.
+func writeWebPushError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logicv1.ErrInvalidSubscription):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid push subscription"})
+	case errors.Is(err, logicv1.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+	case errors.Is(err, logicv1.ErrWebPushDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Web Push is not enabled"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// GetVAPIDPublicKey handles GET /notification/v1/private/webpush/vapid-public-key
+func (h *WebPushHandler) GetVAPIDPublicKey(c *gin.Context) {
+	key, err := h.service.PublicKey()
+	if err != nil {
+		writeWebPushError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"public_key": key})
+}
+
+// Subscribe handles POST /notification/v1/private/webpush/subscriptions
+func (h *WebPushHandler) Subscribe(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("api.version", "v1"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+	userID, ok := requireUserID(c, span, zapLogger)
+	if !ok {
+		return
+	}
+
+	var req domain.PushSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		zapLogger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	span.SetAttributes(attribute.Bool("request.valid", true))
+	sub, err := h.service.Subscribe(ctx, userID, c.Request.UserAgent(), req)
+	if err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to store web push subscription", zap.Error(err))
+		writeWebPushError(c, err)
+		return
+	}
+
+	zapLogger.Info("Web push subscription stored", zap.Int64("subscription_id", sub.ID))
+	c.JSON(http.StatusCreated, sub)
+}
+
+// Unsubscribe handles DELETE /notification/v1/private/webpush/subscriptions
+func (h *WebPushHandler) Unsubscribe(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("api.version", "v1"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	zapLogger := middleware.GetLoggerFromGinContext(c)
+	userID, ok := requireUserID(c, span, zapLogger)
+	if !ok {
+		return
+	}
+
+	var req domain.UnsubscribeWebPushRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetAttributes(attribute.Bool("request.valid", false))
+		span.RecordError(err)
+		zapLogger.Error("Invalid request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Unsubscribe(ctx, userID, req.Endpoint); err != nil {
+		span.RecordError(err)
+		zapLogger.Error("Failed to remove web push subscription", zap.Error(err))
+		writeWebPushError(c, err)
+		return
+	}
+
+	zapLogger.Info("Web push subscription removed")
+	c.Status(http.StatusNoContent)
+}